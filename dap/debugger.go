@@ -0,0 +1,277 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package dap
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"github.com/islisp-dev/iris/reader/parser"
+	"github.com/islisp-dev/iris/runtime"
+	"github.com/islisp-dev/iris/runtime/env"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// entry is a form being evaluated.
+type entry struct {
+	e      env.Environment
+	form   ilos.Instance
+	source parser.Source
+}
+
+// frame is a function call in progress, or the top level, and the innermost
+// form being evaluated in it.
+type frame struct {
+	name  string
+	entry *entry
+}
+
+// The modes in which the program runs until the next stop.
+const (
+	running = iota
+	// entering stops at the first form.
+	entering
+	stepIn
+	stepOver
+	stepOut
+	pausing
+)
+
+// debugger runs the program as the stepper, and stops it at the breakpoints,
+// after the steps and on the conditions which are not handled. The program
+// runs in its own goroutine, which waits for the server to resume it while it
+// is stopped.
+type debugger struct {
+	mutex sync.Mutex
+	// stack is the forms being evaluated, the innermost last.
+	stack []*entry
+	mode  int
+	// depth is the length of the stack at the last stop, from which the
+	// steps are counted.
+	depth int
+	// breakpoints are the lines with breakpoints in the files.
+	breakpoints map[string]map[int]bool
+	uncaught    bool
+	// frames are the frames at the stop, or nil while the program runs.
+	frames []frame
+	// resume resumes the stopped program, which exits if true is sent.
+	resume chan bool
+	// aborted is set when the program is made to exit.
+	aborted bool
+	// evaluating is set while the server evaluates a form in a frame, of
+	// which the evaluations are not stepped.
+	evaluating bool
+	// stopped is called with the stopped event when the program stops.
+	stopped func(StoppedEvent)
+}
+
+func newDebugger(stopped func(StoppedEvent)) *debugger {
+	return &debugger{
+		breakpoints: map[string]map[int]bool{},
+		uncaught:    true,
+		resume:      make(chan bool),
+		stopped:     stopped,
+	}
+}
+
+// canonical returns the absolute path of the file at path, with which the
+// breakpoints and the sources are compared.
+func canonical(path string) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return filepath.Clean(path)
+}
+
+// Enter stops the program before form if the current mode, a breakpoint or
+// a pause request says so.
+func (d *debugger) Enter(e env.Environment, form ilos.Instance, source parser.Source) ilos.Instance {
+	d.mutex.Lock()
+	if d.evaluating {
+		d.mutex.Unlock()
+		return nil
+	}
+	if d.aborted {
+		d.mutex.Unlock()
+		return instance.NewExit(instance.NewInteger(1))
+	}
+	d.stack = append(d.stack, &entry{e, form, source})
+	depth := len(d.stack)
+	reason := ""
+	switch {
+	case d.mode == entering:
+		reason = reasonEntry
+	case d.mode == stepIn, d.mode == stepOver && depth <= d.depth, d.mode == stepOut && depth < d.depth:
+		reason = reasonStep
+	case d.mode == pausing:
+		reason = reasonPause
+	case d.breakpoint(depth):
+		reason = reasonBreakpoint
+	}
+	if reason == "" {
+		d.mutex.Unlock()
+		return nil
+	}
+	if d.stop(StoppedEvent{Reason: reason}) {
+		return instance.NewExit(instance.NewInteger(1))
+	}
+	return nil
+}
+
+// Leave pops the form from the stack.
+func (d *debugger) Leave(e env.Environment, form ilos.Instance) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.evaluating {
+		return
+	}
+	if n := len(d.stack); n > 0 && d.stack[n-1].form == form {
+		d.stack = d.stack[:n-1]
+	}
+}
+
+// breakpoint reports whether the form at the top of the stack of depth
+// starts a line with a breakpoint. The forms in a form on the same line do
+// not stop the program again.
+func (d *debugger) breakpoint(depth int) bool {
+	source := d.stack[depth-1].source
+	if !d.breakpoints[canonical(source.Name)][source.Start.Line] {
+		return false
+	}
+	if depth == 1 {
+		return true
+	}
+	parent := d.stack[depth-2].source
+	return parent.Name != source.Name || parent.Start.Line != source.Start.Line
+}
+
+// stop stops the program, which is called with the mutex locked, and waits
+// for the server to resume it. It reports whether the program is to exit.
+func (d *debugger) stop(event StoppedEvent) bool {
+	d.frames = d.collectFrames()
+	d.depth = len(d.stack)
+	d.mode = running
+	d.mutex.Unlock()
+	event.ThreadID = mainThread
+	event.AllThreadsStopped = true
+	d.stopped(event)
+	abort := <-d.resume
+	d.mutex.Lock()
+	d.frames = nil
+	if abort {
+		d.aborted = true
+	}
+	d.mutex.Unlock()
+	return abort
+}
+
+// uncaughtCondition is the handler of the top level, which stops the program
+// on the conditions which are not handled if it is asked to.
+func (d *debugger) uncaughtCondition(e env.Environment, condition ilos.Instance) (ilos.Instance, ilos.Instance) {
+	d.mutex.Lock()
+	if !d.uncaught || d.evaluating || d.aborted || len(d.stack) == 0 {
+		d.mutex.Unlock()
+		return nil, condition
+	}
+	text := report(e, condition)
+	if d.stop(StoppedEvent{Reason: reasonException, Description: "Paused on an uncaught condition", Text: text}) {
+		return nil, instance.NewExit(instance.NewInteger(1))
+	}
+	return nil, condition
+}
+
+// collectFrames returns the frames of the calls in the stack, the innermost
+// first, with the forms being evaluated in them.
+func (d *debugger) collectFrames() []frame {
+	frames := []frame{}
+	for i := len(d.stack) - 1; i >= 0; i-- {
+		e := d.stack[i].e
+		if len(frames) > 0 && frames[len(frames)-1].entry.e.Frame == e.Frame {
+			continue
+		}
+		name := "top level"
+		if e.Frame != nil {
+			arguments, _ := runtime.List(runtime.TopLevel, e.Frame.Arguments...)
+			name = instance.NewCons(e.Frame.Name, arguments).String()
+		}
+		frames = append(frames, frame{name, d.stack[i]})
+	}
+	return frames
+}
+
+// continueWith resumes the stopped program in mode.
+func (d *debugger) continueWith(mode int) bool {
+	d.mutex.Lock()
+	if d.frames == nil {
+		d.mutex.Unlock()
+		return false
+	}
+	d.mode = mode
+	d.mutex.Unlock()
+	d.resume <- false
+	return true
+}
+
+// abort makes the program exit at the next form, or now if it is stopped.
+func (d *debugger) abort() {
+	d.mutex.Lock()
+	stopped := d.frames != nil
+	d.aborted = true
+	d.mutex.Unlock()
+	if stopped {
+		d.resume <- true
+	}
+}
+
+// frame returns the frame of id, which is numbered from 1, or nil if the
+// program is not stopped there.
+func (d *debugger) frame(id int) *frame {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if id < 1 || id > len(d.frames) {
+		return nil
+	}
+	return &d.frames[id-1]
+}
+
+// locals returns the variables bound lexically in the frame, the innermost
+// first. The global variables are not included.
+func (f *frame) locals() []Variable {
+	variables := f.entry.e.Variable
+	seen := map[ilos.Instance]bool{}
+	locals := []Variable{}
+	for i := len(variables) - 1; i > 0; i-- {
+		names := []ilos.Instance{}
+		for name := range variables[i] {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		sort.Slice(names, func(a, b int) bool { return names[a].String() < names[b].String() })
+		for _, name := range names {
+			locals = append(locals, Variable{Name: name.String(), Value: variables[i][name].String()})
+		}
+	}
+	return locals
+}
+
+// report returns the report of condition.
+func report(e env.Environment, condition ilos.Instance) string {
+	stream, err := runtime.CreateStringOutputStream(e)
+	if err != nil {
+		return condition.String()
+	}
+	if _, err := runtime.ReportCondition(e, condition, stream); err != nil {
+		return condition.String()
+	}
+	s, err := runtime.GetOutputStreamString(e, stream)
+	if err != nil {
+		return condition.String()
+	}
+	return string(s.(instance.String))
+}
@@ -0,0 +1,391 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package dap implements a debug adapter of ISLisp, which speaks the Debug
+// Adapter Protocol. It runs a script with the breakpoints set by the client,
+// stepping through the forms read from the source files.
+package dap
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/islisp-dev/iris/reader/parser"
+	"github.com/islisp-dev/iris/reader/tokenizer"
+	"github.com/islisp-dev/iris/runtime"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// mainThread is the ID of the only thread, which runs the program.
+const mainThread = 1
+
+// Server serves a client over a stream. The program runs in another goroutine
+// after the configuration is done.
+type Server struct {
+	conn     *conn
+	debugger *debugger
+	launch   *LaunchArguments
+	// done is closed when the program exits.
+	done chan struct{}
+	// after is called after the response to the current request is sent.
+	after func()
+}
+
+// NewServer returns a server which reads the messages of the client from in
+// and writes the messages to the client to out.
+func NewServer(in io.Reader, out io.Writer) *Server {
+	s := &Server{conn: newConn(in, out)}
+	s.debugger = newDebugger(func(event StoppedEvent) { s.event("stopped", event) })
+	return s
+}
+
+// Run serves the client until it disconnects or the input ends. It returns 0
+// if the client disconnects, and 1 otherwise.
+func (s *Server) Run() int {
+	for {
+		m, err := s.conn.read()
+		if err != nil {
+			s.disconnect()
+			return 1
+		}
+		if m.Type != "request" {
+			continue
+		}
+		s.after = nil
+		body, err := s.handle(m)
+		s.respond(m, body, err)
+		if s.after != nil {
+			s.after()
+		}
+		if m.Command == "disconnect" {
+			return 0
+		}
+	}
+}
+
+// respond sends the response to the request m.
+func (s *Server) respond(m *message, body interface{}, err error) {
+	success := err == nil
+	r := &message{Type: "response", RequestSeq: m.Seq, Command: m.Command, Success: &success}
+	if err != nil {
+		r.Message = err.Error()
+	} else if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			success = false
+			r.Message = err.Error()
+		}
+		r.Body = data
+	}
+	s.conn.write(r)
+}
+
+// event sends an event to the client.
+func (s *Server) event(name string, body interface{}) {
+	m := &message{Type: "event", Event: name}
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return
+		}
+		m.Body = data
+	}
+	s.conn.write(m)
+}
+
+// handle returns the body of the response to the request m.
+func (s *Server) handle(m *message) (interface{}, error) {
+	switch m.Command {
+	case "initialize":
+		s.after = func() { s.event("initialized", nil) }
+		return Capabilities{
+			SupportsConfigurationDoneRequest: true,
+			SupportsEvaluateForHovers:        true,
+			ExceptionBreakpointFilters: []ExceptionBreakpointsFilter{
+				{Filter: "uncaught", Label: "Uncaught Conditions", Default: true},
+			},
+		}, nil
+	case "launch":
+		var args LaunchArguments
+		if err := unmarshal(m, &args); err != nil {
+			return nil, err
+		}
+		if _, err := os.Stat(args.Program); err != nil {
+			return nil, err
+		}
+		s.launch = &args
+		return nil, nil
+	case "setBreakpoints":
+		var args SetBreakpointsArguments
+		if err := unmarshal(m, &args); err != nil {
+			return nil, err
+		}
+		return s.setBreakpoints(args), nil
+	case "setExceptionBreakpoints":
+		var args SetExceptionBreakpointsArguments
+		if err := unmarshal(m, &args); err != nil {
+			return nil, err
+		}
+		uncaught := false
+		for _, filter := range args.Filters {
+			uncaught = uncaught || filter == "uncaught"
+		}
+		s.debugger.mutex.Lock()
+		s.debugger.uncaught = uncaught
+		s.debugger.mutex.Unlock()
+		return nil, nil
+	case "configurationDone":
+		if s.launch == nil {
+			return nil, fmt.Errorf("no program is launched")
+		}
+		if s.done == nil {
+			s.done = make(chan struct{})
+			s.after = func() { go s.run(*s.launch) }
+		}
+		return nil, nil
+	case "threads":
+		return ThreadsResponse{Threads: []Thread{{ID: mainThread, Name: "main"}}}, nil
+	case "stackTrace":
+		return s.stackTrace(), nil
+	case "scopes":
+		var args ScopesArguments
+		if err := unmarshal(m, &args); err != nil {
+			return nil, err
+		}
+		if s.debugger.frame(args.FrameID) == nil {
+			return nil, fmt.Errorf("no frame %v", args.FrameID)
+		}
+		return ScopesResponse{Scopes: []Scope{{Name: "Locals", VariablesReference: args.FrameID}}}, nil
+	case "variables":
+		var args VariablesArguments
+		if err := unmarshal(m, &args); err != nil {
+			return nil, err
+		}
+		f := s.debugger.frame(args.VariablesReference)
+		if f == nil {
+			return nil, fmt.Errorf("no variables %v", args.VariablesReference)
+		}
+		return VariablesResponse{Variables: f.locals()}, nil
+	case "evaluate":
+		var args EvaluateArguments
+		if err := unmarshal(m, &args); err != nil {
+			return nil, err
+		}
+		return s.evaluate(args)
+	case "continue", "next", "stepIn", "stepOut":
+		if s.debugger.frame(1) == nil {
+			return nil, fmt.Errorf("the program is not stopped")
+		}
+		mode := map[string]int{"continue": running, "next": stepOver, "stepIn": stepIn, "stepOut": stepOut}[m.Command]
+		s.after = func() { s.debugger.continueWith(mode) }
+		if m.Command == "continue" {
+			return ContinueResponse{AllThreadsContinued: true}, nil
+		}
+		return nil, nil
+	case "pause":
+		s.debugger.mutex.Lock()
+		if s.debugger.frames == nil {
+			s.debugger.mode = pausing
+		}
+		s.debugger.mutex.Unlock()
+		return nil, nil
+	case "disconnect":
+		s.after = s.disconnect
+		return nil, nil
+	}
+	return nil, fmt.Errorf("unknown command: %v", m.Command)
+}
+
+func unmarshal(m *message, args interface{}) error {
+	if len(m.Arguments) == 0 {
+		return nil
+	}
+	return json.Unmarshal(m.Arguments, args)
+}
+
+// disconnect makes the program exit and waits for it.
+func (s *Server) disconnect() {
+	if s.done == nil {
+		return
+	}
+	s.debugger.abort()
+	<-s.done
+}
+
+// setBreakpoints replaces the breakpoints in the file. A breakpoint is moved
+// to the first line starting a list at or after it, since only the lists are
+// stepped.
+func (s *Server) setBreakpoints(args SetBreakpointsArguments) SetBreakpointsResponse {
+	path := canonical(args.Source.Path)
+	source := &Source{Name: filepath.Base(path), Path: args.Source.Path}
+	starts := listStarts(path)
+	lines := map[int]bool{}
+	breakpoints := []Breakpoint{}
+	for _, b := range args.Breakpoints {
+		i := 0
+		for i < len(starts) && starts[i] < b.Line {
+			i++
+		}
+		if i == len(starts) {
+			breakpoints = append(breakpoints, Breakpoint{Message: "no form at or after the line", Source: source, Line: b.Line})
+			continue
+		}
+		lines[starts[i]] = true
+		breakpoints = append(breakpoints, Breakpoint{Verified: true, Source: source, Line: starts[i]})
+	}
+	s.debugger.mutex.Lock()
+	s.debugger.breakpoints[path] = lines
+	s.debugger.mutex.Unlock()
+	return SetBreakpointsResponse{Breakpoints: breakpoints}
+}
+
+// listStarts returns the lines on which the lists in the file at path start,
+// in ascending order without duplicates.
+func listStarts(path string) []int {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil
+	}
+	defer file.Close()
+	r := tokenizer.NewReader(file)
+	lines := []int{}
+	for {
+		tok, err := r.Next()
+		if err != nil {
+			return lines
+		}
+		line := r.TokenPosition().Line
+		if tok == "(" && (len(lines) == 0 || lines[len(lines)-1] != line) {
+			lines = append(lines, line)
+		}
+	}
+}
+
+// stackTrace returns the frames of the stopped program, which are numbered
+// from 1 for the innermost one.
+func (s *Server) stackTrace() StackTraceResponse {
+	s.debugger.mutex.Lock()
+	defer s.debugger.mutex.Unlock()
+	frames := []StackFrame{}
+	for i, f := range s.debugger.frames {
+		source := f.entry.source
+		frames = append(frames, StackFrame{
+			ID:     i + 1,
+			Name:   f.name,
+			Source: &Source{Name: filepath.Base(source.Name), Path: canonical(source.Name)},
+			Line:   source.Start.Line,
+			Column: source.Start.Column,
+		})
+	}
+	return StackTraceResponse{StackFrames: frames, TotalFrames: len(frames)}
+}
+
+// evaluate evaluates the expression in the frame, or the innermost frame if
+// the frame is not given. The conditions signaled there are reported as the
+// result rather than stopping the program.
+func (s *Server) evaluate(args EvaluateArguments) (interface{}, error) {
+	id := args.FrameID
+	if id == 0 {
+		id = 1
+	}
+	f := s.debugger.frame(id)
+	if f == nil {
+		return nil, fmt.Errorf("the program is not stopped")
+	}
+	form, err := parser.Parse(tokenizer.NewReader(strings.NewReader(args.Expression)))
+	if err != nil {
+		return nil, fmt.Errorf("%v", err)
+	}
+	e := f.entry.e
+	e.Handler = instance.NewFunction(instance.NewSymbol("TOP-LEVEL-HANDLER"), runtime.TopLevelHander)
+	s.debugger.mutex.Lock()
+	s.debugger.evaluating = true
+	s.debugger.mutex.Unlock()
+	defer func() {
+		s.debugger.mutex.Lock()
+		s.debugger.evaluating = false
+		s.debugger.mutex.Unlock()
+	}()
+	ret, err := runtime.Eval(e, form)
+	if err != nil {
+		return nil, fmt.Errorf("%v", report(e, err))
+	}
+	return EvaluateResponse{Result: ret.String()}, nil
+}
+
+// output is the standard output or the error output of the program, which
+// is sent to the client as the output events.
+type output struct {
+	s        *Server
+	category string
+}
+
+func (o output) Write(p []byte) (int, error) {
+	o.s.event("output", OutputEvent{Category: o.category, Output: string(p)})
+	return len(p), nil
+}
+
+// run runs the program, and tells the client when it exits.
+func (s *Server) run(args LaunchArguments) {
+	defer close(s.done)
+	if args.StopOnEntry {
+		s.debugger.mutex.Lock()
+		s.debugger.mode = entering
+		s.debugger.mutex.Unlock()
+	}
+	status := s.execute(args)
+	s.event("exited", ExitedEvent{ExitCode: status})
+	s.event("terminated", nil)
+}
+
+// execute evaluates the forms in the program with the debugger as the
+// stepper and the handler of the top level, and returns its exit status.
+func (s *Server) execute(args LaunchArguments) int {
+	file, err := os.Open(args.Program)
+	if err != nil {
+		s.event("output", OutputEvent{Category: "stderr", Output: err.Error() + "\n"})
+		return 1
+	}
+	defer file.Close()
+	saved := runtime.TopLevel
+	defer func() {
+		runtime.SetStepper(nil)
+		runtime.TopLevel.StandardInput = saved.StandardInput
+		runtime.TopLevel.StandardOutput = saved.StandardOutput
+		runtime.TopLevel.ErrorOutput = saved.ErrorOutput
+		runtime.TopLevel.Handler = saved.Handler
+	}()
+	input := instance.NewStream(file, nil).(instance.Stream)
+	input.Reader.Name = canonical(args.Program)
+	runtime.TopLevel.StandardInput = instance.NewStream(strings.NewReader(""), nil)
+	runtime.TopLevel.StandardOutput = instance.NewStream(nil, output{s, "stdout"})
+	runtime.TopLevel.ErrorOutput = instance.NewStream(nil, output{s, "stderr"})
+	runtime.TopLevel.Handler = instance.NewFunction(instance.NewSymbol("DEBUGGER"), s.debugger.uncaughtCondition)
+	runtime.SetCommandLineArguments(args.Args)
+	runtime.SetStepper(s.debugger)
+	for {
+		exp, err := runtime.Read(runtime.TopLevel, input)
+		if err != nil {
+			if !ilos.InstanceOf(class.EndOfStream, err) {
+				s.event("output", OutputEvent{Category: "stderr", Output: report(runtime.TopLevel, err) + "\n"})
+				return 1
+			}
+			return 0
+		}
+		_, err = runtime.Eval(runtime.TopLevel, exp)
+		if code, ok := runtime.ExitStatus(err); ok {
+			return code
+		}
+		if err != nil {
+			s.event("output", OutputEvent{Category: "stderr", Output: report(runtime.TopLevel, err) + "\n"})
+			return 1
+		}
+	}
+}
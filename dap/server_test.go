@@ -0,0 +1,339 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package dap
+
+import (
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// client drives a server running in another goroutine as a script.
+type client struct {
+	t      *testing.T
+	conn   *conn
+	in     io.Closer
+	status chan int
+	// received are the messages from the server, which are read in another
+	// goroutine so that the server never blocks on writing.
+	received chan *message
+	// events are the events received but not waited for yet.
+	events []*message
+	// output is the output of the program in the output events waited for.
+	output map[string]*strings.Builder
+}
+
+func newClient(t *testing.T) *client {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &client{t: t, conn: newConn(clientIn, clientOut), in: clientOut, status: make(chan int, 1), received: make(chan *message, 64), output: map[string]*strings.Builder{"stdout": {}, "stderr": {}}}
+	go func() {
+		c.status <- NewServer(serverIn, serverOut).Run()
+		serverOut.Close()
+	}()
+	go func() {
+		defer close(c.received)
+		for {
+			m, err := c.conn.read()
+			if err != nil {
+				return
+			}
+			c.received <- m
+		}
+	}()
+	return c
+}
+
+// next returns the next message from the server.
+func (c *client) next(what string) *message {
+	select {
+	case m, ok := <-c.received:
+		if !ok {
+			c.t.Fatalf("%v: the server exited", what)
+		}
+		return m
+	case <-time.After(10 * time.Second):
+		c.t.Fatalf("%v: timed out", what)
+	}
+	return nil
+}
+
+// request sends a request and returns the response to it.
+func (c *client) request(command string, args interface{}) *message {
+	m := &message{Type: "request", Command: command}
+	if args != nil {
+		data, err := json.Marshal(args)
+		if err != nil {
+			c.t.Fatal(err)
+		}
+		m.Arguments = data
+	}
+	if err := c.conn.write(m); err != nil {
+		c.t.Fatal(err)
+	}
+	for {
+		r := c.next(command)
+		if r.Type == "event" {
+			c.events = append(c.events, r)
+			continue
+		}
+		if r.RequestSeq != m.Seq || r.Command != command {
+			c.t.Fatalf("%v: got the response to %v", command, r.Command)
+		}
+		return r
+	}
+}
+
+// call sends a request, which must succeed, and unmarshals the body of the
+// response into body unless it is nil.
+func (c *client) call(command string, args, body interface{}) {
+	r := c.request(command, args)
+	if r.Success == nil || !*r.Success {
+		c.t.Fatalf("%v failed: %v", command, r.Message)
+	}
+	if body != nil {
+		if err := json.Unmarshal(r.Body, body); err != nil {
+			c.t.Fatalf("%v: %v", command, err)
+		}
+	}
+}
+
+// wait returns the next event named name, and unmarshals its body into body
+// unless it is nil. The output events before it are collected in c.output.
+func (c *client) wait(name string, body interface{}) {
+	for {
+		var m *message
+		if len(c.events) > 0 {
+			m, c.events = c.events[0], c.events[1:]
+		} else {
+			m = c.next(name)
+		}
+		if m.Type != "event" {
+			c.t.Fatalf("waiting for %v: got the response to %v", name, m.Command)
+		}
+		if m.Event == "output" {
+			var event OutputEvent
+			if err := json.Unmarshal(m.Body, &event); err != nil {
+				c.t.Fatal(err)
+			}
+			c.output[event.Category].WriteString(event.Output)
+		}
+		if m.Event != name {
+			continue
+		}
+		if body != nil {
+			if err := json.Unmarshal(m.Body, body); err != nil {
+				c.t.Fatalf("%v: %v", name, err)
+			}
+		}
+		return
+	}
+}
+
+// stopped waits for the stopped event and returns its reason and the names
+// and lines of the frames.
+func (c *client) stopped() (string, []string) {
+	var event StoppedEvent
+	c.wait("stopped", &event)
+	var trace StackTraceResponse
+	c.call("stackTrace", map[string]int{"threadId": mainThread}, &trace)
+	frames := []string{}
+	for _, f := range trace.StackFrames {
+		frames = append(frames, f.Name+" "+filepath.Base(f.Source.Path)+":"+strconv.Itoa(f.Line))
+	}
+	return event.Reason, frames
+}
+
+// start initializes the server and launches the program with the breakpoints
+// on lines.
+func (c *client) start(program string, stopOnEntry bool, lines ...int) []Breakpoint {
+	var capabilities Capabilities
+	c.call("initialize", map[string]string{"adapterID": "iris"}, &capabilities)
+	if !capabilities.SupportsConfigurationDoneRequest {
+		c.t.Errorf("capabilities = %+v", capabilities)
+	}
+	c.wait("initialized", nil)
+	c.call("launch", LaunchArguments{Program: program, StopOnEntry: stopOnEntry}, nil)
+	breakpoints := []SourceBreakpoint{}
+	for _, line := range lines {
+		breakpoints = append(breakpoints, SourceBreakpoint{Line: line})
+	}
+	var response SetBreakpointsResponse
+	c.call("setBreakpoints", SetBreakpointsArguments{Source: Source{Path: program}, Breakpoints: breakpoints}, &response)
+	c.call("configurationDone", nil, nil)
+	return response.Breakpoints
+}
+
+// disconnect disconnects the client and returns the exit status of the
+// server.
+func (c *client) disconnect() int {
+	c.call("disconnect", nil, nil)
+	c.in.Close()
+	return <-c.status
+}
+
+const program = `(defun square (x)
+  (* x x))
+(defun sum-squares (a b)
+  (let ((s (+ (square a) (square b))))
+    s))
+(format (standard-output) "~A~%" (sum-squares 3 4))
+(car 1)
+`
+
+func writeProgram(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "program.lsp")
+	if err := ioutil.WriteFile(path, []byte(program), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestServer_Breakpoints(t *testing.T) {
+	path := writeProgram(t)
+	c := newClient(t)
+	breakpoints := c.start(path, false, 2)
+	if len(breakpoints) != 1 || !breakpoints[0].Verified || breakpoints[0].Line != 2 {
+		t.Errorf("breakpoints = %+v", breakpoints)
+	}
+
+	reason, frames := c.stopped()
+	want := []string{"(SQUARE 3) program.lsp:2", "(SUM-SQUARES 3 4) program.lsp:4", "top level program.lsp:6"}
+	if reason != reasonBreakpoint || !reflect.DeepEqual(frames, want) {
+		t.Errorf("stopped by %v at %v, want %v at %v", reason, frames, reasonBreakpoint, want)
+	}
+	var scopes ScopesResponse
+	c.call("scopes", ScopesArguments{FrameID: 2}, &scopes)
+	if len(scopes.Scopes) != 1 || scopes.Scopes[0].Name != "Locals" {
+		t.Fatalf("scopes = %+v", scopes)
+	}
+	var variables VariablesResponse
+	c.call("variables", VariablesArguments{VariablesReference: scopes.Scopes[0].VariablesReference}, &variables)
+	if want := []Variable{{Name: "A", Value: "3"}, {Name: "B", Value: "4"}}; !reflect.DeepEqual(variables.Variables, want) {
+		t.Errorf("variables = %+v, want %+v", variables.Variables, want)
+	}
+	evaluations := []struct {
+		expression string
+		frame      int
+		want       string
+		wantErr    bool
+	}{
+		{"(+ x 1)", 1, "4", false},
+		{"(+ x 1)", 0, "4", false},
+		{"(list a b)", 2, "(3 4)", false},
+		{"(car x)", 1, "", true},
+		{"(+ x 1)", 3, "", true},
+	}
+	for _, test := range evaluations {
+		r := c.request("evaluate", EvaluateArguments{Expression: test.expression, FrameID: test.frame})
+		if !*r.Success != test.wantErr {
+			t.Errorf("evaluate %v in %v succeeded %v: %v", test.expression, test.frame, *r.Success, r.Message)
+			continue
+		}
+		var result EvaluateResponse
+		if !test.wantErr {
+			json.Unmarshal(r.Body, &result)
+		}
+		if result.Result != test.want {
+			t.Errorf("evaluate %v in %v = %v, want %v", test.expression, test.frame, result.Result, test.want)
+		}
+	}
+
+	c.call("stepOut", nil, nil)
+	reason, frames = c.stopped()
+	want = []string{"(SUM-SQUARES 3 4) program.lsp:4", "top level program.lsp:6"}
+	if reason != reasonStep || !reflect.DeepEqual(frames, want) {
+		t.Errorf("stepped out to %v, want %v", frames, want)
+	}
+
+	// Stepping over (square b) stops at the breakpoint in square unless it
+	// is cleared.
+	c.call("setBreakpoints", SetBreakpointsArguments{Source: Source{Path: path}}, nil)
+	c.call("next", nil, nil)
+	reason, frames = c.stopped()
+	if want := []string{"top level program.lsp:7"}; reason != reasonStep || !reflect.DeepEqual(frames, want) {
+		t.Errorf("stepped over to %v, want %v", frames, want)
+	}
+	if got := c.output["stdout"].String(); got != "25\n" {
+		t.Errorf("output = %q, want %q", got, "25\n")
+	}
+
+	c.call("continue", nil, nil)
+	var event StoppedEvent
+	c.wait("stopped", &event)
+	if event.Reason != reasonException || !strings.Contains(event.Text, "1") {
+		t.Errorf("stopped by %+v, want an exception", event)
+	}
+	c.call("continue", nil, nil)
+	var exited ExitedEvent
+	c.wait("exited", &exited)
+	if got := c.output["stderr"].String(); exited.ExitCode != 1 || got == "" {
+		t.Errorf("exited with %v after %q, want 1 after an error", exited.ExitCode, got)
+	}
+	c.wait("terminated", nil)
+	if status := c.disconnect(); status != 0 {
+		t.Errorf("exit status = %v, want 0", status)
+	}
+}
+
+func TestServer_StopOnEntry(t *testing.T) {
+	path := writeProgram(t)
+	c := newClient(t)
+	c.start(path, true)
+	if reason, frames := c.stopped(); reason != reasonEntry || !reflect.DeepEqual(frames, []string{"top level program.lsp:1"}) {
+		t.Errorf("stopped by %v at %v", reason, frames)
+	}
+	c.call("stepIn", nil, nil)
+	if reason, frames := c.stopped(); reason != reasonStep || !reflect.DeepEqual(frames, []string{"top level program.lsp:3"}) {
+		t.Errorf("stepped in to %v", frames)
+	}
+	if r := c.request("variables", VariablesArguments{VariablesReference: 2}); *r.Success {
+		t.Errorf("variables of a frame which does not exist succeeded")
+	}
+	// Disconnecting makes the stopped program exit.
+	if status := c.disconnect(); status != 0 {
+		t.Errorf("exit status = %v, want 0", status)
+	}
+}
+
+func TestServer_NoExceptionBreakpoints(t *testing.T) {
+	path := writeProgram(t)
+	c := newClient(t)
+	c.call("initialize", nil, nil)
+	c.call("launch", LaunchArguments{Program: path}, nil)
+	var response SetBreakpointsResponse
+	c.call("setBreakpoints", SetBreakpointsArguments{
+		Source:      Source{Path: path},
+		Breakpoints: []SourceBreakpoint{{Line: 5}, {Line: 100}},
+	}, &response)
+	got := []Breakpoint{}
+	for _, b := range response.Breakpoints {
+		got = append(got, Breakpoint{Verified: b.Verified, Line: b.Line})
+	}
+	if want := []Breakpoint{{Verified: true, Line: 6}, {Verified: false, Line: 100}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("breakpoints = %+v, want %+v", got, want)
+	}
+	c.call("setBreakpoints", SetBreakpointsArguments{Source: Source{Path: path}}, nil)
+	c.call("setExceptionBreakpoints", SetExceptionBreakpointsArguments{Filters: []string{}}, nil)
+	if r := c.request("continue", nil); *r.Success {
+		t.Errorf("continue before the program runs succeeded")
+	}
+	c.call("configurationDone", nil, nil)
+	var exited ExitedEvent
+	c.wait("exited", &exited)
+	stdout, stderr := c.output["stdout"].String(), c.output["stderr"].String()
+	if exited.ExitCode != 1 || stdout != "25\n" || !strings.Contains(stderr, "<CONS>") {
+		t.Errorf("exited with %v after %q and %q", exited.ExitCode, stdout, stderr)
+	}
+	if status := c.disconnect(); status != 0 {
+		t.Errorf("exit status = %v, want 0", status)
+	}
+}
@@ -0,0 +1,541 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package lint
+
+import (
+	"strings"
+
+	"github.com/islisp-dev/iris/reader/parser"
+	"github.com/islisp-dev/iris/runtime"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// binding is a lexical variable.
+type binding struct {
+	at   parser.Source
+	used bool
+}
+
+// scope is the variables and the functions bound by a form, such as let or
+// flet, in the scope of the forms around it.
+type scope struct {
+	parent    *scope
+	variables map[ilos.Instance]*binding
+	// functions are the arities of the local functions.
+	functions map[ilos.Instance]*arity
+}
+
+func (s *scope) extend() *scope {
+	return &scope{parent: s, variables: map[ilos.Instance]*binding{}, functions: map[ilos.Instance]*arity{}}
+}
+
+func (s *scope) variable(name ilos.Instance) (*binding, bool) {
+	for ; s != nil; s = s.parent {
+		if b, ok := s.variables[name]; ok {
+			return b, true
+		}
+	}
+	return nil, false
+}
+
+func (s *scope) function(name ilos.Instance) (*arity, bool) {
+	for ; s != nil; s = s.parent {
+		if a, ok := s.functions[name]; ok {
+			return a, true
+		}
+	}
+	return nil, false
+}
+
+// unused reports the variables in s which are not referred to.
+func (l *linter) unused(s *scope, names []ilos.Instance) {
+	for _, name := range names {
+		if b := s.variables[name]; b != nil && !b.used {
+			l.report(b.at, UnusedVariable, "variable %v is not used", name)
+		}
+	}
+}
+
+// sourceOf returns the position of form if it is read from an input, or at.
+func sourceOf(form ilos.Instance, at parser.Source) parser.Source {
+	if cons, ok := form.(*instance.Cons); ok {
+		if source, ok := parser.Sources[cons]; ok {
+			return source
+		}
+	}
+	return at
+}
+
+func (l *linter) forms(forms []ilos.Instance, s *scope, at parser.Source) {
+	for _, form := range forms {
+		l.form(form, s, at)
+	}
+}
+
+// form checks form, which is evaluated in s, at the position of the form in
+// which it is or its own.
+func (l *linter) form(form ilos.Instance, s *scope, at parser.Source) {
+	switch form := form.(type) {
+	case instance.Symbol:
+		l.variable(form, s, at)
+	case *instance.Cons:
+		l.list(form, s, sourceOf(form, at))
+	}
+}
+
+// variable checks the reference to the variable name.
+func (l *linter) variable(name ilos.Instance, s *scope, at parser.Source) {
+	if name == runtime.Nil || name == runtime.T || strings.HasPrefix(name.String(), ":") {
+		return
+	}
+	if b, ok := s.variable(name); ok {
+		b.used = true
+		return
+	}
+	if d, ok := l.variables[name]; ok && d.kind != dynamic {
+		return
+	}
+	if _, ok := runtime.TopLevel.Variable[0][name]; ok {
+		return
+	}
+	if _, ok := runtime.TopLevel.Constant[0][name]; ok {
+		return
+	}
+	l.report(at, UndefinedVariable, "undefined variable %v", name)
+}
+
+// isConstant reports whether name is a global constant.
+func (l *linter) isConstant(name ilos.Instance) bool {
+	if d, ok := l.variables[name]; ok {
+		return d.kind == constant
+	}
+	_, ok := runtime.TopLevel.Constant[0][name]
+	return ok
+}
+
+// assignment checks the variable assigned by setq or setf.
+func (l *linter) assignment(name ilos.Instance, s *scope, at parser.Source) {
+	if _, ok := s.variable(name); ok {
+		return
+	}
+	if l.isConstant(name) {
+		l.report(at, SetqConstant, "assignment to constant %v", name)
+		return
+	}
+	if d, ok := l.variables[name]; ok && d.kind != dynamic {
+		return
+	}
+	if _, ok := runtime.TopLevel.Variable[0][name]; ok {
+		return
+	}
+	l.report(at, UndefinedVariable, "undefined variable %v", name)
+}
+
+// functionArity returns the arity of the global function name, and whether
+// it is defined.
+func (l *linter) functionArity(name ilos.Instance) (*arity, bool) {
+	if d, ok := l.functions[name]; ok {
+		return d.arity, d.kind == function
+	}
+	f, ok := runtime.TopLevel.Function[0][name]
+	if !ok {
+		return nil, false
+	}
+	if f, ok := f.(instance.Function); ok {
+		min, max := f.Arity()
+		return &arity{min, max}, true
+	}
+	return nil, true
+}
+
+// function checks the reference to the function name, such as #'name.
+func (l *linter) function(name ilos.Instance, s *scope, at parser.Source) {
+	if cons, ok := name.(*instance.Cons); ok && cons.Car == symbol("LAMBDA") {
+		l.list(cons, s, sourceOf(cons, at))
+		return
+	}
+	if _, ok := s.function(name); ok {
+		return
+	}
+	if _, ok := l.functionArity(name); !ok {
+		l.report(at, UndefinedFunction, "undefined function %v", name)
+	}
+}
+
+// call checks the call of a function named name with n arguments.
+func (l *linter) call(name ilos.Instance, n int, s *scope, at parser.Source) {
+	a, ok := s.function(name)
+	if !ok {
+		a, ok = l.functionArity(name)
+	}
+	if !ok {
+		l.report(at, UndefinedFunction, "undefined function %v", name)
+		return
+	}
+	if !a.accepts(n) {
+		l.report(at, ArityMismatch, "%v takes %v arguments but is given %v", name, a, n)
+	}
+}
+
+// macro returns the macro named name, or nil.
+func (l *linter) macro(name ilos.Instance, s *scope) ilos.Instance {
+	if _, ok := s.function(name); ok {
+		return nil
+	}
+	if d, ok := l.functions[name]; ok && d.kind != macro {
+		return nil
+	}
+	if m, ok := runtime.TopLevel.Macro[0][name]; ok {
+		return m
+	}
+	return nil
+}
+
+// list checks a compound form.
+func (l *linter) list(form *instance.Cons, s *scope, at parser.Source) {
+	if l.code != nil {
+		l.code = append(l.code, form)
+	}
+	xs := elements(form)
+	head := xs[0]
+	if lambda, ok := head.(*instance.Cons); ok && lambda.Car == symbol("LAMBDA") {
+		l.list(lambda, s, sourceOf(lambda, at))
+		if _, a := parameters(nth(elements(lambda), 1)); !a.accepts(len(xs) - 1) {
+			l.report(at, ArityMismatch, "lambda takes %v arguments but is given %v", a, len(xs)-1)
+		}
+		l.forms(xs[1:], s, at)
+		return
+	}
+	if !isSymbol(head) {
+		return
+	}
+	if _, ok := s.function(head); !ok {
+		if check, ok := specialForms[head]; ok {
+			check(l, xs, s, at)
+			return
+		}
+		if _, ok := runtime.TopLevel.Special[0][head]; ok {
+			// The special forms which are not known are not checked.
+			return
+		}
+		if m := l.macro(head, s); m != nil {
+			if l.code != nil {
+				l.forms(xs[1:], s, at)
+				return
+			}
+			expansion, err := m.(instance.Applicable).Apply(runtime.TopLevel.NewDynamic(), xs[1:]...)
+			if err != nil {
+				l.report(at, MacroExpansion, "cannot expand macro %v: %v", head, message(err))
+				return
+			}
+			l.form(expansion, s, at)
+			return
+		}
+	}
+	l.call(head, len(xs)-1, s, at)
+	l.forms(xs[1:], s, at)
+}
+
+// lambda checks body in the scope of the parameters in lambdaList.
+func (l *linter) lambda(lambdaList ilos.Instance, body []ilos.Instance, s *scope, at parser.Source) {
+	inner := s.extend()
+	variables, _ := parameters(lambdaList)
+	for _, v := range variables {
+		inner.variables[v] = &binding{at: at, used: true}
+	}
+	l.forms(body, inner, at)
+}
+
+// specialForms check the special forms whose syntax is known. They are given
+// the elements of the forms.
+var specialForms map[ilos.Instance]func(l *linter, xs []ilos.Instance, s *scope, at parser.Source)
+
+func init() {
+	evaluated := func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+		l.forms(xs[1:], s, at)
+	}
+	after := func(n int) func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+		return func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+			if n < len(xs) {
+				l.forms(xs[n:], s, at)
+			}
+		}
+	}
+	nothing := func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {}
+	specialForms = map[ilos.Instance]func(l *linter, xs []ilos.Instance, s *scope, at parser.Source){
+		symbol("QUOTE"):      nothing,
+		symbol("CLASS"):      nothing,
+		symbol("DYNAMIC"):    nothing,
+		symbol("GO"):         nothing,
+		symbol("DEFCLASS"):   nothing,
+		symbol("DEFGENERIC"): nothing,
+		symbol("DEFSUITE"):   nothing,
+		symbol("IN-SUITE"):   nothing,
+		symbol("TRACE"):      nothing,
+		symbol("UNTRACE"):    nothing,
+		symbol("FUNCTION"): func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+			l.function(nth(xs, 1), s, at)
+		},
+		symbol("QUASIQUOTE"): func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+			l.quasiquote(nth(xs, 1), s, at, 1)
+		},
+		symbol("LAMBDA"): func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+			l.lambda(nth(xs, 1), from(xs, 2), s, at)
+		},
+		symbol("DEFUN"): func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+			l.lambda(nth(xs, 2), from(xs, 3), s, at)
+		},
+		symbol("DEFMACRO"): func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+			l.lambda(nth(xs, 2), from(xs, 3), s, at)
+		},
+		symbol("DEFMETHOD"): func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+			for i := 2; i < len(xs); i++ {
+				if !isSymbol(xs[i]) {
+					// The methods may call the next methods.
+					inner := s.extend()
+					inner.functions[symbol("CALL-NEXT-METHOD")] = &arity{0, 0}
+					inner.functions[symbol("NEXT-METHOD-P")] = &arity{0, 0}
+					l.lambda(xs[i], from(xs, i+1), inner, at)
+					return
+				}
+			}
+		},
+		symbol("DEFGLOBAL"):    after(2),
+		symbol("DEFCONSTANT"):  after(2),
+		symbol("DEFDYNAMIC"):   after(2),
+		symbol("DEFTEST"):      after(2),
+		symbol("BLOCK"):        after(2),
+		symbol("RETURN-FROM"):  after(2),
+		symbol("THE"):          after(2),
+		symbol("ASSURE"):       after(2),
+		symbol("ASSERT-ERROR"): after(2),
+		symbol("CONVERT"): func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+			l.form(nth(xs, 1), s, at)
+		},
+		symbol("SETQ"): func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+			l.assignment(nth(xs, 1), s, at)
+			l.forms(from(xs, 2), s, at)
+		},
+		symbol("SETF"): func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+			if place := nth(xs, 1); isSymbol(place) {
+				l.assignment(place, s, at)
+			} else {
+				l.form(place, s, at)
+			}
+			l.forms(from(xs, 2), s, at)
+		},
+		symbol("TAGBODY"): func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+			for _, x := range xs[1:] {
+				if _, ok := x.(*instance.Cons); ok {
+					l.form(x, s, at)
+				}
+			}
+		},
+		symbol("COND"):       cond,
+		symbol("CASE"):       cases(2),
+		symbol("CASE-USING"): cases(3),
+		symbol("LET"):        let,
+		symbol("LET*"):       let,
+		symbol("DYNAMIC-LET"): func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+			for _, b := range elements(nth(xs, 1)) {
+				l.forms(from(elements(b), 1), s, at)
+			}
+			l.forms(from(xs, 2), s, at)
+		},
+		symbol("FLET"):   flet,
+		symbol("LABELS"): flet,
+		symbol("FOR"):    forLoop,
+		symbol("HANDLER-CASE"): func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+			l.form(nth(xs, 1), s, at)
+			for _, clause := range from(xs, 2) {
+				c := elements(clause)
+				l.lambda(nth(c, 1), from(c, 2), s, sourceOf(clause, at))
+			}
+		},
+		symbol("RESTART-CASE"): func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+			l.form(nth(xs, 1), s, at)
+			for _, clause := range from(xs, 2) {
+				c := elements(clause)
+				body := from(c, 2)
+				for len(body) > 1 && (body[0] == symbol(":REPORT") || body[0] == symbol(":INTERACTIVE")) {
+					if body[0] == symbol(":INTERACTIVE") {
+						l.form(body[1], s, at)
+					}
+					body = body[2:]
+				}
+				l.lambda(nth(c, 1), body, s, sourceOf(clause, at))
+			}
+		},
+		symbol("WITH-RESTARTS"): func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+			for _, b := range elements(nth(xs, 1)) {
+				l.forms(from(elements(b), 1), s, at)
+			}
+			l.forms(from(xs, 2), s, at)
+		},
+		symbol("WITH-OPEN-INPUT-FILE"):  withOpenFile,
+		symbol("WITH-OPEN-OUTPUT-FILE"): withOpenFile,
+		symbol("WITH-OPEN-IO-FILE"):     withOpenFile,
+	}
+	for _, name := range []string{
+		"IF", "PROGN", "WHILE", "AND", "OR", "CATCH", "THROW", "UNWIND-PROTECT", "IGNORE-ERRORS",
+		"WITH-HANDLER", "WITH-STANDARD-INPUT", "WITH-STANDARD-OUTPUT", "WITH-ERROR-OUTPUT",
+		"ASSERT-TRUE", "ASSERT-FALSE", "ASSERT-EQUAL", "TIME",
+	} {
+		specialForms[symbol(name)] = evaluated
+	}
+}
+
+// quasiquote checks the forms unquoted in form, which is quasiquoted depth
+// times.
+func (l *linter) quasiquote(form ilos.Instance, s *scope, at parser.Source, depth int) {
+	cons, ok := form.(*instance.Cons)
+	if !ok {
+		return
+	}
+	at = sourceOf(cons, at)
+	switch cons.Car {
+	case symbol("UNQUOTE"), symbol("UNQUOTE-SPLICING"):
+		if depth == 1 {
+			l.form(nth(elements(cons), 1), s, at)
+		} else {
+			l.quasiquote(nth(elements(cons), 1), s, at, depth-1)
+		}
+		return
+	case symbol("QUASIQUOTE"):
+		l.quasiquote(nth(elements(cons), 1), s, at, depth+1)
+		return
+	}
+	for ; ok; cons, ok = cons.Cdr.(*instance.Cons) {
+		l.quasiquote(cons.Car, s, at, depth)
+	}
+}
+
+// isTrue reports whether the test of a cond clause is always true, that is,
+// it is t or a literal other than nil.
+func isTrue(test ilos.Instance) bool {
+	switch test.(type) {
+	case instance.Symbol:
+		return test == runtime.T || strings.HasPrefix(test.String(), ":")
+	case *instance.Cons:
+		return false
+	}
+	return test != runtime.Nil
+}
+
+// cond checks the clauses of cond, and reports the first clause after a
+// clause whose test is always true.
+func cond(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+	always := false
+	for _, clause := range xs[1:] {
+		c := elements(clause)
+		if always {
+			l.report(sourceOf(clause, at), UnreachableClause, "clause is never reached since a preceding test is always true")
+			always = false
+		}
+		l.forms(c, s, sourceOf(clause, at))
+		if len(c) > 0 && isTrue(c[0]) {
+			always = true
+		}
+	}
+}
+
+// cases checks case and case-using. The forms before the nth are evaluated,
+// and so are the forms of the clauses after their keys.
+func cases(n int) func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+	return func(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+		for i := 1; i < n && i < len(xs); i++ {
+			l.form(xs[i], s, at)
+		}
+		for _, clause := range from(xs, n) {
+			l.forms(from(elements(clause), 1), s, sourceOf(clause, at))
+		}
+	}
+}
+
+// let checks let and let*, and reports the variables bound by them which are
+// not referred to.
+func let(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+	inner := s.extend()
+	names := []ilos.Instance{}
+	for _, b := range elements(nth(xs, 1)) {
+		bs := elements(b)
+		if xs[0] == symbol("LET*") {
+			l.forms(from(bs, 1), inner, sourceOf(b, at))
+			// A variable rebound by let* shadows the earlier binding from
+			// the following init forms.
+			inner = inner.extend()
+		} else {
+			l.forms(from(bs, 1), s, sourceOf(b, at))
+		}
+		if name := nth(bs, 0); isSymbol(name) {
+			inner.variables[name] = &binding{at: sourceOf(b, at)}
+			names = append(names, name)
+		}
+	}
+	l.forms(from(xs, 2), inner, at)
+	for scope := inner; scope != s; scope = scope.parent {
+		l.unused(scope, names)
+	}
+}
+
+// flet checks flet and labels, and reports the functions bound twice by
+// them. The functions bound by labels are in the scope of their own
+// definitions.
+func flet(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+	inner := s.extend()
+	definitions := s
+	if xs[0] == symbol("LABELS") {
+		definitions = inner
+	}
+	for _, b := range elements(nth(xs, 1)) {
+		bs := elements(b)
+		name := nth(bs, 0)
+		if _, ok := inner.functions[name]; ok {
+			l.report(sourceOf(b, at), DuplicateBinding, "function %v is bound twice by %v", name, xs[0])
+		}
+		_, a := parameters(nth(bs, 1))
+		inner.functions[name] = a
+	}
+	for _, b := range elements(nth(xs, 1)) {
+		bs := elements(b)
+		l.lambda(nth(bs, 1), from(bs, 2), definitions, sourceOf(b, at))
+	}
+	l.forms(from(xs, 2), inner, at)
+}
+
+// forLoop checks (for ((var init [step])*) (end-test result*) form*), and
+// reports the variables which are not referred to.
+func forLoop(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+	inner := s.extend()
+	names := []ilos.Instance{}
+	specs := elements(nth(xs, 1))
+	for _, spec := range specs {
+		l.form(nth(elements(spec), 1), s, sourceOf(spec, at))
+	}
+	for _, spec := range specs {
+		if name := nth(elements(spec), 0); isSymbol(name) {
+			inner.variables[name] = &binding{at: sourceOf(spec, at)}
+			names = append(names, name)
+		}
+	}
+	for _, spec := range specs {
+		l.forms(from(elements(spec), 2), inner, sourceOf(spec, at))
+	}
+	l.forms(elements(nth(xs, 2)), inner, at)
+	l.forms(from(xs, 3), inner, at)
+	l.unused(inner, names)
+}
+
+// withOpenFile checks (with-open-input-file (var filename [class]) form*).
+func withOpenFile(l *linter, xs []ilos.Instance, s *scope, at parser.Source) {
+	spec := elements(nth(xs, 1))
+	l.forms(from(spec, 1), s, at)
+	inner := s.extend()
+	if name := nth(spec, 0); isSymbol(name) {
+		inner.variables[name] = &binding{at: at, used: true}
+	}
+	l.forms(from(xs, 2), inner, at)
+}
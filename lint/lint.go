@@ -0,0 +1,344 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package lint finds the likely mistakes in ISLisp source files without
+// running them. Only the defmacro forms are evaluated, so that the macros
+// defined in the files are expanded before the forms are checked.
+package lint
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"sort"
+
+	"github.com/islisp-dev/iris/reader/parser"
+	"github.com/islisp-dev/iris/reader/tokenizer"
+	"github.com/islisp-dev/iris/runtime"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// The rules which diagnostics are reported by.
+const (
+	SyntaxError       = "syntax-error"
+	UndefinedFunction = "undefined-function"
+	UndefinedVariable = "undefined-variable"
+	ArityMismatch     = "arity-mismatch"
+	UnusedVariable    = "unused-variable"
+	SetqConstant      = "setq-constant"
+	DuplicateBinding  = "duplicate-binding"
+	UnreachableClause = "unreachable-clause"
+	MacroExpansion    = "macro-expansion"
+)
+
+// Diagnostic is a problem found at a position in a file. The line and the
+// column count from 1.
+type Diagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column"`
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	// End is the position where the form diagnosed ends, if it is known,
+	// which is not written in the JSON format.
+	End tokenizer.Position `json:"-"`
+}
+
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%v:%v:%v: %v: %v (%v)", d.File, d.Line, d.Column, d.Severity, d.Message, d.Rule)
+}
+
+// severities are the severities of the rules, which are errors unless they
+// are warnings.
+var severities = map[string]string{
+	UnusedVariable:    "warning",
+	UnreachableClause: "warning",
+	MacroExpansion:    "warning",
+}
+
+// Input is a source to be checked.
+type Input struct {
+	Name   string
+	Reader io.Reader
+}
+
+// arity is the numbers of the arguments a function takes. Max is -1 if any
+// number of arguments may follow Min.
+type arity struct {
+	min, max int
+}
+
+func (a *arity) accepts(n int) bool {
+	return a == nil || n >= a.min && (a.max < 0 || n <= a.max)
+}
+
+func (a *arity) String() string {
+	switch {
+	case a.max < 0:
+		return fmt.Sprintf("at least %v", a.min)
+	case a.min == a.max:
+		return fmt.Sprint(a.min)
+	}
+	return fmt.Sprintf("%v to %v", a.min, a.max)
+}
+
+// The kinds of the global definitions.
+const (
+	function = iota
+	macro
+	global
+	constant
+	dynamic
+)
+
+// definition is a global definition made by a form in the inputs.
+type definition struct {
+	kind int
+	// arity is the arity of a function, or nil if it is not known.
+	arity *arity
+}
+
+// linter checks the forms read from the inputs with the definitions made by
+// all of them.
+type linter struct {
+	functions   map[ilos.Instance]*definition
+	variables   map[ilos.Instance]*definition
+	diagnostics []Diagnostic
+	// code is the lists checked as code, which are collected by Code
+	// instead of evaluating and expanding the macros, or nil.
+	code []*instance.Cons
+}
+
+func newLinter() *linter {
+	return &linter{functions: map[ilos.Instance]*definition{}, variables: map[ilos.Instance]*definition{}, diagnostics: []Diagnostic{}}
+}
+
+// Check returns the diagnostics of the inputs, sorted by their positions.
+func Check(inputs ...Input) []Diagnostic {
+	saved := parser.Sources
+	parser.Sources = map[*instance.Cons]parser.Source{}
+	defer func() { parser.Sources = saved }()
+	l := newLinter()
+	forms := []ilos.Instance{}
+	for _, input := range inputs {
+		forms = append(forms, l.read(input)...)
+	}
+	for _, form := range forms {
+		l.define(form, parser.Sources[form.(*instance.Cons)])
+	}
+	for _, form := range forms {
+		l.form(form, nil, parser.Sources[form.(*instance.Cons)])
+	}
+	sort.SliceStable(l.diagnostics, func(i, j int) bool {
+		a, b := l.diagnostics[i], l.diagnostics[j]
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return l.diagnostics
+}
+
+// Code returns the lists in forms which are evaluated as code, such as the
+// calls and the special forms, leaving out the quoted data, the lambda lists
+// and so on. The macros are neither defined nor expanded, and the arguments
+// of their calls are taken as code.
+func Code(forms []ilos.Instance) []*instance.Cons {
+	l := newLinter()
+	l.code = []*instance.Cons{}
+	for _, form := range forms {
+		l.define(form, parser.Source{})
+	}
+	for _, form := range forms {
+		l.form(form, nil, parser.Source{})
+	}
+	return l.code
+}
+
+// report adds a diagnostic of rule at the position of source, unless it has
+// been reported, as it is if a macro expands to a form twice.
+func (l *linter) report(at parser.Source, rule, format string, args ...interface{}) {
+	severity := severities[rule]
+	if severity == "" {
+		severity = "error"
+	}
+	d := Diagnostic{
+		File:     at.Name,
+		Line:     at.Start.Line,
+		Column:   at.Start.Column,
+		Rule:     rule,
+		Severity: severity,
+		Message:  fmt.Sprintf(format, args...),
+		End:      at.End,
+	}
+	for _, reported := range l.diagnostics {
+		if reported == d {
+			return
+		}
+	}
+	l.diagnostics = append(l.diagnostics, d)
+}
+
+// read returns the lists read from input. The other top-level forms are not
+// checked, since they are constants or symbols, and neither is the form ended
+// by the end of input.
+func (l *linter) read(input Input) []ilos.Instance {
+	text, err := ioutil.ReadAll(input.Reader)
+	if err != nil {
+		l.report(parser.Source{Name: input.Name, Start: tokenizer.Position{Line: 1, Column: 1}}, SyntaxError, "%v", err)
+	}
+	forms, errors := parser.ParseAll(input.Name, string(text))
+	for _, e := range errors {
+		if parser.Truncated(e.Condition) {
+			l.report(e.Source, SyntaxError, "unterminated form")
+		} else {
+			l.report(e.Source, SyntaxError, "%v", message(e.Condition))
+		}
+	}
+	lists := []ilos.Instance{}
+	for _, form := range forms {
+		if _, ok := form.(*instance.Cons); ok {
+			lists = append(lists, form)
+		}
+	}
+	return lists
+}
+
+// message returns the report of condition.
+func message(condition ilos.Instance) string {
+	stream, err := runtime.CreateStringOutputStream(runtime.TopLevel)
+	if err != nil {
+		return condition.String()
+	}
+	if _, err := runtime.ReportCondition(runtime.TopLevel, condition, stream); err != nil {
+		return condition.String()
+	}
+	s, err := runtime.GetOutputStreamString(runtime.TopLevel, stream)
+	if err != nil {
+		return condition.String()
+	}
+	return string(s.(instance.String))
+}
+
+// elements returns the elements of list, ignoring the last cdr of a dotted
+// list.
+func elements(list ilos.Instance) []ilos.Instance {
+	xs := []ilos.Instance{}
+	for cons, ok := list.(*instance.Cons); ok; cons, ok = cons.Cdr.(*instance.Cons) {
+		xs = append(xs, cons.Car)
+	}
+	return xs
+}
+
+// nth returns the nth element of xs, or nil if there is not.
+func nth(xs []ilos.Instance, n int) ilos.Instance {
+	if n < len(xs) {
+		return xs[n]
+	}
+	return runtime.Nil
+}
+
+// from returns the elements of xs from the nth.
+func from(xs []ilos.Instance, n int) []ilos.Instance {
+	if n < len(xs) {
+		return xs[n:]
+	}
+	return nil
+}
+
+func symbol(name string) ilos.Instance {
+	return instance.NewSymbol(name)
+}
+
+func isSymbol(x ilos.Instance) bool {
+	_, ok := x.(instance.Symbol)
+	return ok && x != runtime.Nil
+}
+
+// isRest reports whether x introduces the rest parameter of a lambda list.
+func isRest(x ilos.Instance) bool {
+	return x == symbol("&REST") || x == symbol(":REST")
+}
+
+// parameters returns the variables of a lambda list, in which the parameters
+// of methods may be specialized as (var class), and its arity.
+func parameters(lambdaList ilos.Instance) ([]ilos.Instance, *arity) {
+	variables := []ilos.Instance{}
+	a := &arity{}
+	rest := false
+	for _, p := range elements(lambdaList) {
+		if isRest(p) {
+			rest = true
+			continue
+		}
+		if cons, ok := p.(*instance.Cons); ok {
+			p = cons.Car
+		}
+		if isSymbol(p) {
+			variables = append(variables, p)
+		}
+		if !rest {
+			a.min++
+		}
+	}
+	a.max = a.min
+	if rest {
+		a.max = -1
+	}
+	return variables, a
+}
+
+// define records the global definitions made by form, and evaluates the
+// definitions of macros so that they can be expanded.
+func (l *linter) define(form ilos.Instance, at parser.Source) {
+	xs := elements(form)
+	name := nth(xs, 1)
+	switch nth(xs, 0) {
+	case symbol("PROGN"):
+		for _, x := range from(xs, 1) {
+			l.define(x, at)
+		}
+		return
+	case symbol("DEFMACRO"):
+		if l.code == nil {
+			if _, err := runtime.Eval(runtime.TopLevel, form); err != nil {
+				l.report(at, MacroExpansion, "cannot define macro %v: %v", name, message(err))
+			}
+		}
+		l.functions[name] = &definition{kind: macro}
+	case symbol("DEFUN"):
+		_, a := parameters(nth(xs, 2))
+		l.functions[name] = &definition{kind: function, arity: a}
+	case symbol("DEFGENERIC"):
+		_, a := parameters(nth(xs, 2))
+		l.functions[name] = &definition{kind: function, arity: a}
+	case symbol("DEFMETHOD"):
+		if _, ok := l.functions[name]; !ok {
+			l.functions[name] = &definition{kind: function}
+		}
+	case symbol("DEFGLOBAL"):
+		l.variables[name] = &definition{kind: global}
+	case symbol("DEFCONSTANT"):
+		l.variables[name] = &definition{kind: constant}
+	case symbol("DEFDYNAMIC"):
+		l.variables[name] = &definition{kind: dynamic}
+	case symbol("DEFCLASS"):
+		for _, slot := range elements(nth(xs, 3)) {
+			options := elements(slot)
+			for i := 1; i+1 < len(options); i += 2 {
+				switch options[i] {
+				case symbol(":READER"), symbol(":ACCESSOR"), symbol(":BOUNDP"):
+					l.functions[options[i+1]] = &definition{kind: function, arity: &arity{1, 1}}
+				case symbol(":WRITER"):
+					l.functions[options[i+1]] = &definition{kind: function, arity: &arity{2, 2}}
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,155 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package lint
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/islisp-dev/iris/reader/parser"
+)
+
+func check(sources ...string) []string {
+	inputs := []Input{}
+	for i, source := range sources {
+		inputs = append(inputs, Input{Name: string(rune('a'+i)) + ".lsp", Reader: strings.NewReader(source)})
+	}
+	got := []string{}
+	for _, d := range Check(inputs...) {
+		got = append(got, d.String())
+	}
+	return got
+}
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{
+			name:   "clean",
+			source: "(defun f (x &rest ys) (cons x ys))\n(f 1)\n(f 1 2 3)\n(let ((x 1)) (setq x 2) x)",
+			want:   []string{},
+		},
+		{
+			name:   "syntax error",
+			source: "(car nil)\n(car\n  ; comment\n",
+			want:   []string{"a.lsp:2:1: error: unterminated form (syntax-error)"},
+		},
+		{
+			name:   "unexpected parenthesis",
+			source: "(car nil))\n(cdr)",
+			want: []string{
+				"a.lsp:1:10: error: Cannot parse \")\" as <OBJECT>. (syntax-error)",
+				"a.lsp:2:1: error: CDR takes 1 arguments but is given 0 (arity-mismatch)",
+			},
+		},
+		{
+			name:   "trailing comment",
+			source: "(car nil)\n; comment\n",
+			want:   []string{},
+		},
+		{
+			name:   "undefined function",
+			source: "(defun f ()\n  (g 1))\n(f)",
+			want:   []string{"a.lsp:2:3: error: undefined function G (undefined-function)"},
+		},
+		{
+			name:   "undefined variable",
+			source: "(defglobal x 1)\n(defun f (y) (list x y z))",
+			want:   []string{"a.lsp:2:14: error: undefined variable Z (undefined-variable)"},
+		},
+		{
+			name:   "arity mismatch",
+			source: "(defun f (a b) (+ a b))\n(f 1)\n(car 1 2)\n(list)\n(flet ((g (x) x)) (g))",
+			want: []string{
+				"a.lsp:2:1: error: F takes 2 arguments but is given 1 (arity-mismatch)",
+				"a.lsp:3:1: error: CAR takes 1 arguments but is given 2 (arity-mismatch)",
+				"a.lsp:5:19: error: G takes 1 arguments but is given 0 (arity-mismatch)",
+			},
+		},
+		{
+			name:   "unused variable",
+			source: "(let ((x 1)\n      (y 2))\n  y)\n(for ((i 0 (+ i 1)) (j 0)) ((= i 3)) i)",
+			want: []string{
+				"a.lsp:1:7: warning: variable X is not used (unused-variable)",
+				"a.lsp:4:21: warning: variable J is not used (unused-variable)",
+			},
+		},
+		{
+			name:   "setq constant",
+			source: "(defconstant limit 10)\n(defun f ()\n  (setq limit 11))",
+			want:   []string{"a.lsp:3:3: error: assignment to constant LIMIT (setq-constant)"},
+		},
+		{
+			name:   "duplicate binding",
+			source: "(labels ((f () 1)\n         (f () 2))\n  (f))",
+			want:   []string{"a.lsp:2:10: error: function F is bound twice by LABELS (duplicate-binding)"},
+		},
+		{
+			name:   "unreachable clause",
+			source: "(defun f (x)\n  (cond ((= x 1) 'one)\n        (t 'other)\n        ((= x 2) 'two)))",
+			want:   []string{"a.lsp:4:9: warning: clause is never reached since a preceding test is always true (unreachable-clause)"},
+		},
+		{
+			name:   "macro",
+			source: "(defmacro twice (x) `(progn ,x ,x))\n(defun f ()\n  (twice (g)))\n(twice)",
+			want: []string{
+				"a.lsp:3:10: error: undefined function G (undefined-function)",
+				"a.lsp:4:1: warning: cannot expand macro TWICE: TWICE expects 1 arguments but was given 0. (macro-expansion)",
+			},
+		},
+		{
+			name:   "methods",
+			source: "(defgeneric f (x))\n(defmethod f :around ((x <integer>)) (if (next-method-p) (call-next-method) x))\n(f 1)",
+			want:   []string{},
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := check(test.source); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("Check() = %q, want %q", got, test.want)
+			}
+		})
+	}
+}
+
+func TestCheck_Files(t *testing.T) {
+	got := check("(defun f (x) (g x))", "(defun g (x) x)\n(f 1 2)")
+	want := []string{"b.lsp:2:1: error: F takes 1 arguments but is given 2 (arity-mismatch)"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Check() = %q, want %q", got, want)
+	}
+}
+
+func TestDiagnostic_JSON(t *testing.T) {
+	diagnostics := Check(Input{Name: "a.lsp", Reader: strings.NewReader("(f)")})
+	data, err := json.Marshal(diagnostics)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `[{"file":"a.lsp","line":1,"column":1,"rule":"undefined-function","severity":"error","message":"undefined function F"}]`
+	if string(data) != want {
+		t.Errorf("json.Marshal() = %s, want %s", data, want)
+	}
+}
+
+func TestCode(t *testing.T) {
+	forms, _ := parser.ParseAll("a.lsp", "(defun f (x) (list x '(car x)))\n(f (cdr '(1 2)))")
+	got := []string{}
+	for _, form := range Code(forms) {
+		got = append(got, form.String())
+	}
+	want := []string{
+		"(DEFUN F (X) (LIST X (QUOTE (CAR X))))", "(LIST X (QUOTE (CAR X)))", "(QUOTE (CAR X))",
+		"(F (CDR (QUOTE (1 2))))", "(CDR (QUOTE (1 2)))", "(QUOTE (1 2))",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Code() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,94 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/islisp-dev/iris/lint"
+)
+
+// findLispFiles returns the files named *.lsp in the directories in paths and
+// the other paths, sorted and without duplicates.
+func findLispFiles(paths []string) ([]string, error) {
+	files := []string{}
+	found := map[string]bool{}
+	for _, path := range paths {
+		err := filepath.Walk(path, func(name string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			name = filepath.Clean(name)
+			if info.IsDir() || found[name] {
+				return nil
+			}
+			if name == filepath.Clean(path) || strings.HasSuffix(info.Name(), ".lsp") {
+				found[name] = true
+				files = append(files, name)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// lintFiles checks the files in paths together, so that the functions defined
+// in a file may be called in another, and writes the diagnostics to w.
+func lintFiles(w io.Writer, args []string) int {
+	flags := flag.NewFlagSet("lint", flag.ExitOnError)
+	format := flags.String("format", "text", "write the diagnostics in the text or json format")
+	flags.Parse(args)
+	if *format != "text" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown lint format: %v\n", *format)
+		return 2
+	}
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	names, err := findLispFiles(paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	inputs := []lint.Input{}
+	for _, name := range names {
+		// Each file is closed as soon as it is read, so that checking many
+		// files does not run out of file descriptors.
+		text, err := ioutil.ReadFile(name)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 2
+		}
+		inputs = append(inputs, lint.Input{Name: name, Reader: bytes.NewReader(text)})
+	}
+	diagnostics := lint.Check(inputs...)
+	if *format == "json" {
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		encoder.Encode(diagnostics)
+	} else {
+		for _, d := range diagnostics {
+			fmt.Fprintln(w, d)
+		}
+	}
+	if len(diagnostics) > 0 {
+		return 1
+	}
+	return 0
+}
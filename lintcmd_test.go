@@ -0,0 +1,92 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/islisp-dev/iris/lint"
+)
+
+func TestFindLispFiles(t *testing.T) {
+	files, err := findLispFiles([]string{"testdata/lint", "testdata/lint/notes.txt", "testdata/lint/square.lsp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// A file named is checked whatever its name is.
+	want := []string{
+		filepath.Join("testdata", "lint", "notes.txt"),
+		filepath.Join("testdata", "lint", "square.lsp"),
+		filepath.Join("testdata", "lint", "sub", "area.lsp"),
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("findLispFiles() = %q, want %q", files, want)
+	}
+}
+
+func TestLintFiles(t *testing.T) {
+	area := filepath.Join("testdata", "lint", "sub", "area.lsp")
+	tests := []struct {
+		args   []string
+		status int
+		output string
+	}{
+		{
+			args:   []string{"testdata/lint"},
+			status: 1,
+			output: area + ":2:33: error: undefined function CUBE (undefined-function)\n",
+		},
+		{
+			args:   []string{"testdata/lint/square.lsp"},
+			status: 0,
+			output: "",
+		},
+		{
+			args:   []string{"-format", "xml", "testdata/lint"},
+			status: 2,
+			output: "",
+		},
+		{
+			args:   []string{"testdata/no-such-file.lsp"},
+			status: 2,
+			output: "",
+		},
+	}
+	for _, tt := range tests {
+		var output bytes.Buffer
+		if status := lintFiles(&output, tt.args); status != tt.status {
+			t.Errorf("lintFiles(%q) = %v, want %v", tt.args, status, tt.status)
+		}
+		if output.String() != tt.output {
+			t.Errorf("lintFiles(%q) wrote %q, want %q", tt.args, output.String(), tt.output)
+		}
+	}
+}
+
+func TestLintFilesJSON(t *testing.T) {
+	var output bytes.Buffer
+	if status := lintFiles(&output, []string{"-format", "json", "testdata/lint"}); status != 1 {
+		t.Errorf("status = %v, want 1", status)
+	}
+	var diagnostics []lint.Diagnostic
+	if err := json.Unmarshal(output.Bytes(), &diagnostics); err != nil {
+		t.Fatal(err)
+	}
+	want := []lint.Diagnostic{{
+		File:     filepath.Join("testdata", "lint", "sub", "area.lsp"),
+		Line:     2,
+		Column:   33,
+		Rule:     lint.UndefinedFunction,
+		Severity: "error",
+		Message:  "undefined function CUBE",
+	}}
+	if !reflect.DeepEqual(diagnostics, want) {
+		t.Errorf("diagnostics = %+v, want %+v", diagnostics, want)
+	}
+}
@@ -0,0 +1,419 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package lsp
+
+import (
+	"sort"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/islisp-dev/iris/lint"
+	"github.com/islisp-dev/iris/reader/parser"
+	"github.com/islisp-dev/iris/reader/tokenizer"
+	"github.com/islisp-dev/iris/runtime"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// The namespaces of the definitions.
+const (
+	functionNamespace = "function"
+	variableNamespace = "variable"
+	classNamespace    = "class"
+)
+
+// span is the range of a form in the text of a document, in offsets.
+type span struct {
+	start, end int
+}
+
+// definition is a global definition made by a top-level form.
+type definition struct {
+	name      string
+	namespace string
+	// kind describes the definition, such as "function" or "macro".
+	kind string
+	// at is the span of the name in the defining form.
+	at span
+	// signature is the defining form without its body, such as
+	// (defun f (x y)), as written in the document.
+	signature string
+	doc       string
+}
+
+// document is an open text document and the result of analyzing it.
+type document struct {
+	uri     string
+	version int
+	text    []rune
+	// lines are the offsets of the beginnings of the lines in text.
+	lines []int
+	// forms are the top-level forms read from text, and sources are where
+	// the lists in them are read from.
+	forms       []ilos.Instance
+	sources     map[*instance.Cons]parser.Source
+	definitions []*definition
+}
+
+func newDocument(uri string, version int, text string) *document {
+	d := &document{uri: uri, version: version, text: []rune(text), lines: []int{0}, sources: map[*instance.Cons]parser.Source{}}
+	for i, r := range d.text {
+		if r == '\n' {
+			d.lines = append(d.lines, i+1)
+		}
+	}
+	saved := parser.Sources
+	parser.Sources = d.sources
+	forms, errors := parser.ParseAll(uri, text)
+	parser.Sources = saved
+	d.forms = forms
+	// The form being written at the end of the document is closed, so that
+	// its definition can be used.
+	for _, e := range errors {
+		if e.Form != nil {
+			d.forms = append(d.forms, e.Form)
+		}
+	}
+	for _, form := range d.forms {
+		d.define(form)
+	}
+	return d
+}
+
+// source returns the text of s.
+func (d *document) source(s span) string {
+	return string(d.text[s.start:s.end])
+}
+
+// spanOf returns the span of list, or false if it is not read from d. A list
+// closed by the parser ends at the end of the text.
+func (d *document) spanOf(list ilos.Instance) (span, bool) {
+	cons, ok := list.(*instance.Cons)
+	if !ok {
+		return span{}, false
+	}
+	source, ok := d.sources[cons]
+	if !ok {
+		return span{}, false
+	}
+	s := span{source.Start.Offset, source.End.Offset}
+	if s.end > len(d.text) {
+		s.end = len(d.text)
+	}
+	return s, true
+}
+
+// spans returns the spans of the elements of list, which are read again from
+// the text of list, or nil if list is not read from d.
+func (d *document) spans(list ilos.Instance) []span {
+	s, ok := d.spanOf(list)
+	if !ok {
+		return nil
+	}
+	text := d.source(s)
+	r := tokenizer.NewReader(strings.NewReader(text))
+	r.Next()
+	spans := []span{}
+	for range elements(list) {
+		before := r.Position()
+		if _, err := parser.Parse(r); err != nil {
+			break
+		}
+		start := tokenizer.TokenAfter(text, before)
+		spans = append(spans, span{s.start + start.Offset, s.start + r.Position().Offset})
+	}
+	return spans
+}
+
+// positionAt returns the position of the offset in text.
+func (d *document) positionAt(offset int) Position {
+	line := sort.SearchInts(d.lines, offset+1) - 1
+	return Position{Line: line, Character: len(utf16.Encode(d.text[d.lines[line]:offset]))}
+}
+
+func (d *document) rangeOf(s span) Range {
+	return Range{Start: d.positionAt(s.start), End: d.positionAt(s.end)}
+}
+
+// offset returns the offset in text of p, which is clamped to the text.
+func (d *document) offset(p Position) int {
+	if p.Line < 0 {
+		return 0
+	}
+	if p.Line >= len(d.lines) {
+		return len(d.text)
+	}
+	offset := d.lines[p.Line]
+	for units := 0; offset < len(d.text) && d.text[offset] != '\n'; offset++ {
+		units += len(utf16.Encode([]rune{d.text[offset]}))
+		if units > p.Character {
+			break
+		}
+	}
+	return offset
+}
+
+// isDelimiter reports whether r cannot be a part of a symbol.
+func isDelimiter(r rune) bool {
+	return strings.ContainsRune(" \t\r\n()'`,\";", r)
+}
+
+// wordAt returns the word around p and its range in offsets, which is used
+// to find the symbol under the cursor and the prefix to be completed.
+func (d *document) wordAt(p Position) (string, int, int) {
+	start := d.offset(p)
+	end := start
+	for start > 0 && !isDelimiter(d.text[start-1]) {
+		start--
+	}
+	for end < len(d.text) && !isDelimiter(d.text[end]) {
+		end++
+	}
+	word := string(d.text[start:end])
+	if strings.HasPrefix(word, "#'") {
+		return word[2:], start + 2, end
+	}
+	return word, start, end
+}
+
+// symbolName returns the name of the symbol written as word, or an empty
+// string if word is not a symbol.
+func symbolName(word string) string {
+	atom, err := parser.ParseAtom(word)
+	if err != nil {
+		return ""
+	}
+	if s, ok := atom.(instance.Symbol); ok {
+		return string(s)
+	}
+	return ""
+}
+
+// elements returns the elements of list, ignoring the last cdr of a dotted
+// list.
+func elements(list ilos.Instance) []ilos.Instance {
+	xs := []ilos.Instance{}
+	for cons, ok := list.(*instance.Cons); ok; cons, ok = cons.Cdr.(*instance.Cons) {
+		xs = append(xs, cons.Car)
+	}
+	return xs
+}
+
+// nameOf returns the name of the symbol x, or an empty string if x is not a
+// symbol.
+func nameOf(x ilos.Instance) string {
+	if s, ok := x.(instance.Symbol); ok {
+		return string(s)
+	}
+	return ""
+}
+
+// head returns the name of the symbol at the head of a list, or an empty
+// string.
+func head(form ilos.Instance) string {
+	if cons, ok := form.(*instance.Cons); ok {
+		return nameOf(cons.Car)
+	}
+	return ""
+}
+
+// docstring returns the string x, or an empty string.
+func docstring(x ilos.Instance) string {
+	if s, ok := x.(instance.String); ok {
+		return string(s)
+	}
+	return ""
+}
+
+// option returns the value of the option named name, such as
+// (:documentation "..."), in xs, or nil.
+func option(xs []ilos.Instance, name string) ilos.Instance {
+	for _, x := range xs {
+		if head(x) == name {
+			if ys := elements(x); len(ys) > 1 {
+				return ys[1]
+			}
+		}
+	}
+	return nil
+}
+
+// define records the definitions made by form.
+func (d *document) define(form ilos.Instance) {
+	xs := elements(form)
+	spans := d.spans(form)
+	if len(spans) < 2 || nameOf(xs[1]) == "" {
+		return
+	}
+	whole, _ := d.spanOf(form)
+	add := func(namespace, kind, name string, at span, end int, doc string) {
+		signature := d.source(whole)
+		if end < len(xs) && end <= len(spans) {
+			signature = string(d.text[whole.start:spans[end-1].end]) + ")"
+		}
+		d.definitions = append(d.definitions, &definition{
+			name: name, namespace: namespace, kind: kind,
+			at: at, signature: signature, doc: doc,
+		})
+	}
+	name := nameOf(xs[1])
+	switch head(form) {
+	case "DEFUN":
+		doc := ""
+		if len(xs) > 4 {
+			doc = docstring(xs[3])
+		}
+		add(functionNamespace, "function", name, spans[1], 3, doc)
+	case "DEFMACRO":
+		doc := ""
+		if len(xs) > 4 {
+			doc = docstring(xs[3])
+		}
+		add(functionNamespace, "macro", name, spans[1], 3, doc)
+	case "DEFGENERIC":
+		doc := ""
+		if len(xs) > 3 {
+			doc = docstring(option(xs[3:], ":DOCUMENTATION"))
+		}
+		add(functionNamespace, "generic function", name, spans[1], 3, doc)
+	case "DEFGLOBAL":
+		add(variableNamespace, "global variable", name, spans[1], 3, docstring(nth(xs, 3)))
+	case "DEFCONSTANT":
+		add(variableNamespace, "constant", name, spans[1], 3, docstring(nth(xs, 3)))
+	case "DEFDYNAMIC":
+		add(variableNamespace, "dynamic variable", name, spans[1], 3, docstring(nth(xs, 3)))
+	case "DEFCLASS":
+		doc := ""
+		if len(xs) > 4 {
+			doc = docstring(option(xs[4:], ":DOCUMENTATION"))
+		}
+		add(classNamespace, "class", name, spans[1], 3, doc)
+		for _, slot := range elements(nth(xs, 3)) {
+			options, at := elements(slot), d.spans(slot)
+			for i := 1; i+1 < len(options) && i+1 < len(at); i += 2 {
+				switch nameOf(options[i]) {
+				case ":READER", ":WRITER", ":ACCESSOR", ":BOUNDP":
+					if accessor := nameOf(options[i+1]); accessor != "" {
+						add(functionNamespace, "slot accessor of "+name, accessor, at[i+1], 3, "")
+					}
+				}
+			}
+		}
+	}
+}
+
+// nth returns the nth element of xs, or nil if there is not.
+func nth(xs []ilos.Instance, n int) ilos.Instance {
+	if n < len(xs) {
+		return xs[n]
+	}
+	return nil
+}
+
+// symbolKind returns the kind of the document symbol of form, or 0 if form
+// defines nothing.
+func symbolKind(form ilos.Instance) int {
+	switch head(form) {
+	case "DEFUN", "DEFMACRO", "DEFGENERIC":
+		return SymbolFunction
+	case "DEFMETHOD":
+		return SymbolMethod
+	case "DEFCLASS":
+		return SymbolClass
+	case "DEFGLOBAL", "DEFDYNAMIC":
+		return SymbolVariable
+	case "DEFCONSTANT":
+		return SymbolConstant
+	}
+	return 0
+}
+
+// symbols returns the top-level definitions in d.
+func (d *document) symbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	for _, form := range d.forms {
+		kind := symbolKind(form)
+		xs, spans := elements(form), d.spans(form)
+		if kind == 0 || len(spans) < 2 || nameOf(xs[1]) == "" {
+			continue
+		}
+		whole, _ := d.spanOf(form)
+		symbol := DocumentSymbol{
+			Name:           d.source(spans[1]),
+			Kind:           kind,
+			Range:          d.rangeOf(whole),
+			SelectionRange: d.rangeOf(spans[1]),
+		}
+		if kind == SymbolMethod {
+			// The qualifiers precede the lambda list.
+			details := []string{}
+			for i := 2; i < len(spans); i++ {
+				details = append(details, d.source(spans[i]))
+				if nameOf(xs[i]) == "" {
+					break
+				}
+			}
+			symbol.Detail = strings.Join(details, " ")
+		}
+		symbols = append(symbols, symbol)
+	}
+	return symbols
+}
+
+// builtin returns the kind of the built-in definition of name in namespace, or
+// an empty string.
+func builtin(namespace, name string) string {
+	symbol := instance.NewSymbol(name)
+	has := func(m map[ilos.Instance]ilos.Instance) bool {
+		_, ok := m[symbol]
+		return ok
+	}
+	switch namespace {
+	case functionNamespace:
+		switch {
+		case has(runtime.TopLevel.Special[0]):
+			return "special operator"
+		case has(runtime.TopLevel.Macro[0]):
+			return "macro"
+		case has(runtime.TopLevel.Function[0]):
+			return "function"
+		}
+	case variableNamespace:
+		if has(runtime.TopLevel.Constant[0]) {
+			return "constant"
+		}
+		if has(runtime.TopLevel.Variable[0]) {
+			return "global variable"
+		}
+	case classNamespace:
+		if has(runtime.TopLevel.Class[0]) {
+			return "class"
+		}
+	}
+	return ""
+}
+
+// diagnostic returns the diagnostic found by lint in the coordinates of LSP,
+// which begins at its line and column and ends where the form diagnosed ends.
+func (d *document) diagnostic(found lint.Diagnostic) Diagnostic {
+	start := len(d.text)
+	if found.Line >= 1 && found.Line <= len(d.lines) {
+		start = d.lines[found.Line-1] + found.Column - 1
+	}
+	end := found.End.Offset
+	if end < start {
+		end = start
+	}
+	severity := SeverityError
+	if found.Severity == "warning" {
+		severity = SeverityWarning
+	}
+	return Diagnostic{
+		Range:    Range{Start: d.positionAt(start), End: d.positionAt(end)},
+		Severity: severity,
+		Source:   "iris",
+		Message:  found.Message,
+	}
+}
@@ -0,0 +1,347 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package lsp implements a language server of ISLisp, which speaks the
+// Language Server Protocol. It analyzes the open documents without
+// evaluating them, except for the macro definitions, which lint evaluates to
+// expand the macros.
+package lsp
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/islisp-dev/iris/lint"
+	"github.com/islisp-dev/iris/runtime"
+	"github.com/islisp-dev/iris/runtime/ilos"
+)
+
+// Server serves a client over a stream. It handles the messages one by one
+// in the order of their arrival.
+type Server struct {
+	conn        *conn
+	documents   map[string]*document
+	initialized bool
+	shutdown    bool
+}
+
+// NewServer returns a server which reads the messages of the client from in
+// and writes the messages to the client to out.
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{conn: newConn(in, out), documents: map[string]*document{}}
+}
+
+// Run serves the client until the exit notification or the end of the input.
+// It returns the exit status, which is 0 if the client shut the server down
+// before exit.
+func (s *Server) Run() int {
+	for {
+		m, err := s.conn.read()
+		if err == io.EOF {
+			return 1
+		}
+		if _, ok := err.(*json.SyntaxError); ok {
+			s.respond(nil, nil, &responseError{Code: parseError, Message: err.Error()})
+			continue
+		}
+		if err != nil {
+			return 1
+		}
+		if m.Method == "exit" {
+			if s.shutdown {
+				return 0
+			}
+			return 1
+		}
+		result, failure := s.handle(m)
+		if m.ID != nil {
+			s.respond(m.ID, result, failure)
+		}
+	}
+}
+
+// respond sends the response to the request of id.
+func (s *Server) respond(id *json.RawMessage, result interface{}, failure *responseError) {
+	if id == nil {
+		null := json.RawMessage("null")
+		id = &null
+	}
+	m := &message{ID: id, Error: failure}
+	if failure == nil {
+		data, err := json.Marshal(result)
+		if err != nil {
+			m.Error = &responseError{Code: invalidRequest, Message: err.Error()}
+		} else {
+			m.Result = data
+		}
+	}
+	s.conn.write(m)
+}
+
+// notify sends a notification to the client.
+func (s *Server) notify(method string, params interface{}) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		return
+	}
+	s.conn.write(&message{Method: method, Params: data})
+}
+
+// handle returns the result of the request or the notification m.
+func (s *Server) handle(m *message) (interface{}, *responseError) {
+	if !s.initialized && m.Method != "initialize" {
+		if m.ID == nil {
+			return nil, nil
+		}
+		return nil, &responseError{Code: serverNotInitialized, Message: "the server is not initialized"}
+	}
+	switch m.Method {
+	case "initialize":
+		s.initialized = true
+		return InitializeResult{
+			Capabilities: ServerCapabilities{
+				TextDocumentSync:       1,
+				HoverProvider:          true,
+				DefinitionProvider:     true,
+				DocumentSymbolProvider: true,
+				CompletionProvider:     CompletionOptions{TriggerCharacters: []string{"(", "<", "*"}},
+			},
+			ServerInfo: ServerInfo{Name: "iris"},
+		}, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if failure := unmarshal(m, &params); failure != nil {
+			return nil, failure
+		}
+		item := params.TextDocument
+		s.documents[item.URI] = newDocument(item.URI, item.Version, item.Text)
+		s.publishDiagnostics()
+		return nil, nil
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if failure := unmarshal(m, &params); failure != nil {
+			return nil, failure
+		}
+		if n := len(params.ContentChanges); n > 0 {
+			item := params.TextDocument
+			s.documents[item.URI] = newDocument(item.URI, item.Version, params.ContentChanges[n-1].Text)
+			s.publishDiagnostics()
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if failure := unmarshal(m, &params); failure != nil {
+			return nil, failure
+		}
+		uri := params.TextDocument.URI
+		delete(s.documents, uri)
+		s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: uri, Diagnostics: []Diagnostic{}})
+		s.publishDiagnostics()
+		return nil, nil
+	case "textDocument/hover":
+		return s.positional(m, s.hover)
+	case "textDocument/definition":
+		return s.positional(m, s.definition)
+	case "textDocument/completion":
+		return s.positional(m, s.completion)
+	case "textDocument/documentSymbol":
+		var params DocumentSymbolParams
+		if failure := unmarshal(m, &params); failure != nil {
+			return nil, failure
+		}
+		d, ok := s.documents[params.TextDocument.URI]
+		if !ok {
+			return []DocumentSymbol{}, nil
+		}
+		return d.symbols(), nil
+	}
+	if m.ID == nil {
+		// The notifications which are not known, such as initialized, are
+		// ignored.
+		return nil, nil
+	}
+	return nil, &responseError{Code: methodNotFound, Message: fmt.Sprintf("method not found: %v", m.Method)}
+}
+
+func unmarshal(m *message, params interface{}) *responseError {
+	if err := json.Unmarshal(m.Params, params); err != nil {
+		return &responseError{Code: invalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+// positional unmarshals the parameters of a request at a position in a
+// document, and returns the result of f for them.
+func (s *Server) positional(m *message, f func(d *document, p Position) interface{}) (interface{}, *responseError) {
+	var params TextDocumentPositionParams
+	if failure := unmarshal(m, &params); failure != nil {
+		return nil, failure
+	}
+	d, ok := s.documents[params.TextDocument.URI]
+	if !ok {
+		return nil, &responseError{Code: invalidParams, Message: fmt.Sprintf("unknown document: %v", params.TextDocument.URI)}
+	}
+	return f(d, params.Position), nil
+}
+
+// uris returns the URIs of the open documents, sorted.
+func (s *Server) uris() []string {
+	uris := []string{}
+	for uri := range s.documents {
+		uris = append(uris, uri)
+	}
+	sort.Strings(uris)
+	return uris
+}
+
+// lookup returns the definitions of name in the open documents, those in d
+// first.
+func (s *Server) lookup(d *document, name string) []*definition {
+	definitions := []*definition{}
+	for _, uri := range append([]string{d.uri}, s.uris()...) {
+		if uri == d.uri && len(definitions) > 0 {
+			continue
+		}
+		for _, def := range s.documents[uri].definitions {
+			if def.name == name {
+				definitions = append(definitions, def)
+			}
+		}
+	}
+	return definitions
+}
+
+// publishDiagnostics sends the diagnostics of all the open documents, since
+// a change of a document may define or undefine the names used by others.
+// The documents are checked by lint together.
+func (s *Server) publishDiagnostics() {
+	inputs := []lint.Input{}
+	found := map[string][]Diagnostic{}
+	for _, uri := range s.uris() {
+		inputs = append(inputs, lint.Input{Name: uri, Reader: strings.NewReader(string(s.documents[uri].text))})
+		found[uri] = []Diagnostic{}
+	}
+	for _, d := range lint.Check(inputs...) {
+		found[d.File] = append(found[d.File], s.documents[d.File].diagnostic(d))
+	}
+	for _, uri := range s.uris() {
+		d := s.documents[uri]
+		s.notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{
+			URI:         uri,
+			Version:     d.version,
+			Diagnostics: found[uri],
+		})
+	}
+}
+
+// documentOf returns the document defining def.
+func (s *Server) documentOf(def *definition) *document {
+	for _, d := range s.documents {
+		for _, x := range d.definitions {
+			if x == def {
+				return d
+			}
+		}
+	}
+	return nil
+}
+
+// hover returns the signatures and the documentation of the definitions of
+// the symbol at p, or the kinds of its built-in definitions.
+func (s *Server) hover(d *document, p Position) interface{} {
+	word, start, end := d.wordAt(p)
+	name := symbolName(word)
+	if name == "" {
+		return nil
+	}
+	sections := []string{}
+	for _, def := range s.lookup(d, name) {
+		section := "```islisp\n" + def.signature + "\n```"
+		if def.doc != "" {
+			section += "\n\n" + def.doc
+		}
+		sections = append(sections, section)
+	}
+	if len(sections) == 0 {
+		for _, namespace := range []string{functionNamespace, variableNamespace, classNamespace} {
+			if kind := builtin(namespace, name); kind != "" {
+				sections = append(sections, fmt.Sprintf("`%v` is a built-in %v.", strings.ToLower(name), kind))
+			}
+		}
+	}
+	if len(sections) == 0 {
+		return nil
+	}
+	r := Range{Start: d.positionAt(start), End: d.positionAt(end)}
+	return Hover{Contents: MarkupContent{Kind: "markdown", Value: strings.Join(sections, "\n\n---\n\n")}, Range: &r}
+}
+
+// definition returns the locations of the definitions of the symbol at p.
+func (s *Server) definition(d *document, p Position) interface{} {
+	word, _, _ := d.wordAt(p)
+	locations := []Location{}
+	if name := symbolName(word); name != "" {
+		for _, def := range s.lookup(d, name) {
+			doc := s.documentOf(def)
+			locations = append(locations, Location{URI: doc.uri, Range: doc.rangeOf(def.at)})
+		}
+	}
+	return locations
+}
+
+// completion returns the names in all the namespaces which start with the
+// word before p. The names are in lower case unless the word has an upper
+// case letter.
+func (s *Server) completion(d *document, p Position) interface{} {
+	_, start, _ := d.wordAt(p)
+	word := string(d.text[start:d.offset(p)])
+	if strings.HasPrefix(word, "#'") {
+		word = word[2:]
+	}
+	lower := strings.ToLower(word) == word
+	prefix := strings.ToUpper(word)
+	items := []CompletionItem{}
+	seen := map[string]bool{}
+	add := func(name string, kind int, detail string) {
+		if !strings.HasPrefix(name, prefix) || seen[name] {
+			return
+		}
+		seen[name] = true
+		label := name
+		if lower {
+			label = strings.ToLower(name)
+		}
+		items = append(items, CompletionItem{Label: label, Kind: kind, Detail: detail})
+	}
+	kinds := map[string]int{functionNamespace: CompletionFunction, variableNamespace: CompletionVariable, classNamespace: CompletionClass}
+	for _, uri := range s.uris() {
+		for _, def := range s.documents[uri].definitions {
+			add(def.name, kinds[def.namespace], def.kind)
+		}
+	}
+	for _, namespace := range []struct {
+		m      map[ilos.Instance]ilos.Instance
+		kind   int
+		detail string
+	}{
+		{runtime.TopLevel.Special[0], CompletionKeyword, "special operator"},
+		{runtime.TopLevel.Macro[0], CompletionKeyword, "macro"},
+		{runtime.TopLevel.Function[0], CompletionFunction, "function"},
+		{runtime.TopLevel.Constant[0], CompletionVariable, "constant"},
+		{runtime.TopLevel.Variable[0], CompletionVariable, "global variable"},
+		{runtime.TopLevel.Class[0], CompletionClass, "class"},
+	} {
+		for symbol := range namespace.m {
+			add(symbol.String(), namespace.kind, namespace.detail)
+		}
+	}
+	sort.Slice(items, func(i, j int) bool { return items[i].Label < items[j].Label })
+	return CompletionList{Items: items}
+}
@@ -0,0 +1,307 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package lsp
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/islisp-dev/iris/lint"
+)
+
+// client talks to a server running in another goroutine.
+type client struct {
+	t      *testing.T
+	conn   *conn
+	in     io.Closer
+	id     int
+	status chan int
+	// received are the messages from the server, which are read in another
+	// goroutine so that the server never blocks on writing.
+	received chan *message
+	// notifications are the notifications received before the responses.
+	notifications []*message
+}
+
+func newClient(t *testing.T) *client {
+	serverIn, clientOut := io.Pipe()
+	clientIn, serverOut := io.Pipe()
+	c := &client{t: t, conn: newConn(clientIn, clientOut), in: clientOut, status: make(chan int, 1), received: make(chan *message, 64)}
+	go func() {
+		c.status <- NewServer(serverIn, serverOut).Run()
+		serverOut.Close()
+	}()
+	go func() {
+		defer close(c.received)
+		for {
+			m, err := c.conn.read()
+			if err != nil {
+				return
+			}
+			c.received <- m
+		}
+	}()
+	return c
+}
+
+func (c *client) send(id *json.RawMessage, method string, params interface{}) {
+	data, err := json.Marshal(params)
+	if err != nil {
+		c.t.Fatal(err)
+	}
+	if err := c.conn.write(&message{ID: id, Method: method, Params: data}); err != nil {
+		c.t.Fatal(err)
+	}
+}
+
+// call sends a request and returns the response to it.
+func (c *client) call(method string, params interface{}) *message {
+	c.id++
+	id := json.RawMessage(strconv.Itoa(c.id))
+	c.send(&id, method, params)
+	for m := range c.received {
+		if m.ID == nil {
+			c.notifications = append(c.notifications, m)
+			continue
+		}
+		if string(*m.ID) != string(id) {
+			c.t.Fatalf("%v: got the response to %s", method, *m.ID)
+		}
+		return m
+	}
+	c.t.Fatalf("%v: the server exited", method)
+	return nil
+}
+
+// result calls the method and unmarshals its result into v.
+func (c *client) result(method string, params, v interface{}) {
+	m := c.call(method, params)
+	if m.Error != nil {
+		c.t.Fatalf("%v: %v", method, m.Error.Message)
+	}
+	if err := json.Unmarshal(m.Result, v); err != nil {
+		c.t.Fatalf("%v: %v", method, err)
+	}
+}
+
+// notify sends a notification, and synchronizes with the server by a request
+// so that the notifications sent by the server in reply are received.
+func (c *client) notify(method string, params interface{}) {
+	c.notifications = nil
+	c.send(nil, method, params)
+	c.call("$/synchronize", nil)
+}
+
+// diagnostics returns the last diagnostics published for uri after the last
+// notification sent.
+func (c *client) diagnostics(uri string) []Diagnostic {
+	var diagnostics []Diagnostic
+	for _, m := range c.notifications {
+		var params PublishDiagnosticsParams
+		if m.Method != "textDocument/publishDiagnostics" {
+			continue
+		}
+		if err := json.Unmarshal(m.Params, &params); err != nil {
+			c.t.Fatal(err)
+		}
+		if params.URI == uri {
+			diagnostics = params.Diagnostics
+		}
+	}
+	return diagnostics
+}
+
+func (c *client) open(uri, text string) {
+	c.notify("textDocument/didOpen", DidOpenTextDocumentParams{
+		TextDocument: TextDocumentItem{URI: uri, Version: 1, Text: text},
+	})
+}
+
+func (c *client) exit() int {
+	c.send(nil, "exit", nil)
+	c.in.Close()
+	return <-c.status
+}
+
+func at(uri string, line, character int) TextDocumentPositionParams {
+	return TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     Position{Line: line, Character: character},
+	}
+}
+
+func messages(diagnostics []Diagnostic) []string {
+	messages := []string{}
+	for _, d := range diagnostics {
+		messages = append(messages, d.Message)
+	}
+	return messages
+}
+
+const mainLisp = `(defun square (x)
+  "Returns the square of X."
+  (* x x))
+(defglobal *size* 3 "The size.")
+(defclass <point> () ((x :accessor point-x)))
+(defgeneric area (shape) (:documentation "Returns the area."))
+(defmethod area ((p <point>)) (square (point-x p)))
+(let ((y 1)) (cube (+ y *size* z)))
+`
+
+func TestServer(t *testing.T) {
+	c := newClient(t)
+	if m := c.call("textDocument/hover", at("file:///main.lsp", 0, 0)); m.Error == nil || m.Error.Code != serverNotInitialized {
+		t.Errorf("hover before initialize = %+v, want the error %v", m.Error, serverNotInitialized)
+	}
+	var initialized InitializeResult
+	c.result("initialize", map[string]interface{}{}, &initialized)
+	if !initialized.Capabilities.HoverProvider || initialized.Capabilities.TextDocumentSync != 1 {
+		t.Errorf("capabilities = %+v", initialized.Capabilities)
+	}
+	c.notify("initialized", map[string]interface{}{})
+
+	c.open("file:///main.lsp", mainLisp)
+	undefined := []string{"undefined function CUBE", "undefined variable Z"}
+	if got, want := messages(c.diagnostics("file:///main.lsp")), undefined; !reflect.DeepEqual(got, want) {
+		t.Errorf("diagnostics = %v, want %v", got, want)
+	}
+
+	// Defining cube in another document removes the diagnostic in main.lsp.
+	c.open("file:///cube.lsp", "(defun cube (x) (* x (square x)))\n(defun broken (x) (list x")
+	if got, want := messages(c.diagnostics("file:///main.lsp")), []string{"undefined variable Z"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("diagnostics = %v, want %v", got, want)
+	}
+	diagnostics := c.diagnostics("file:///cube.lsp")
+	if got, want := messages(diagnostics), []string{"unterminated form"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("diagnostics = %v, want %v", got, want)
+	}
+	if got, want := diagnostics[0].Range.Start, (Position{Line: 1, Character: 0}); got != want || diagnostics[0].Severity != SeverityError {
+		t.Errorf("diagnostic at %v with the severity %v, want at %v with %v", got, diagnostics[0].Severity, want, SeverityError)
+	}
+
+	hovers := []struct {
+		line, character int
+		want            []string
+	}{
+		{6, 32, []string{"(defun square (x))", "Returns the square of X."}},
+		{6, 41, []string{"(defclass <point> ())"}},
+		{6, 12, []string{"(defgeneric area (shape))", "Returns the area."}},
+		{7, 25, []string{"(defglobal *size* 3)", "The size."}},
+		{2, 3, []string{"`*` is a built-in function."}},
+		{0, 2, []string{"`defun` is a built-in special operator."}},
+	}
+	for _, test := range hovers {
+		var hover *Hover
+		c.result("textDocument/hover", at("file:///main.lsp", test.line, test.character), &hover)
+		if hover == nil {
+			t.Errorf("hover at %v:%v = null", test.line, test.character)
+			continue
+		}
+		for _, want := range test.want {
+			if !strings.Contains(hover.Contents.Value, want) {
+				t.Errorf("hover at %v:%v = %q, want %q in it", test.line, test.character, hover.Contents.Value, want)
+			}
+		}
+	}
+	var hover *Hover
+	c.result("textDocument/hover", at("file:///main.lsp", 3, 18), &hover)
+	if hover != nil {
+		t.Errorf("hover on a number = %+v, want null", hover)
+	}
+
+	definitions := []struct {
+		params TextDocumentPositionParams
+		want   []Location
+	}{
+		{at("file:///main.lsp", 6, 32), []Location{{"file:///main.lsp", Range{Position{0, 7}, Position{0, 13}}}}},
+		{at("file:///main.lsp", 7, 14), []Location{{"file:///cube.lsp", Range{Position{0, 7}, Position{0, 11}}}}},
+		{at("file:///main.lsp", 6, 21), []Location{{"file:///main.lsp", Range{Position{4, 10}, Position{4, 17}}}}},
+		{at("file:///main.lsp", 7, 29), []Location{{"file:///main.lsp", Range{Position{3, 11}, Position{3, 17}}}}},
+		{at("file:///main.lsp", 6, 1), []Location{}},
+	}
+	for _, test := range definitions {
+		var got []Location
+		c.result("textDocument/definition", test.params, &got)
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("definition at %v = %v, want %v", test.params.Position, got, test.want)
+		}
+	}
+
+	var list CompletionList
+	c.result("textDocument/completion", at("file:///main.lsp", 6, 33), &list)
+	labels := map[string]int{}
+	for _, item := range list.Items {
+		labels[item.Label] = item.Kind
+	}
+	for label, kind := range map[string]int{"square": CompletionFunction, "sqrt": CompletionFunction} {
+		if labels[label] != kind {
+			t.Errorf("completion of sq = %v, want %v of the kind %v", labels, label, kind)
+		}
+	}
+	c.result("textDocument/completion", at("file:///main.lsp", 4, 12), &list)
+	found := false
+	for _, item := range list.Items {
+		if !strings.HasPrefix(item.Label, "<p") {
+			t.Errorf("completion of <p has %v", item.Label)
+		}
+		found = found || item == CompletionItem{Label: "<point>", Kind: CompletionClass, Detail: "class"}
+	}
+	if !found {
+		t.Errorf("completion of <p = %v, want <point> in it", list.Items)
+	}
+
+	var symbols []DocumentSymbol
+	c.result("textDocument/documentSymbol", DocumentSymbolParams{TextDocument: TextDocumentIdentifier{URI: "file:///main.lsp"}}, &symbols)
+	got := []string{}
+	for _, s := range symbols {
+		got = append(got, strings.TrimSpace(s.Name+" "+s.Detail))
+	}
+	if want := []string{"square", "*size*", "<point>", "area", "area ((p <point>))"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("document symbols = %v, want %v", got, want)
+	}
+
+	c.notify("textDocument/didClose", DidCloseTextDocumentParams{TextDocument: TextDocumentIdentifier{URI: "file:///cube.lsp"}})
+	if got, want := messages(c.diagnostics("file:///main.lsp")), undefined; !reflect.DeepEqual(got, want) {
+		t.Errorf("diagnostics after closing = %v, want %v", got, want)
+	}
+
+	if m := c.call("shutdown", nil); m.Error != nil {
+		t.Errorf("shutdown = %v", m.Error.Message)
+	}
+	if status := c.exit(); status != 0 {
+		t.Errorf("exit status = %v, want 0", status)
+	}
+}
+
+func TestServer_ExitWithoutShutdown(t *testing.T) {
+	c := newClient(t)
+	c.call("initialize", map[string]interface{}{})
+	if m := c.call("unknown/method", nil); m.Error == nil || m.Error.Code != methodNotFound {
+		t.Errorf("unknown method = %+v, want the error %v", m.Error, methodNotFound)
+	}
+	if status := c.exit(); status != 1 {
+		t.Errorf("exit status = %v, want 1", status)
+	}
+}
+
+func TestDiagnostic(t *testing.T) {
+	text := "(defun f (x)\n  (let ((y 1)) (g x)))\n"
+	d := newDocument("file:///test.lsp", 1, text)
+	got := []Diagnostic{}
+	for _, found := range lint.Check(lint.Input{Name: d.uri, Reader: strings.NewReader(text)}) {
+		got = append(got, d.diagnostic(found))
+	}
+	want := []Diagnostic{
+		{Range{Position{1, 8}, Position{1, 13}}, SeverityWarning, "iris", "variable Y is not used"},
+		{Range{Position{1, 15}, Position{1, 20}}, SeverityError, "iris", "undefined function G"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("diagnostics of %q = %v, want %v", text, got, want)
+	}
+}
@@ -7,79 +7,216 @@ package main
 import (
 	"flag"
 	"fmt"
+	"io"
 	"os"
+	"path/filepath"
 	golang "runtime"
+	"time"
 
+	"github.com/islisp-dev/iris/dap"
+	"github.com/islisp-dev/iris/lint"
+	"github.com/islisp-dev/iris/lsp"
+	"github.com/islisp-dev/iris/repl"
 	"github.com/islisp-dev/iris/runtime"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
 	"github.com/islisp-dev/iris/runtime/ilos/instance"
 )
 
 var commit string
 
-func repl(quiet bool) {
-	if !quiet {
-		if commit == "" {
-			commit = "HEAD"
-		}
-		fmt.Printf("Iris ISLisp Interpreter Commit %v on %v\n", commit, golang.Version())
-		fmt.Printf("Copyright 2017 islisp-dev All Rights Reserved.\n")
-		fmt.Print(">>> ")
+// interactive runs the REPL on the terminal.
+func interactive(historyPath string) int {
+	if commit == "" {
+		commit = "HEAD"
+	}
+	fmt.Printf("Iris ISLisp Interpreter Commit %v on %v\n", commit, golang.Version())
+	fmt.Printf("Copyright 2017 islisp-dev All Rights Reserved.\n")
+	runtime.TopLevel.StandardInput = instance.NewStream(os.Stdin, nil)
+	runtime.TopLevel.StandardOutput = instance.NewStream(nil, os.Stdout)
+	runtime.TopLevel.ErrorOutput = instance.NewStream(nil, os.Stderr)
+	history, err := repl.LoadHistory(historyPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
 	}
+	return repl.New(os.Stdin, os.Stdout, history).Run()
+}
+
+// printCondition writes the report of condition to w on a line.
+func printCondition(w io.Writer, condition ilos.Instance) {
+	if _, err := runtime.ReportCondition(runtime.TopLevel, condition, instance.NewStream(nil, w)); err != nil {
+		fmt.Fprint(w, condition)
+	}
+	fmt.Fprintln(w)
+}
+
+// batch evaluates the forms read from the standard input and prints their
+// values. It returns 1 if an error is not handled.
+func batch() int {
 	runtime.TopLevel.StandardInput = instance.NewStream(os.Stdin, nil)
 	runtime.TopLevel.StandardOutput = instance.NewStream(nil, os.Stdout)
 	runtime.TopLevel.ErrorOutput = instance.NewStream(nil, os.Stderr)
+	status := 0
 	for exp, err := runtime.Read(runtime.TopLevel); err == nil; exp, err = runtime.Read(runtime.TopLevel) {
 		ret, err := runtime.Eval(runtime.TopLevel, exp)
+		if code, ok := runtime.ExitStatus(err); ok {
+			return code
+		}
 		if err != nil {
-			fmt.Println(err)
+			printCondition(os.Stdout, err)
+			status = 1
 		} else {
 			fmt.Println(ret)
 		}
-		if !quiet {
-			fmt.Print(">>> ")
-		}
 	}
+	return status
 }
 
-func script(path string) {
+// script evaluates the forms in the file at path and returns the exit status
+// of the program, which is 1 if an error is not handled.
+func script(path string) int {
 	file, err := os.Open(path)
 	if err != nil {
-		return
+		fmt.Fprintln(os.Stderr, err)
+		return 1
 	}
 	defer file.Close()
-	runtime.TopLevel.StandardInput = instance.NewStream(file, nil)
+	input := instance.NewStream(file, nil).(instance.Stream)
+	input.Reader.Name = path
+	runtime.TopLevel.StandardInput = input
 	runtime.TopLevel.StandardOutput = instance.NewStream(nil, os.Stdout)
 	runtime.TopLevel.ErrorOutput = instance.NewStream(nil, os.Stderr)
 	for {
 		exp, err := runtime.Read(runtime.TopLevel)
 		if err != nil {
-			if fmt.Sprint(err) != "#<END-OF-STREAM >" {
-				fmt.Println(err)
+			if !ilos.InstanceOf(class.EndOfStream, err) {
+				printCondition(os.Stderr, err)
+				return 1
 			}
-			return
+			return 0
 		}
 		_, err = runtime.Eval(runtime.TopLevel, exp)
+		if code, ok := runtime.ExitStatus(err); ok {
+			return code
+		}
 		if err != nil {
-			fmt.Println(err)
-			return
+			printCondition(os.Stderr, err)
+			return 1
 		}
 	}
 }
 
+// defaultHistoryPath returns the path of the history file in the home
+// directory, or an empty path if there is no home directory.
+func defaultHistoryPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".iris_history")
+}
+
+// writeProfile stops profile and writes it to the file at path in the format
+// of pprof.
+func writeProfile(profile *runtime.Profile, path string) error {
+	profile.Stop()
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := profile.WritePprof(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// writeCoverage stops coverage and writes it to the file at path in format,
+// which is text, html or lcov.
+func writeCoverage(coverage *runtime.Coverage, path, format string) error {
+	coverage.Stop()
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	write := coverage.WriteText
+	switch format {
+	case "html":
+		write = coverage.WriteHTML
+	case "lcov":
+		write = coverage.WriteLCOV
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// run runs the script named by the arguments, the REPL or the forms read from
+// the standard input, and returns the exit status.
+func run(historyPath string) int {
+	if flag.NArg() > 0 {
+		runtime.SetCommandLineArguments(flag.Args()[1:])
+		if os.Getenv(testResultsVariable) != "" {
+			return testChild(flag.Arg(0))
+		}
+		return script(flag.Arg(0))
+	}
+	if repl.IsTerminal(os.Stdin.Fd()) {
+		return interactive(historyPath)
+	}
+	return batch()
+}
+
 func main() {
+	flag.IntVar(&runtime.Safety, "safety", runtime.Safety, "check the declared types of arguments when positive")
+	historyPath := flag.String("history", defaultHistoryPath(), "save the history of the REPL in `file` unless empty")
+	profilePath := flag.String("profile", "", "write the profile of the Lisp functions to `file` in the format of pprof")
+	profileMode := flag.String("profile-mode", "deterministic", "profile every call if deterministic, or sample the calls if sampling")
+	profileInterval := flag.Duration("profile-interval", time.Millisecond, "the interval of the samples in the sampling mode")
+	coveragePath := flag.String("coverage", "", "write the coverage of the forms read from the files to `file`")
+	coverageFormat := flag.String("coverage-format", "text", "write the coverage in the text, html or lcov format")
 	flag.Parse()
-	if flag.NArg() > 0 {
-		script(flag.Arg(0))
-		return
+	if flag.Arg(0) == "test" {
+		os.Exit(test(flag.Args()[1:]))
 	}
-	info, err := os.Stdin.Stat()
-	if err != nil {
-		panic(err)
+	if flag.Arg(0) == "lint" {
+		os.Exit(lintFiles(os.Stdout, flag.Args()[1:]))
 	}
-	if (info.Mode() & os.ModeNamedPipe) == 0 {
-		repl(false)
-		return
+	if flag.Arg(0) == "dap" {
+		os.Exit(dap.NewServer(os.Stdin, os.Stdout).Run())
+	}
+	if flag.Arg(0) == "lsp" {
+		os.Exit(lsp.NewServer(os.Stdin, os.Stdout).Run())
+	}
+	// finish writes the profile and the coverage after running the program.
+	finish := []func() error{}
+	if *profilePath != "" {
+		if *profileMode != "deterministic" && *profileMode != "sampling" {
+			fmt.Fprintf(os.Stderr, "unknown profile mode: %v\n", *profileMode)
+			os.Exit(2)
+		}
+		profile := runtime.StartProfile(runtime.TopLevel.Clock, *profileMode == "sampling", *profileInterval)
+		finish = append(finish, func() error { return writeProfile(profile, *profilePath) })
+	}
+	if *coveragePath != "" {
+		if *coverageFormat != "text" && *coverageFormat != "html" && *coverageFormat != "lcov" {
+			fmt.Fprintf(os.Stderr, "unknown coverage format: %v\n", *coverageFormat)
+			os.Exit(2)
+		}
+		coverage := runtime.StartCoverage()
+		coverage.Code = lint.Code
+		finish = append(finish, func() error { return writeCoverage(coverage, *coveragePath, *coverageFormat) })
+	}
+	status := run(*historyPath)
+	for _, f := range finish {
+		if err := f(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			if status == 0 {
+				status = 1
+			}
+		}
 	}
-	repl(true)
-	return
+	os.Exit(status)
 }
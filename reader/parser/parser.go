@@ -6,6 +6,7 @@ package parser
 
 import (
 	"math"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
@@ -20,6 +21,26 @@ import (
 var eop = instance.NewSymbol("End Of Parentheses")
 var bod = instance.NewSymbol("Begin Of Dot")
 
+// Source is where a form is read from: the name of the input and the
+// positions of the beginning and the end of the form.
+type Source struct {
+	Name       string
+	Start, End tokenizer.Position
+}
+
+// Sources maps the lists read by Parse from the readers with names to where
+// they are read from. Nothing is recorded while it is nil.
+var Sources map[*instance.Cons]Source
+
+func record(t *tokenizer.Reader, start tokenizer.Position, form ilos.Instance) {
+	if Sources == nil || t.Name == "" {
+		return
+	}
+	if cons, ok := form.(*instance.Cons); ok {
+		Sources[cons] = Source{Name: t.Name, Start: start, End: t.Position()}
+	}
+}
+
 func ParseAtom(tok string) (ilos.Instance, ilos.Instance) {
 	//
 	// integer
@@ -77,7 +98,7 @@ func ParseAtom(tok string) (ilos.Instance, ilos.Instance) {
 		return instance.Nil, nil
 	}
 	str := `^(`
-	str += `[:&][a-zA-Z]+|`
+	str += `[:&][a-zA-Z][-a-zA-Z0-9]*|`
 	str += `\|.*\||`
 	str += `\+|-|1\+|1-|`
 	str += `[a-zA-Z<>/*=?_!$%[\]^{}~][-a-zA-Z0-9+<>/*=?_!$%[\]^{}~]*|`
@@ -91,10 +112,35 @@ func ParseAtom(tok string) (ilos.Instance, ilos.Instance) {
 		instance.NewSymbol("EXPECTED-CLASS"), class.Object)
 }
 
+// truncation returns the parse error of a form beginning with tok, which is
+// ended by the end of the input.
+func truncation(tok string) ilos.Instance {
+	return instance.NewParseError(env.NewEnvironment(nil, nil, nil, nil), instance.NewString([]rune(tok)), class.Cons)
+}
+
+// truncated returns the truncation of a form beginning with tok instead of
+// err of the end of stream.
+func truncated(tok string, err ilos.Instance) ilos.Instance {
+	if !ilos.InstanceOf(class.EndOfStream, err) {
+		return err
+	}
+	return truncation(tok)
+}
+
+// Truncated returns true if err is the parse error of a form ended by the end
+// of the input.
+func Truncated(err ilos.Instance) bool {
+	if !ilos.InstanceOf(class.ParseError, err) {
+		return false
+	}
+	expectedClass, _ := err.(instance.Instance).GetSlotValue(instance.NewSymbol("EXPECTED-CLASS"), class.ParseError)
+	return reflect.DeepEqual(expectedClass, class.Cons)
+}
+
 func parseMacro(tok string, t *tokenizer.Reader) (ilos.Instance, ilos.Instance) {
-	cdr, err := Parse(t)
+	cdr, err := parse(t)
 	if err != nil {
-		return nil, err
+		return nil, truncated(tok, err)
 	}
 	n := tok
 	if m, _ := regexp.MatchString("#[[:digit:]]+[aA]", tok); m {
@@ -134,22 +180,25 @@ func parseMacro(tok string, t *tokenizer.Reader) (ilos.Instance, ilos.Instance)
 	return instance.NewCons(m, instance.NewCons(cdr, instance.Nil)), nil
 }
 func parseCons(t *tokenizer.Reader) (ilos.Instance, ilos.Instance) {
-	car, err := Parse(t)
+	car, err := parse(t)
 	if err == eop {
 		return instance.Nil, nil
 	}
 	if err == bod {
-		cdr, err := Parse(t)
+		cdr, err := parse(t)
 		if err != nil {
-			return nil, err
+			return nil, truncated("(", err)
 		}
-		if _, err := Parse(t); err != eop {
-			return nil, err
+		if obj, err := parse(t); err != eop {
+			if err == nil {
+				err = instance.NewParseError(env.NewEnvironment(nil, nil, nil, nil), instance.NewString([]rune(obj.String())), class.Object)
+			}
+			return nil, truncated("(", err)
 		}
 		return cdr, nil
 	}
 	if err != nil {
-		return nil, err
+		return nil, truncated("(", err)
 	}
 	cdr, err := parseCons(t)
 	if err != nil {
@@ -158,8 +207,79 @@ func parseCons(t *tokenizer.Reader) (ilos.Instance, ilos.Instance) {
 	return instance.NewCons(car, cdr), nil
 }
 
-// Parse builds a internal expression from tokens
+// Parse builds a internal expression from tokens. A closing parenthesis or a
+// dot out of a list is a parse error, and so is a form ended by the end of
+// the input, while the end of stream is signaled only before a form.
 func Parse(t *tokenizer.Reader) (ilos.Instance, ilos.Instance) {
+	obj, err := parse(t)
+	switch err {
+	case eop:
+		return nil, instance.NewParseError(env.NewEnvironment(nil, nil, nil, nil), instance.NewString([]rune(")")), class.Object)
+	case bod:
+		return nil, instance.NewParseError(env.NewEnvironment(nil, nil, nil, nil), instance.NewString([]rune(".")), class.Object)
+	}
+	return obj, err
+}
+
+// SyntaxError is an error in reading the form at Source.
+type SyntaxError struct {
+	Source
+	Condition ilos.Instance
+	// Form is the form closed by ParseAll if it is ended by the end of the
+	// input, or nil.
+	Form ilos.Instance
+}
+
+// ParseAll returns the forms read from text, which is named name, and the
+// errors in reading them. It reads on after an error, and closes the lists
+// left open at the end of text, so that as many forms as possible are read
+// from a partial text, such as a file being edited. The form closed is not
+// returned with the others, since it is not complete.
+func ParseAll(name, text string) ([]ilos.Instance, []SyntaxError) {
+	length := len([]rune(text))
+	t := tokenizer.NewReader(strings.NewReader(text + strings.Repeat(")", unclosed(text))))
+	t.Name = name
+	forms := []ilos.Instance{}
+	errors := []SyntaxError{}
+	for {
+		end := t.Position()
+		form, err := Parse(t)
+		switch {
+		case err == nil && t.Position().Offset <= length:
+			forms = append(forms, form)
+		case err == nil:
+			start := tokenizer.TokenAfter(text, end)
+			errors = append(errors, SyntaxError{Source{name, start, start}, truncation("("), form})
+		case Truncated(err):
+			start := tokenizer.TokenAfter(text, end)
+			return forms, append(errors, SyntaxError{Source{name, start, start}, err, nil})
+		case ilos.InstanceOf(class.EndOfStream, err):
+			return forms, errors
+		default:
+			errors = append(errors, SyntaxError{Source{name, t.TokenPosition(), t.Position()}, err, nil})
+		}
+	}
+}
+
+// unclosed returns the number of the lists left open at the end of text.
+func unclosed(text string) int {
+	t := tokenizer.NewReader(strings.NewReader(text))
+	depth := 0
+	for {
+		tok, err := t.Next()
+		if err != nil {
+			return depth
+		}
+		if tok == "(" {
+			depth++
+		}
+		if tok == ")" && depth > 0 {
+			depth--
+		}
+	}
+}
+
+func parse(t *tokenizer.Reader) (ilos.Instance, ilos.Instance) {
 	tok, err := t.Next()
 	if err != nil {
 		return nil, instance.Create(env.NewEnvironment(nil, nil, nil, nil), class.EndOfStream)
@@ -170,11 +290,13 @@ func Parse(t *tokenizer.Reader) (ilos.Instance, ilos.Instance) {
 			return nil, instance.Create(env.NewEnvironment(nil, nil, nil, nil), class.EndOfStream)
 		}
 	}
+	start := t.TokenPosition()
 	if tok == "(" {
 		cons, err := parseCons(t)
 		if err != nil {
 			return nil, err
 		}
+		record(t, start, cons)
 		return cons, err
 	}
 	if tok == ")" {
@@ -188,6 +310,7 @@ func Parse(t *tokenizer.Reader) (ilos.Instance, ilos.Instance) {
 		if err != nil {
 			return nil, err
 		}
+		record(t, start, m)
 		return m, nil
 	}
 	atom, err1 := ParseAtom(tok)
@@ -5,10 +5,14 @@
 package parser
 
 import (
+	"fmt"
 	"reflect"
+	"strings"
 	"testing"
 
+	"github.com/islisp-dev/iris/reader/tokenizer"
 	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
 	"github.com/islisp-dev/iris/runtime/ilos/instance"
 )
 
@@ -165,3 +169,80 @@ func Test_parseAtom(t *testing.T) {
 		})
 	}
 }
+
+func TestSources(t *testing.T) {
+	defer func() { Sources = nil }()
+	Sources = map[*instance.Cons]Source{}
+	r := tokenizer.NewReader(strings.NewReader("(f (g x)\n  'y)"))
+	r.Name = "test.lsp"
+	form, err := Parse(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f := form.(*instance.Cons)
+	g := f.Cdr.(*instance.Cons).Car.(*instance.Cons)
+	quote := f.Cdr.(*instance.Cons).Cdr.(*instance.Cons).Car.(*instance.Cons)
+	want := map[*instance.Cons]Source{
+		f:     {"test.lsp", tokenizer.Position{Line: 1, Column: 1}, tokenizer.Position{Line: 2, Column: 6, Offset: 14}},
+		g:     {"test.lsp", tokenizer.Position{Line: 1, Column: 4, Offset: 3}, tokenizer.Position{Line: 1, Column: 9, Offset: 8}},
+		quote: {"test.lsp", tokenizer.Position{Line: 2, Column: 3, Offset: 11}, tokenizer.Position{Line: 2, Column: 5, Offset: 13}},
+	}
+	if !reflect.DeepEqual(Sources, want) {
+		t.Errorf("Sources = %v, want %v", Sources, want)
+	}
+	Sources = map[*instance.Cons]Source{}
+	if _, err := Parse(tokenizer.NewReader(strings.NewReader("(f)"))); err != nil || len(Sources) != 0 {
+		t.Errorf("Sources = %v for a reader without name, want none", Sources)
+	}
+}
+
+func TestParse_ParseError(t *testing.T) {
+	for _, text := range []string{")", ".", "(a . b c)"} {
+		_, err := Parse(tokenizer.NewReader(strings.NewReader(text)))
+		if !ilos.InstanceOf(class.ParseError, err) {
+			t.Errorf("Parse(%q) err = %v, want a parse error", text, err)
+		}
+	}
+}
+
+func TestParse_Truncated(t *testing.T) {
+	for _, text := range []string{"(deftest broken (car", "'", "(a .", "#(1 2"} {
+		_, err := Parse(tokenizer.NewReader(strings.NewReader(text)))
+		if !Truncated(err) {
+			t.Errorf("Parse(%q) err = %v, want a truncated form", text, err)
+		}
+	}
+	for _, text := range []string{"", " ; comment", ")"} {
+		_, err := Parse(tokenizer.NewReader(strings.NewReader(text)))
+		if Truncated(err) {
+			t.Errorf("Parse(%q) err = %v, want no truncated form", text, err)
+		}
+	}
+}
+
+func TestParseAll(t *testing.T) {
+	tests := []struct {
+		text   string
+		forms  int
+		errors []string
+		closed bool
+	}{
+		{"(a b) c 'd", 3, []string{}, false},
+		{"a) b", 2, []string{"1:2"}, false},
+		{"(a)\n(b (c)", 1, []string{"2:1"}, true},
+		{"(a \"bc", 0, []string{"1:1"}, false},
+		{"; comment\n(a) ; (b\n", 1, []string{}, false},
+	}
+	for _, test := range tests {
+		forms, errors := ParseAll("test.lsp", test.text)
+		got := []string{}
+		closed := false
+		for _, e := range errors {
+			got = append(got, fmt.Sprintf("%v:%v", e.Start.Line, e.Start.Column))
+			closed = closed || e.Form != nil
+		}
+		if len(forms) != test.forms || !reflect.DeepEqual(got, test.errors) || closed != test.closed {
+			t.Errorf("ParseAll(%q) = %v forms and the errors at %v, want %v and %v", test.text, len(forms), got, test.forms, test.errors)
+		}
+	}
+}
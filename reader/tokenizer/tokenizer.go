@@ -3,6 +3,7 @@ package tokenizer
 import (
 	"bufio"
 	"io"
+	"os"
 	"regexp"
 	"strings"
 )
@@ -12,46 +13,137 @@ import (
 // Reader is like bufio.Reader but has PeekRune
 // which returns a rune without advancing pointer
 type Reader struct {
-	err error
-	ru  rune
-	sz  int
-	rr  *bufio.Reader
+	// Name is the name of the input, such as the path of a file, or empty
+	// if it is not known.
+	Name     string
+	err      error
+	ru       rune
+	sz       int
+	peeked   bool
+	src      io.Reader
+	rr       *bufio.Reader
+	position Position
+	start    Position
+}
+
+// Position is a position in the input of a reader. Lines and columns are
+// counted from 1, and the offset in runes from 0.
+type Position struct {
+	Line, Column, Offset int
 }
 
 // NewReader creates interal reader from io.RuneReader
 func NewReader(r io.Reader) *Reader {
 	b := new(Reader)
+	b.src = r
 	b.rr = bufio.NewReader(r)
+	b.position = Position{Line: 1, Column: 1}
 	return b
 }
 
+// Position returns the position of the next rune to be read.
+func (r *Reader) Position() Position {
+	return r.position
+}
+
+// TokenPosition returns the position where the token last returned by Next
+// begins.
+func (r *Reader) TokenPosition() Position {
+	return r.start
+}
+
+func (r *Reader) advance(ru rune) {
+	r.position.Offset++
+	if ru == '\n' {
+		r.position.Line++
+		r.position.Column = 1
+	} else {
+		r.position.Column++
+	}
+}
+
 // PeekRune returns a rune without advancing pointer
 func (r *Reader) PeekRune() (rune, int, error) {
-	if r.ru == 0 {
+	if !r.peeked {
 		r.ru, r.sz, r.err = r.rr.ReadRune()
+		r.peeked = true
 	}
 	return r.ru, r.sz, r.err
 }
 
-// ReadRune returns a rune with advancing pointer
+// ReadRune returns a rune with advancing pointer. It never reads ahead, so
+// that reading from a terminal does not wait for more input than needed.
 func (r *Reader) ReadRune() (rune, int, error) {
-	if r.ru == 0 {
-		r.ru, r.sz, r.err = r.rr.ReadRune()
+	ru, sz, err := r.ru, r.sz, r.err
+	if !r.peeked {
+		ru, sz, err = r.rr.ReadRune()
+	}
+	r.peeked = false
+	if err == nil {
+		r.advance(ru)
 	}
-	ru := r.ru
-	sz := r.sz
-	err := r.err
-	r.ru, r.sz, r.err = r.rr.ReadRune()
 	return ru, sz, err
 }
 
+// ReadLine returns the runes up to the next newline, which is consumed but not
+// included, as is a preceding carriage return. The error is only returned if
+// no rune could be read.
+func (r *Reader) ReadLine() (string, error) {
+	var b strings.Builder
+	for {
+		ru, _, err := r.ReadRune()
+		if err != nil {
+			if b.Len() == 0 {
+				return "", err
+			}
+			return b.String(), nil
+		}
+		if ru == '\n' {
+			return strings.TrimSuffix(b.String(), "\r"), nil
+		}
+		b.WriteRune(ru)
+	}
+}
+
+// Ready reports whether a rune can be read without blocking. Input that is
+// neither buffered nor known to be in memory or in a regular file is reported
+// as not ready, since it cannot be examined without waiting for it.
+func (r *Reader) Ready() bool {
+	if r.peeked {
+		return r.err == nil
+	}
+	if r.rr.Buffered() > 0 {
+		return true
+	}
+	switch src := r.src.(type) {
+	case interface{ Len() int }:
+		return src.Len() > 0
+	case *os.File:
+		info, err := src.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return false
+		}
+		position, err := src.Seek(0, io.SeekCurrent)
+		return err == nil && position < info.Size()
+	}
+	return false
+}
+
+// Buffered returns the number of bytes which have been read from the
+// underlying reader but not yet returned.
+func (r *Reader) Buffered() int {
+	if !r.peeked || r.err != nil {
+		return r.rr.Buffered()
+	}
+	return r.rr.Buffered() + r.sz
+}
+
 func (r *Reader) Read(b []byte) (int, error) {
-	ru := r.ru
-	sz := r.sz
-	err := r.err
-	r.ru, r.sz, r.err = r.rr.ReadRune()
-	copy(b, []byte(string([]rune{ru})))
-	return sz, err
+	ru, _, err := r.ReadRune()
+	if err != nil {
+		return 0, err
+	}
+	return copy(b, string(ru)), nil
 }
 
 var str = `^1\+$|^1-$|` +
@@ -64,11 +156,11 @@ var str = `^1\+$|^1-$|` +
 	`^#\\[[:alpha:]]+$|` +
 	`^#\\[[:graph:]]$|` +
 	`^"(?:\\\\|\\"|[^\\"])*"$|` +
-	`^[:&][a-zA-Z]+$|` +
+	`^[:&][a-zA-Z][-a-zA-Z0-9]*$|` +
 	`^\+$|^-$|^[a-zA-Z<>/*=?_!$%[\]^{}~][-a-zA-Z0-9+<>/*=?_!$%[\]^{}~]*$|` +
 	`^\|(?:\\\\|\\\||[^\\|])*\|$|` +
 	`^[.()]$|` +
-	"^;.*?\n|$" +
+	"^;[^\n]*$|" +
 	`^#\|.*?\|#$|` +
 	"^#'$|^,@?$|^'$|^`$|^#[[:digit:]]*[aA]$|^#$" // TODO: hangs at #ab or #3
 var re = regexp.MustCompile(str)
@@ -85,6 +177,7 @@ func (r *Reader) Next() (string, error) {
 		}
 		r.ReadRune()
 	}
+	r.start = r.position
 	buf := ""
 	mat := false
 	num := false
@@ -131,3 +224,18 @@ func (r *Reader) Next() (string, error) {
 	}
 	return buf, nil
 }
+
+// TokenAfter returns the position where the first token in text at or after
+// p begins, skipping the comments, or p if there is no such token.
+func TokenAfter(text string, p Position) Position {
+	r := NewReader(strings.NewReader(text))
+	for {
+		tok, err := r.Next()
+		if err != nil {
+			return p
+		}
+		if r.TokenPosition().Offset >= p.Offset && tok[:1] != ";" && !strings.HasPrefix(tok, "#|") {
+			return r.TokenPosition()
+		}
+	}
+}
@@ -48,3 +48,26 @@ func TestTokenizer_Next(t *testing.T) {
 		})
 	}
 }
+
+func TestTokenizer_NextComment(t *testing.T) {
+	tokenizer := NewReader(strings.NewReader("; comment\n(a) ; end\n"))
+	for _, want := range []string{"; comment", "(", "a", ")", "; end"} {
+		if got, err := tokenizer.Next(); got != want || err != nil {
+			t.Errorf("Tokenizer.Next() = %q, %v, want %q", got, err, want)
+		}
+	}
+}
+
+func TestTokenizer_TokenPosition(t *testing.T) {
+	tokenizer := NewReader(strings.NewReader("(a\n  \"é\" b)\n"))
+	want := []Position{{1, 1, 0}, {1, 2, 1}, {2, 3, 5}, {2, 7, 9}, {2, 8, 10}}
+	for _, w := range want {
+		tok, _ := tokenizer.Next()
+		if got := tokenizer.TokenPosition(); got != w {
+			t.Errorf("Tokenizer.TokenPosition() of %q = %v, want %v", tok, got, w)
+		}
+	}
+	if got, want := tokenizer.Position(), (Position{2, 9, 11}); got != want {
+		t.Errorf("Tokenizer.Position() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,212 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package repl
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/islisp-dev/iris/reader/parser"
+	"github.com/islisp-dev/iris/reader/tokenizer"
+	"github.com/islisp-dev/iris/runtime"
+	"github.com/islisp-dev/iris/runtime/env"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+const debuggerHelp = `Debugger commands:
+  :abort or 0          Return to toplevel.
+  :continue [form]     Continue the condition with the value of form.
+  n                    Invoke the restart numbered n.
+  :backtrace           Show the frames of the function calls.
+  :frame n             Select the frame numbered n and show its variables.
+  :locals              Show the variables of the selected frame.
+  :help                Show this message.
+Any other form is evaluated in the selected frame.
+`
+
+// debugger is the state of a break loop.
+type debugger struct {
+	e         env.Environment
+	condition ilos.Instance
+	frames    []*env.Frame
+	restarts  []ilos.Instance
+	selected  int
+}
+
+// debug is the handler of the conditions which the program does not handle.
+// It enters a break loop one level deeper than the current one, in which the
+// user can inspect the frames of the calls in progress, evaluate forms in
+// them, and continue or abort.
+func (r *REPL) debug(e env.Environment, condition ilos.Instance) (ilos.Instance, ilos.Instance) {
+	restarts, err := runtime.ComputeRestarts(e, condition)
+	if err != nil {
+		return nil, err
+	}
+	d := &debugger{e: e, condition: condition, frames: e.Frames(), restarts: restarts.(instance.List).Slice()}
+	r.level++
+	defer func() { r.level-- }()
+	r.printCondition(d)
+	prompt := fmt.Sprintf("%d] ", r.level)
+	for {
+		text, err := r.read(prompt)
+		if err == ErrInterrupted {
+			continue
+		}
+		if err != nil {
+			r.aborting = true
+			return nil, condition
+		}
+		if ret, err, ok := r.command(d, text); ok {
+			return ret, err
+		}
+	}
+}
+
+// command runs the debugger command in text. It returns true with the result
+// of the handler if the break loop is left.
+func (r *REPL) command(d *debugger, text string) (ilos.Instance, ilos.Instance, bool) {
+	t := tokenizer.NewReader(strings.NewReader(text))
+	exp, err := parser.Parse(t)
+	if err != nil {
+		if !ilos.InstanceOf(class.EndOfStream, err) {
+			fmt.Fprintln(r.out, err)
+		}
+		return nil, nil, false
+	}
+	argument, err := parser.Parse(t)
+	if err != nil {
+		argument = nil
+	}
+	switch exp {
+	case instance.NewInteger(0), instance.NewSymbol(":ABORT"):
+		r.aborting = true
+		return nil, d.condition, true
+	case instance.NewSymbol(":CONTINUE"):
+		if b, ok := d.condition.(instance.Instance).GetSlotValue(instance.NewSymbol("IRIS.CONTINUABLE"), class.SeriousCondition); !ok || b == runtime.Nil {
+			fmt.Fprintln(r.out, "The condition is not continuable.")
+			return nil, nil, false
+		}
+		value := runtime.Nil
+		if argument != nil {
+			ret, err := runtime.Eval(r.frameEnvironment(d), argument)
+			if err != nil {
+				return r.failed(err)
+			}
+			value = ret
+		}
+		ret, err := runtime.ContinueCondition(d.e, d.condition, value)
+		return ret, err, true
+	case instance.NewSymbol(":BACKTRACE"):
+		r.printBacktrace(d)
+		return nil, nil, false
+	case instance.NewSymbol(":FRAME"):
+		n, ok := argument.(instance.Integer)
+		if !ok || int(n) < 0 || int(n) >= len(d.frames) {
+			fmt.Fprintf(r.out, "There are frames from 0 to %d.\n", len(d.frames)-1)
+			return nil, nil, false
+		}
+		d.selected = int(n)
+		r.printFrame(d.selected, d.frames[d.selected])
+		r.printLocals(d)
+		return nil, nil, false
+	case instance.NewSymbol(":LOCALS"):
+		r.printLocals(d)
+		return nil, nil, false
+	case instance.NewSymbol(":HELP"):
+		fmt.Fprint(r.out, debuggerHelp)
+		return nil, nil, false
+	}
+	if n, ok := exp.(instance.Integer); ok {
+		if int(n) < 1 || int(n) > len(d.restarts) {
+			fmt.Fprintf(r.out, "There are restarts from 0 to %d.\n", len(d.restarts))
+			return nil, nil, false
+		}
+		ret, err := runtime.InvokeRestartInteractively(d.e, d.restarts[int(n)-1])
+		return ret, err, true
+	}
+	if err := r.eval(r.frameEnvironment(d), text); err != nil {
+		return r.failed(err)
+	}
+	return nil, nil, false
+}
+
+// failed leaves the break loop if err exits the program or aborts to the
+// toplevel, and prints err otherwise.
+func (r *REPL) failed(err ilos.Instance) (ilos.Instance, ilos.Instance, bool) {
+	if _, ok := runtime.ExitStatus(err); ok || r.aborting {
+		return nil, err, true
+	}
+	fmt.Fprintln(r.out, err)
+	return nil, nil, false
+}
+
+// frameEnvironment returns the environment in which the forms are evaluated
+// in the selected frame. Errors there enter a nested break loop.
+func (r *REPL) frameEnvironment(d *debugger) env.Environment {
+	e := d.e
+	if d.selected < len(d.frames) && d.frames[d.selected].Environment != nil {
+		e = *d.frames[d.selected].Environment
+	}
+	e.Handler = r.handler
+	return e
+}
+
+func (r *REPL) printCondition(d *debugger) {
+	stream := instance.NewStream(nil, r.out)
+	fmt.Fprint(r.out, "Debugger entered on ")
+	if _, err := runtime.ReportCondition(d.e, d.condition, stream); err != nil {
+		fmt.Fprint(r.out, d.condition)
+	}
+	fmt.Fprint(r.out, "\n\nRestarts:\n  0: [ABORT] Return to toplevel.\n")
+	for i, restart := range d.restarts {
+		report := restart.(*instance.Restart).Report
+		if report == runtime.Nil {
+			report = instance.NewString([]rune(""))
+		}
+		runtime.Format(d.e, stream, instance.NewString([]rune("  ~A: [~A] ~A~%")), instance.NewInteger(i+1), restart.(*instance.Restart).Name, report)
+	}
+	fmt.Fprintln(r.out)
+	r.printBacktrace(d)
+	fmt.Fprintln(r.out, "\nType :help for the debugger commands.")
+}
+
+func (r *REPL) printBacktrace(d *debugger) {
+	fmt.Fprintln(r.out, "Backtrace:")
+	for i, frame := range d.frames {
+		r.printFrame(i, frame)
+	}
+}
+
+func (r *REPL) printFrame(i int, frame *env.Frame) {
+	arguments, _ := runtime.List(runtime.TopLevel, frame.Arguments...)
+	fmt.Fprintf(r.out, "  %d: %v\n", i, instance.NewCons(frame.Name, arguments))
+}
+
+// printLocals prints the variables bound in the selected frame, the
+// innermost first. The global variables are not shown.
+func (r *REPL) printLocals(d *debugger) {
+	if d.selected >= len(d.frames) || d.frames[d.selected].Environment == nil {
+		fmt.Fprintln(r.out, "No variables.")
+		return
+	}
+	variables := d.frames[d.selected].Environment.Variable
+	seen := map[ilos.Instance]bool{}
+	for i := len(variables) - 1; i > 0; i-- {
+		names := []ilos.Instance{}
+		for name := range variables[i] {
+			if !seen[name] {
+				seen[name] = true
+				names = append(names, name)
+			}
+		}
+		sort.Slice(names, func(a, b int) bool { return names[a].String() < names[b].String() })
+		for _, name := range names {
+			fmt.Fprintf(r.out, "  %v = %v\n", name, variables[i][name])
+		}
+	}
+}
@@ -0,0 +1,102 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package repl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDebugger(t *testing.T) {
+	setup := "(defun debugger-test (x y) (+ (car x) y))"
+	tests := []struct {
+		name     string
+		form     string
+		commands string
+		want     []string
+		wantNot  []string
+	}{
+		{
+			name:     "abort",
+			form:     "(debugger-test 1 2) 'next",
+			commands: ":abort\n",
+			want:     []string{"Debugger entered on", "0: (CAR 1)", "1: (DEBUGGER-TEST 1 2)", "1] 1 is not an instance of <CONS>.\n"},
+			wantNot:  []string{"NEXT"},
+		},
+		{
+			name:     "funcall",
+			form:     "(funcall #'debugger-test 1 2)",
+			commands: ":frame 1\n(list y x)\n:abort\n",
+			want:     []string{"0: (CAR 1)", "1: (DEBUGGER-TEST 1 2)", "2: (FUNCALL #<FUNCTION> 1 2)", "(2 1)"},
+		},
+		{
+			name:     "end of input",
+			form:     "(debugger-test 1 2)",
+			commands: "",
+			want:     []string{"1] 1 is not an instance of <CONS>.\n"},
+		},
+		{
+			name:     "locals",
+			form:     "(debugger-test 1 2)",
+			commands: ":frame 1\n(list y x)\n0\n",
+			want:     []string{"X = 1", "Y = 2", "(2 1)"},
+		},
+		{
+			name:     "continue",
+			form:     "(list (cerror \"Use a value.\" \"Failed\") 'next)",
+			commands: ":continue (+ 1 2)\n",
+			want:     []string{"1: [CONTINUE] Use a value.", "(3 NEXT)"},
+		},
+		{
+			name:     "not continuable",
+			form:     "(debugger-test 1 2)",
+			commands: ":continue 1\n:abort\n",
+			want:     []string{"The condition is not continuable."},
+		},
+		{
+			name:     "restart",
+			form:     "(restart-case (error \"Failed\") (use-it () 'restarted))",
+			commands: "1\n",
+			want:     []string{"1: [USE-IT]", "RESTARTED"},
+		},
+		{
+			name:     "nested",
+			form:     "(debugger-test 1 2)",
+			commands: "(car 2)\n:backtrace\n:abort\n",
+			want:     []string{"1] ", "2] ", "0: (CAR 2)"},
+		},
+		{
+			name:     "exit",
+			form:     "(debugger-test 1 2)",
+			commands: "(exit 3)\n",
+			want:     []string{"Debugger entered on"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			r := New(strings.NewReader(tt.commands), &out, nil)
+			r.evalTopLevel(setup)
+			status, exited := r.evalTopLevel(tt.form)
+			if exited != (tt.name == "exit") || (exited && status != 3) {
+				t.Errorf("evalTopLevel() exit = %v, %v", status, exited)
+			}
+			for _, want := range tt.want {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("output does not contain %q:\n%v", want, out.String())
+				}
+			}
+			for _, want := range tt.wantNot {
+				if strings.Contains(out.String(), want) {
+					t.Errorf("output contains %q:\n%v", want, out.String())
+				}
+			}
+			if r.level != 0 || r.aborting {
+				t.Errorf("level = %v, aborting = %v after the debugger", r.level, r.aborting)
+			}
+		})
+	}
+}
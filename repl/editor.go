@@ -0,0 +1,278 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package repl
+
+import (
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ErrInterrupted is returned by ReadLine when the line is abandoned by
+// Ctrl-C.
+var ErrInterrupted = errors.New("interrupted")
+
+// Editor reads lines from a terminal in raw mode. It supports the cursor
+// movement with the arrow keys and the Emacs key bindings, the history with
+// the up and down keys, and completion with the tab key.
+type Editor struct {
+	in      io.Reader
+	out     io.Writer
+	history *History
+	// Complete returns the candidates for the word which ends at pos in
+	// line, and the index where the word starts.
+	Complete func(line []rune, pos int) ([]string, int)
+}
+
+// NewEditor returns an editor reading keys from in and drawing on out. The
+// lines read are browsable in history, but they are not added to it.
+func NewEditor(in io.Reader, out io.Writer, history *History) *Editor {
+	if history == nil {
+		history = &History{}
+	}
+	return &Editor{in: in, out: out, history: history}
+}
+
+func ctrl(r rune) rune {
+	return r & 0x1f
+}
+
+func (ed *Editor) readRune() (rune, error) {
+	buf := []byte{}
+	b := make([]byte, 1)
+	for {
+		if _, err := io.ReadFull(ed.in, b); err != nil {
+			return 0, err
+		}
+		buf = append(buf, b[0])
+		if utf8.FullRune(buf) {
+			r, _ := utf8.DecodeRune(buf)
+			return r, nil
+		}
+	}
+}
+
+// readEscape reads the rest of an escape sequence sent by a key and returns
+// the key it stands for, or 0 if the sequence is not known.
+func (ed *Editor) readEscape() (rune, error) {
+	r, err := ed.readRune()
+	if err != nil || (r != '[' && r != 'O') {
+		return 0, err
+	}
+	r, err = ed.readRune()
+	if err != nil {
+		return 0, err
+	}
+	if '0' <= r && r <= '9' {
+		digits := string(r)
+		for {
+			if r, err = ed.readRune(); err != nil {
+				return 0, err
+			}
+			if r < '0' || '9' < r {
+				break
+			}
+			digits += string(r)
+		}
+		if r != '~' {
+			return 0, nil
+		}
+		switch n, _ := strconv.Atoi(digits); n {
+		case 1, 7:
+			return ctrl('A'), nil
+		case 3:
+			return keyDelete, nil
+		case 4, 8:
+			return ctrl('E'), nil
+		}
+		return 0, nil
+	}
+	switch r {
+	case 'A':
+		return ctrl('P'), nil
+	case 'B':
+		return ctrl('N'), nil
+	case 'C':
+		return ctrl('F'), nil
+	case 'D':
+		return ctrl('B'), nil
+	case 'H':
+		return ctrl('A'), nil
+	case 'F':
+		return ctrl('E'), nil
+	}
+	return 0, nil
+}
+
+// keyDelete stands for the delete key, which has no control character.
+const keyDelete = -1
+
+// ReadLine reads a line after showing prompt. It returns io.EOF if Ctrl-D is
+// typed on an empty line and ErrInterrupted if Ctrl-C is typed.
+func (ed *Editor) ReadLine(prompt string) (string, error) {
+	line := []rune{}
+	pos := 0
+	index := ed.history.Len()
+	saved := ""
+	browse := func(to int) {
+		if to < 0 || to > ed.history.Len() {
+			return
+		}
+		if index == ed.history.Len() {
+			saved = string(line)
+		}
+		index = to
+		if index == ed.history.Len() {
+			line = []rune(saved)
+		} else {
+			line = []rune(ed.history.At(index))
+		}
+		pos = len(line)
+	}
+	ed.refresh(prompt, line, pos)
+	for {
+		r, err := ed.readRune()
+		if err != nil {
+			if err == io.EOF && len(line) > 0 {
+				io.WriteString(ed.out, "\r\n")
+				return string(line), nil
+			}
+			return "", err
+		}
+		if r == 0x1b {
+			if r, err = ed.readEscape(); err != nil {
+				return "", err
+			}
+		}
+		switch r {
+		case '\r', '\n':
+			io.WriteString(ed.out, "\r\n")
+			return string(line), nil
+		case ctrl('C'):
+			io.WriteString(ed.out, "^C\r\n")
+			return "", ErrInterrupted
+		case ctrl('D'):
+			if len(line) == 0 {
+				io.WriteString(ed.out, "\r\n")
+				return "", io.EOF
+			}
+			fallthrough
+		case keyDelete:
+			if pos < len(line) {
+				line = append(line[:pos], line[pos+1:]...)
+			}
+		case ctrl('H'), 0x7f:
+			if pos > 0 {
+				line = append(line[:pos-1], line[pos:]...)
+				pos--
+			}
+		case ctrl('A'):
+			pos = 0
+		case ctrl('E'):
+			pos = len(line)
+		case ctrl('B'):
+			if pos > 0 {
+				pos--
+			}
+		case ctrl('F'):
+			if pos < len(line) {
+				pos++
+			}
+		case ctrl('K'):
+			line = line[:pos]
+		case ctrl('U'):
+			line = line[pos:]
+			pos = 0
+		case ctrl('W'):
+			start := pos
+			for start > 0 && unicode.IsSpace(line[start-1]) {
+				start--
+			}
+			for start > 0 && !unicode.IsSpace(line[start-1]) {
+				start--
+			}
+			line = append(line[:start], line[pos:]...)
+			pos = start
+		case ctrl('L'):
+			io.WriteString(ed.out, "\x1b[H\x1b[2J")
+		case ctrl('P'):
+			browse(index - 1)
+		case ctrl('N'):
+			browse(index + 1)
+		case '\t':
+			line, pos = ed.complete(line, pos)
+		default:
+			if unicode.IsPrint(r) {
+				line = append(line[:pos], append([]rune{r}, line[pos:]...)...)
+				pos++
+			}
+		}
+		ed.refresh(prompt, line, pos)
+	}
+}
+
+// complete replaces the word before pos with the longest common prefix of its
+// candidates. The candidates are listed if there is nothing to insert.
+func (ed *Editor) complete(line []rune, pos int) ([]rune, int) {
+	if ed.Complete == nil {
+		return line, pos
+	}
+	candidates, start := ed.Complete(line, pos)
+	if len(candidates) == 0 {
+		io.WriteString(ed.out, "\a")
+		return line, pos
+	}
+	prefix := []rune(candidates[0])
+	for _, candidate := range candidates[1:] {
+		c := []rune(candidate)
+		n := 0
+		for n < len(prefix) && n < len(c) && prefix[n] == c[n] {
+			n++
+		}
+		prefix = prefix[:n]
+	}
+	if len(prefix) <= pos-start {
+		if len(candidates) > 1 {
+			io.WriteString(ed.out, "\r\n"+strings.Join(candidates, "  ")+"\r\n")
+		}
+		return line, pos
+	}
+	rest := append([]rune{}, line[pos:]...)
+	line = append(append(line[:start], prefix...), rest...)
+	return line, start + len(prefix)
+}
+
+// refresh redraws prompt and line, and puts the cursor at pos.
+func (ed *Editor) refresh(prompt string, line []rune, pos int) {
+	var b strings.Builder
+	b.WriteString("\r" + prompt + string(line) + "\x1b[K\r")
+	if n := utf8.RuneCountInString(prompt) + pos; n > 0 {
+		b.WriteString("\x1b[" + strconv.Itoa(n) + "C")
+	}
+	io.WriteString(ed.out, b.String())
+}
+
+// readPlainLine reads a line after showing prompt without any editing, for the
+// input which is not a terminal.
+func (ed *Editor) readPlainLine(prompt string) (string, error) {
+	io.WriteString(ed.out, prompt)
+	line := []rune{}
+	for {
+		r, err := ed.readRune()
+		if err != nil {
+			if err == io.EOF && len(line) > 0 {
+				return string(line), nil
+			}
+			return "", err
+		}
+		if r == '\n' {
+			return strings.TrimSuffix(string(line), "\r"), nil
+		}
+		line = append(line, r)
+	}
+}
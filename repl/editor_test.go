@@ -0,0 +1,79 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package repl
+
+import (
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+)
+
+func TestEditorReadLine(t *testing.T) {
+	history := &History{lines: []string{"(car x)", "(cdr y)"}}
+	tests := []struct {
+		name    string
+		keys    string
+		want    string
+		wantErr error
+	}{
+		{"insert", "abc\r", "abc", nil},
+		{"backspace", "abd\x7fc\r", "abc", nil},
+		{"arrows", "ac\x1b[Db\x1b[C!\r", "abc!", nil},
+		{"home and end", "bc\x01a\x05d\r", "abcd", nil},
+		{"delete", "abxc\x1b[D\x1b[D\x1b[3~\r", "abc", nil},
+		{"kill", "abc def\x01\x1b[C\x0b\r", "a", nil},
+		{"kill word", "abc def\x17\r", "abc ", nil},
+		{"history", "\x1b[A\x1b[A\r", "(car x)", nil},
+		{"history back", "new\x1b[A\x1b[B\r", "new", nil},
+		{"multibyte", "λx\r", "λx", nil},
+		{"interrupt", "abc\x03", "", ErrInterrupted},
+		{"end of file", "\x04", "", io.EOF},
+		{"unterminated", "abc", "abc", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ed := NewEditor(strings.NewReader(tt.keys), ioutil.Discard, history)
+			got, err := ed.ReadLine(Prompt)
+			if err != tt.wantErr {
+				t.Errorf("ReadLine() err = %v, want %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("ReadLine() got = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEditorComplete(t *testing.T) {
+	words := []string{"car", "cdr", "create-list", "create-string"}
+	complete := func(line []rune, pos int) ([]string, int) {
+		start := strings.LastIndexAny(string(line[:pos]), " (") + 1
+		candidates := []string{}
+		for _, word := range words {
+			if strings.HasPrefix(word, string(line[start:pos])) {
+				candidates = append(candidates, word)
+			}
+		}
+		return candidates, start
+	}
+	tests := []struct {
+		keys string
+		want string
+	}{
+		{"(ca\t\r", "(car"},
+		{"(cr\t\r", "(create-"},
+		{"(cre\t\tl\t x)\r", "(create-list x)"},
+		{"(x\t\r", "(x"},
+	}
+	for _, tt := range tests {
+		ed := NewEditor(strings.NewReader(tt.keys), ioutil.Discard, nil)
+		ed.Complete = complete
+		got, err := ed.ReadLine(Prompt)
+		if err != nil || got != tt.want {
+			t.Errorf("ReadLine(%q) got = %q, %v, want %q", tt.keys, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,83 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package repl
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// maxHistory is the number of lines kept in a history.
+const maxHistory = 1000
+
+// History is the list of the lines entered so far, which is saved in a file so
+// that it survives the session.
+type History struct {
+	path  string
+	lines []string
+}
+
+// LoadHistory reads the history saved in the file at path. The file does not
+// need to exist. The history is not saved if path is empty.
+func LoadHistory(path string) (*History, error) {
+	h := &History{path: path}
+	if path == "" {
+		return h, nil
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return h, nil
+		}
+		return h, err
+	}
+	defer file.Close()
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		h.append(scanner.Text())
+	}
+	return h, scanner.Err()
+}
+
+// Len returns the number of lines in h.
+func (h *History) Len() int {
+	return len(h.lines)
+}
+
+// At returns the i-th line of h, the oldest first.
+func (h *History) At(i int) string {
+	return h.lines[i]
+}
+
+// Add appends line to h and to its file. Blank lines and the repetitions of
+// the last line are not recorded, and newlines are replaced by spaces.
+func (h *History) Add(line string) error {
+	line = strings.Join(strings.Fields(line), " ")
+	if line == "" || (len(h.lines) > 0 && h.lines[len(h.lines)-1] == line) {
+		return nil
+	}
+	h.append(line)
+	if h.path == "" {
+		return nil
+	}
+	file, err := os.OpenFile(h.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(file, line); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func (h *History) append(line string) {
+	h.lines = append(h.lines, line)
+	if len(h.lines) > maxHistory {
+		h.lines = h.lines[len(h.lines)-maxHistory:]
+	}
+}
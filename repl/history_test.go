@@ -0,0 +1,35 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package repl
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestHistory(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history")
+	h, err := LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range []string{"(+ 1 2)\n", "", "(+ 1 2)", "(list 1\n  2)\n"} {
+		if err := h.Add(line); err != nil {
+			t.Fatal(err)
+		}
+	}
+	want := []string{"(+ 1 2)", "(list 1 2)"}
+	if !reflect.DeepEqual(h.lines, want) {
+		t.Errorf("Add() lines = %q, want %q", h.lines, want)
+	}
+	h, err = LoadHistory(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(h.lines, want) {
+		t.Errorf("LoadHistory() lines = %q, want %q", h.lines, want)
+	}
+}
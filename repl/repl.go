@@ -0,0 +1,267 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+// Package repl implements the interactive read-eval-print loop of iris.
+package repl
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/islisp-dev/iris/reader/parser"
+	"github.com/islisp-dev/iris/reader/tokenizer"
+	"github.com/islisp-dev/iris/runtime"
+	"github.com/islisp-dev/iris/runtime/env"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// Prompt is shown when a new form is read, and ContinuationPrompt when the
+// form is continued on the next line.
+const (
+	Prompt             = ">>> "
+	ContinuationPrompt = "... "
+)
+
+// REPL reads forms from a terminal, evaluates them in the top level
+// environment and prints their values. The last three values are bound to
+// the variables *, ** and ***. The conditions which are not handled enter
+// the debugger.
+type REPL struct {
+	in      io.Reader
+	out     io.Writer
+	editor  *Editor
+	history *History
+	handler ilos.Instance
+	// level is the depth of the nested break loops.
+	level int
+	// aborting tells that the break loops are being left for the toplevel.
+	aborting bool
+}
+
+// New returns a REPL reading from in and writing to out, which records the
+// forms in history. It installs its debugger as the handler of the top level
+// environment.
+func New(in io.Reader, out io.Writer, history *History) *REPL {
+	for _, name := range []string{"*", "**", "***"} {
+		runtime.TopLevel.Variable.Define(instance.NewSymbol(name), runtime.Nil)
+	}
+	editor := NewEditor(in, out, history)
+	editor.Complete = Complete
+	r := &REPL{in: in, out: out, editor: editor, history: editor.history}
+	r.handler = instance.NewFunction(instance.NewSymbol("DEBUGGER"), r.debug)
+	runtime.TopLevel.Handler = r.handler
+	return r
+}
+
+// Run runs the loop until the end of the input or exit, and returns the exit
+// status of the program. Errors are printed and the loop continues.
+func (r *REPL) Run() int {
+	for {
+		text, err := r.read(Prompt)
+		if err == ErrInterrupted {
+			continue
+		}
+		if err != nil {
+			return 0
+		}
+		if err := r.history.Add(text); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		if status, ok := r.evalTopLevel(text); ok {
+			return status
+		}
+	}
+}
+
+// read reads lines until they make complete forms.
+func (r *REPL) read(prompt string) (string, error) {
+	text := ""
+	for {
+		line, err := r.readLine(prompt)
+		if err != nil {
+			return "", err
+		}
+		text += line + "\n"
+		if !Incomplete(text) {
+			return text, nil
+		}
+		prompt = ContinuationPrompt
+	}
+}
+
+func (r *REPL) readLine(prompt string) (string, error) {
+	file, ok := r.in.(*os.File)
+	if !ok {
+		return r.editor.readPlainLine(prompt)
+	}
+	restore, err := makeRaw(file.Fd())
+	if err != nil {
+		return r.editor.readPlainLine(prompt)
+	}
+	defer restore()
+	return r.editor.ReadLine(prompt)
+}
+
+// evalTopLevel evaluates the forms in text at the toplevel. It returns the
+// exit status if exit is called.
+func (r *REPL) evalTopLevel(text string) (int, bool) {
+	err := r.eval(runtime.TopLevel, text)
+	if status, ok := runtime.ExitStatus(err); ok {
+		return status, true
+	}
+	if r.aborting {
+		r.aborting = false
+		r.printError(runtime.TopLevel, err)
+	}
+	return 0, false
+}
+
+// eval evaluates the forms in text in e and prints their values. The errors
+// are printed, unless they exit or abort to the toplevel, in which case the
+// rest of the forms are not evaluated and the error is returned.
+func (r *REPL) eval(e env.Environment, text string) ilos.Instance {
+	t := tokenizer.NewReader(strings.NewReader(text))
+	for {
+		exp, err := parser.Parse(t)
+		if err != nil {
+			if !ilos.InstanceOf(class.EndOfStream, err) {
+				r.printError(e, err)
+			}
+			return nil
+		}
+		ret, err := runtime.Eval(e, exp)
+		if err != nil {
+			if _, ok := runtime.ExitStatus(err); ok || r.aborting {
+				return err
+			}
+			r.printError(e, err)
+			continue
+		}
+		last, _ := runtime.TopLevel.Variable.Get(instance.NewSymbol("*"))
+		second, _ := runtime.TopLevel.Variable.Get(instance.NewSymbol("**"))
+		runtime.TopLevel.Variable.Define(instance.NewSymbol("***"), second)
+		runtime.TopLevel.Variable.Define(instance.NewSymbol("**"), last)
+		runtime.TopLevel.Variable.Define(instance.NewSymbol("*"), ret)
+		fmt.Fprintln(r.out, ret)
+	}
+}
+
+// printError prints the report of condition in e on a line.
+func (r *REPL) printError(e env.Environment, condition ilos.Instance) {
+	if _, err := runtime.ReportCondition(e, condition, instance.NewStream(nil, r.out)); err != nil {
+		fmt.Fprint(r.out, condition)
+	}
+	fmt.Fprintln(r.out)
+}
+
+// Incomplete reports whether text ends in the middle of a form, a string, a
+// symbol quoted with bars or a block comment, that is, whether more lines
+// are needed to read it.
+func Incomplete(text string) bool {
+	rs := []rune(text)
+	depth := 0
+	quoted := false
+	for i := 0; i < len(rs); i++ {
+		next := rune(0)
+		if i+1 < len(rs) {
+			next = rs[i+1]
+		}
+		switch {
+		case rs[i] == ';':
+			for i < len(rs) && rs[i] != '\n' {
+				i++
+			}
+		case rs[i] == '#' && next == '|':
+			j := i + 2
+			for j+1 < len(rs) && !(rs[j] == '|' && rs[j+1] == '#') {
+				j++
+			}
+			if j+1 >= len(rs) {
+				return true
+			}
+			i = j + 1
+		case rs[i] == '#' && next == '\\':
+			i += 2
+			quoted = false
+		case rs[i] == '#' && next == '\'':
+			i++
+			quoted = true
+		case rs[i] == '"' || rs[i] == '|':
+			j := i + 1
+			for j < len(rs) && rs[j] != rs[i] {
+				if rs[j] == '\\' {
+					j++
+				}
+				j++
+			}
+			if j >= len(rs) {
+				return true
+			}
+			i = j
+			quoted = false
+		case rs[i] == '\'' || rs[i] == '`' || rs[i] == ',':
+			if next == '@' {
+				i++
+			}
+			quoted = true
+		case rs[i] == '(':
+			depth++
+			quoted = false
+		case rs[i] == ')':
+			if depth > 0 {
+				depth--
+			}
+			quoted = false
+		case unicode.IsSpace(rs[i]):
+		default:
+			quoted = false
+		}
+	}
+	return depth > 0 || quoted
+}
+
+// Complete returns the names of the functions, variables and classes which
+// start with the word before pos in line, and the index where the word starts.
+// The names are in lower case unless the word has an upper case letter.
+func Complete(line []rune, pos int) ([]string, int) {
+	start := pos
+	for start > 0 && !unicode.IsSpace(line[start-1]) && !strings.ContainsRune("()'`,\";", line[start-1]) {
+		start--
+	}
+	word := string(line[start:pos])
+	if word == "" {
+		return nil, start
+	}
+	lower := strings.ToLower(word) == word
+	prefix := strings.ToUpper(word)
+	seen := map[string]bool{}
+	candidates := []string{}
+	for _, namespace := range []map[ilos.Instance]ilos.Instance{
+		runtime.TopLevel.Function[0],
+		runtime.TopLevel.Special[0],
+		runtime.TopLevel.Macro[0],
+		runtime.TopLevel.Variable[0],
+		runtime.TopLevel.Class[0],
+	} {
+		for symbol := range namespace {
+			name := symbol.String()
+			if !strings.HasPrefix(name, prefix) || seen[name] {
+				continue
+			}
+			seen[name] = true
+			if lower {
+				name = strings.ToLower(name)
+			}
+			candidates = append(candidates, name)
+		}
+	}
+	sort.Strings(candidates)
+	return candidates, start
+}
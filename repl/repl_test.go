@@ -0,0 +1,82 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package repl
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestIncomplete(t *testing.T) {
+	tests := []struct {
+		text string
+		want bool
+	}{
+		{"(+ 1 2)\n", false},
+		{"(+ 1\n", true},
+		{"(+ 1 2))\n", false},
+		{"\"(\"\n", false},
+		{"\"abc\n", true},
+		{"\"a\\\"b\"\n", false},
+		{"|a(b|\n", false},
+		{"#\\(\n", false},
+		{"(list #\\) 1\n", true},
+		{"; (\n", false},
+		{"#| ( |# 1\n", false},
+		{"#| (\n", true},
+		{"'\n", true},
+		{"'a\n", false},
+		{",@\n", true},
+		{"#'\n", true},
+		{"#(1 2\n", true},
+	}
+	for _, tt := range tests {
+		if got := Incomplete(tt.text); got != tt.want {
+			t.Errorf("Incomplete(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+}
+
+func TestComplete(t *testing.T) {
+	tests := []struct {
+		line      string
+		want      []string
+		wantStart int
+	}{
+		{"(car", []string{"car"}, 1},
+		{"(CAR", []string{"CAR"}, 1},
+		{"(list *pi", []string{"*pi*"}, 6},
+		{"(class <simple-e", []string{"<simple-error>"}, 7},
+		{"(defu", []string{"defun"}, 1},
+		{"(", nil, 1},
+	}
+	for _, tt := range tests {
+		line := []rune(tt.line)
+		got, start := Complete(line, len(line))
+		if !reflect.DeepEqual(got, tt.want) || start != tt.wantStart {
+			t.Errorf("Complete(%q) = %q, %v, want %q, %v", tt.line, got, start, tt.want, tt.wantStart)
+		}
+	}
+}
+
+func TestEval(t *testing.T) {
+	var out bytes.Buffer
+	r := New(strings.NewReader(""), &out, nil)
+	if _, ok := r.evalTopLevel("(+ 1 2) (list 4)\n(list * ** ***)\n"); ok {
+		t.Fatal("evalTopLevel() exited")
+	}
+	if _, ok := r.evalTopLevel(")"); ok {
+		t.Fatal("evalTopLevel() exited")
+	}
+	if status, ok := r.evalTopLevel("(exit 2) 3"); !ok || status != 2 {
+		t.Errorf("evalTopLevel() exit = %v, %v, want 2, true", status, ok)
+	}
+	want := "3\n(4)\n((4) 3 NIL)\nCannot parse \")\" as <OBJECT>.\n"
+	if out.String() != want {
+		t.Errorf("evalTopLevel() printed %q, want %q", out.String(), want)
+	}
+}
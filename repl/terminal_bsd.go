@@ -0,0 +1,13 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+// +build darwin dragonfly freebsd netbsd openbsd
+
+package repl
+
+import "syscall"
+
+const ioctlGetTermios = syscall.TIOCGETA
+const ioctlSetTermios = syscall.TIOCSETA
@@ -0,0 +1,22 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+//go:build !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd
+// +build !darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd
+
+package repl
+
+import "errors"
+
+var errNotTerminal = errors.New("terminal is not supported")
+
+// IsTerminal reports whether fd refers to a terminal, which is never the case
+// on this platform.
+func IsTerminal(fd uintptr) bool {
+	return false
+}
+
+func makeRaw(fd uintptr) (func(), error) {
+	return nil, errNotTerminal
+}
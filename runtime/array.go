@@ -57,14 +57,14 @@ func CreateArray(e env.Environment, dimensions ilos.Instance, initialElement ...
 		if err != nil {
 			return nil, err
 		}
-		if err := ensure(e, class.Integer, elt); err != nil {
-			return nil, err
+		if !ilos.InstanceOf(class.Integer, elt) || int(elt.(instance.Integer)) < 0 {
+			return SignalCondition(e, instance.NewDomainError(e, elt, class.Integer), Nil)
 		}
 	}
 	// set the initial element
 	elt := Nil
 	if len(initialElement) > 1 {
-		return SignalCondition(e, instance.NewArityError(e), Nil)
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("CREATE-ARRAY"), 1, 2, 1+len(initialElement)), Nil)
 	}
 	if len(initialElement) == 1 {
 		elt = initialElement[0]
@@ -136,20 +136,20 @@ func Aref(e env.Environment, basicArray ilos.Instance, dimensions ...ilos.Instan
 	switch {
 	case ilos.InstanceOf(class.String, basicArray):
 		if len(dimensions) != 1 {
-			return SignalCondition(e, instance.NewArityError(e), Nil)
+			return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("AREF"), 2, 2, 1+len(dimensions)), Nil)
 		}
 		index := int(dimensions[0].(instance.Integer))
-		if len(basicArray.(instance.String)) <= index {
-			return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
+		if index < 0 || len(basicArray.(instance.String)) <= index {
+			return SignalCondition(e, instance.NewIndexOutOfRange(e, basicArray, dimensions[0]), Nil)
 		}
 		return instance.NewCharacter(basicArray.(instance.String)[index]), nil
 	case ilos.InstanceOf(class.GeneralVector, basicArray):
 		if len(dimensions) != 1 {
-			return SignalCondition(e, instance.NewArityError(e), Nil)
+			return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("AREF"), 2, 2, 1+len(dimensions)), Nil)
 		}
 		index := int(dimensions[0].(instance.Integer))
-		if len(basicArray.(instance.GeneralVector)) <= index {
-			return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
+		if index < 0 || len(basicArray.(instance.GeneralVector)) <= index {
+			return SignalCondition(e, instance.NewIndexOutOfRange(e, basicArray, dimensions[0]), Nil)
 		}
 		return basicArray.(instance.GeneralVector)[index], nil
 	default: // General Array*
@@ -169,14 +169,14 @@ func Garef(e env.Environment, generalArray ilos.Instance, dimensions ...ilos.Ins
 	}
 	if len(dimensions) == 0 {
 		if generalArray.(*instance.GeneralArrayStar).Scalar == nil {
-			return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
+			return SignalCondition(e, instance.NewIndexOutOfRange(e, generalArray, Nil), Nil)
 		}
 		return generalArray.(*instance.GeneralArrayStar).Scalar, nil
 	}
 	array := generalArray.(*instance.GeneralArrayStar)
 	index := int(dimensions[0].(instance.Integer))
-	if array.Vector == nil || len(array.Vector) <= index {
-		return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
+	if array.Vector == nil || index < 0 || len(array.Vector) <= index {
+		return SignalCondition(e, instance.NewIndexOutOfRange(e, generalArray, dimensions[0]), Nil)
 	}
 	return Garef(e, array.Vector[index], dimensions[1:]...)
 }
@@ -197,21 +197,21 @@ func SetAref(e env.Environment, obj, basicArray ilos.Instance, dimensions ...ilo
 			return nil, err
 		}
 		if len(dimensions) != 1 {
-			return SignalCondition(e, instance.NewArityError(e), Nil)
+			return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("SET-AREF"), 3, 3, 2+len(dimensions)), Nil)
 		}
 		index := int(dimensions[0].(instance.Integer))
-		if len(basicArray.(instance.String)) <= index {
-			return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
+		if index < 0 || len(basicArray.(instance.String)) <= index {
+			return SignalCondition(e, instance.NewIndexOutOfRange(e, basicArray, dimensions[0]), Nil)
 		}
 		basicArray.(instance.String)[index] = rune(obj.(instance.Character))
 		return obj, nil
 	case ilos.InstanceOf(class.GeneralVector, basicArray):
 		if len(dimensions) != 1 {
-			return SignalCondition(e, instance.NewArityError(e), Nil)
+			return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("SET-AREF"), 3, 3, 2+len(dimensions)), Nil)
 		}
 		index := int(dimensions[0].(instance.Integer))
-		if len(basicArray.(instance.GeneralVector)) <= index {
-			return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
+		if index < 0 || len(basicArray.(instance.GeneralVector)) <= index {
+			return SignalCondition(e, instance.NewIndexOutOfRange(e, basicArray, dimensions[0]), Nil)
 		}
 		basicArray.(instance.GeneralVector)[index] = obj
 		return obj, nil
@@ -232,15 +232,15 @@ func SetGaref(e env.Environment, obj, generalArray ilos.Instance, dimensions ...
 	}
 	if len(dimensions) == 0 {
 		if generalArray.(*instance.GeneralArrayStar).Scalar == nil {
-			return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
+			return SignalCondition(e, instance.NewIndexOutOfRange(e, generalArray, Nil), Nil)
 		}
 		generalArray.(*instance.GeneralArrayStar).Scalar = obj
 		return obj, nil
 	}
 	array := generalArray.(*instance.GeneralArrayStar)
 	index := int(dimensions[0].(instance.Integer))
-	if array.Vector == nil || len(array.Vector) <= index {
-		return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
+	if array.Vector == nil || index < 0 || len(array.Vector) <= index {
+		return SignalCondition(e, instance.NewIndexOutOfRange(e, generalArray, dimensions[0]), Nil)
 	}
 	return SetGaref(e, obj, array.Vector[index], dimensions[1:]...)
 }
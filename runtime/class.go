@@ -18,15 +18,32 @@ func ClassOf(e env.Environment, obj ilos.Instance) (ilos.Instance, ilos.Instance
 	return obj.Class(), nil
 }
 
-func Instancep(e env.Environment, obj ilos.Instance, class ilos.Class) (ilos.Instance, ilos.Instance) {
-	if ilos.InstanceOf(class, obj) {
+// ensureClass signals a domain-error unless each of objs is a class.
+func ensureClass(e env.Environment, objs ...ilos.Instance) ilos.Instance {
+	for _, obj := range objs {
+		if _, ok := obj.(ilos.Class); !ok {
+			_, err := SignalCondition(e, instance.NewDomainError(e, obj, class.StandardClass), Nil)
+			return err
+		}
+	}
+	return nil
+}
+
+func Instancep(e env.Environment, obj, c ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensureClass(e, c); err != nil {
+		return nil, err
+	}
+	if ilos.InstanceOf(c.(ilos.Class), obj) {
 		return T, nil
 	}
 	return Nil, nil
 }
 
-func Subclassp(e env.Environment, class1, class2 ilos.Class) (ilos.Instance, ilos.Instance) {
-	if ilos.SubclassOf(class1, class2) {
+func Subclassp(e env.Environment, class1, class2 ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensureClass(e, class1, class2); err != nil {
+		return nil, err
+	}
+	if ilos.SubclassOf(class1.(ilos.Class), class2.(ilos.Class)) {
 		return T, nil
 	}
 	return Nil, nil
@@ -75,7 +92,7 @@ func Defclass(e env.Environment, className, scNames, slotSpecs ilos.Instance, cl
 		}
 		for _, before := range supers {
 			if checkSuperClass(before, super) {
-				return SignalCondition(e, instance.NewArityError(e), Nil)
+				return SignalCondition(e, instance.NewProgramError(e), Nil)
 			}
 		}
 		supers = append(supers, super.(ilos.Class))
@@ -107,6 +124,7 @@ func Defclass(e env.Environment, className, scNames, slotSpecs ilos.Instance, cl
 	}
 	metaclass := class.StandardClass
 	abstractp := Nil
+	var documentation ilos.Instance
 	for _, classOpt := range classOpts {
 		var err ilos.Instance
 		switch classOpt.(*instance.Cons).Car {
@@ -118,10 +136,15 @@ func Defclass(e env.Environment, className, scNames, slotSpecs ilos.Instance, cl
 			if abstractp, err = Eval(e, classOpt.(instance.List).Nth(1)); err != nil {
 				return nil, err
 			}
+		case instance.NewSymbol(":DOCUMENTATION"):
+			if documentation, err = optionalDocumentation(e, "DEFCLASS", 0, classOpt.(*instance.Cons).Cdr.(instance.List).Slice()); err != nil {
+				return nil, err
+			}
 		}
 	}
 	classObject := instance.NewStandardClass(className, supers, slots, initforms, initargs, metaclass, abstractp)
 	e.Class[:1].Define(className, classObject)
+	document(e, className, "CLASS", documentation)
 	for _, slotSpec := range slotSpecs.(instance.List).Slice() {
 		if ilos.InstanceOf(class.Symbol, slotSpec) {
 			continue
@@ -151,7 +174,7 @@ func Defclass(e env.Environment, className, scNames, slotSpecs ilos.Instance, cl
 				Defgeneric(e, readerFunctionName, lambdaList)
 			}
 			fun, _ := e.Function.Get(readerFunctionName)
-			fun.(*instance.GenericFunction).AddMethod(nil, lambdaList, []ilos.Class{classObject}, instance.NewFunction(readerFunctionName, func(e env.Environment, object ilos.Instance) (ilos.Instance, ilos.Instance) {
+			untraced(fun).(*instance.GenericFunction).AddMethod(nil, lambdaList, []ilos.Class{classObject}, instance.NewFunction(readerFunctionName, func(e env.Environment, object ilos.Instance) (ilos.Instance, ilos.Instance) {
 				slot, ok := object.(instance.Instance).GetSlotValue(slotName, classObject)
 				if ok {
 					return slot, nil
@@ -168,8 +191,8 @@ func Defclass(e env.Environment, className, scNames, slotSpecs ilos.Instance, cl
 				Defgeneric(e, writerFunctionName, lambdaList)
 			}
 			fun, _ := e.Function.Get(writerFunctionName)
-			fun.(*instance.GenericFunction).AddMethod(nil, lambdaList, []ilos.Class{class.Object, classObject}, instance.NewFunction(writerFunctionName, func(e env.Environment, obj, object ilos.Instance) (ilos.Instance, ilos.Instance) {
-				ok := object.(instance.Instance).SetSlotValue(obj, slotName, classObject)
+			untraced(fun).(*instance.GenericFunction).AddMethod(nil, lambdaList, []ilos.Class{class.Object, classObject}, instance.NewFunction(writerFunctionName, func(e env.Environment, obj, object ilos.Instance) (ilos.Instance, ilos.Instance) {
+				ok := object.(instance.Instance).SetSlotValue(slotName, obj, classObject)
 				if ok {
 					return obj, nil
 				}
@@ -185,7 +208,7 @@ func Defclass(e env.Environment, className, scNames, slotSpecs ilos.Instance, cl
 				Defgeneric(e, boundpFunctionName, lambdaList)
 			}
 			fun, _ := e.Function.Get(boundpFunctionName)
-			fun.(*instance.GenericFunction).AddMethod(nil, lambdaList, []ilos.Class{classObject}, instance.NewFunction(boundpFunctionName, func(e env.Environment, object ilos.Instance) (ilos.Instance, ilos.Instance) {
+			untraced(fun).(*instance.GenericFunction).AddMethod(nil, lambdaList, []ilos.Class{classObject}, instance.NewFunction(boundpFunctionName, func(e env.Environment, object ilos.Instance) (ilos.Instance, ilos.Instance) {
 				_, ok := object.(instance.Instance).GetSlotValue(slotName, classObject)
 				if ok {
 					return T, nil
@@ -213,7 +236,7 @@ func InitializeObject(e env.Environment, object ilos.Instance, inits ...ilos.Ins
 
 func Defmethod(e env.Environment, arguments ...ilos.Instance) (ilos.Instance, ilos.Instance) {
 	if len(arguments) < 2 {
-		return SignalCondition(e, instance.NewArityError(e), Nil)
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("DEFMETHOD"), 2, -1, len(arguments)), Nil)
 	}
 	name := arguments[0]
 	var qualifier ilos.Instance
@@ -258,14 +281,14 @@ func Defmethod(e env.Environment, arguments ...ilos.Instance) (ilos.Instance, il
 	if !ok {
 		return SignalCondition(e, instance.NewUndefinedFunction(e, name), Nil)
 	}
-	if !gen.(*instance.GenericFunction).AddMethod(qualifier, lambdaList, classList, fun) {
+	if !untraced(gen).(*instance.GenericFunction).AddMethod(qualifier, lambdaList, classList, fun) {
 		return SignalCondition(e, instance.NewUndefinedFunction(e, name), Nil)
 	}
 	return name, nil
 }
 
 func Defgeneric(e env.Environment, funcSpec, lambdaList ilos.Instance, optionsOrMethodDescs ...ilos.Instance) (ilos.Instance, ilos.Instance) {
-	var methodCombination ilos.Instance
+	var methodCombination, documentation ilos.Instance
 	genericFunctionClass := class.StandardGenericFunction
 	forms := []ilos.Instance{}
 	for _, optionOrMethodDesc := range optionsOrMethodDescs {
@@ -280,6 +303,11 @@ func Defgeneric(e env.Environment, funcSpec, lambdaList ilos.Instance, optionsOr
 			genericFunctionClass = class.(ilos.Class)
 		case instance.NewSymbol(":METHOD"):
 			forms = append(forms, instance.NewCons(instance.NewSymbol("DEFMETHOD"), optionOrMethodDesc.(instance.List).NthCdr(1)))
+		case instance.NewSymbol(":DOCUMENTATION"):
+			var err ilos.Instance
+			if documentation, err = optionalDocumentation(e, "DEFGENERIC", 0, optionOrMethodDesc.(*instance.Cons).Cdr.(instance.List).Slice()); err != nil {
+				return nil, err
+			}
 		}
 	}
 	e.Function[:1].Define(
@@ -293,6 +321,7 @@ func Defgeneric(e env.Environment, funcSpec, lambdaList ilos.Instance, optionsOr
 			genericFunctionClass,
 		),
 	)
+	document(e, instance.NewSymbol(fmt.Sprint(funcSpec)), "FUNCTION", documentation)
 	Progn(e, forms...)
 	return funcSpec, nil
 }
@@ -5,6 +5,8 @@
 package runtime
 
 import (
+	"strings"
+
 	"github.com/islisp-dev/iris/runtime/env"
 	"github.com/islisp-dev/iris/runtime/ilos"
 	"github.com/islisp-dev/iris/runtime/ilos/class"
@@ -16,6 +18,16 @@ func SignalCondition(e env.Environment, condition, continuable ilos.Instance) (i
 		return nil, err
 	}
 	condition.(instance.Instance).SetSlotValue(instance.NewSymbol("IRIS.CONTINUABLE"), continuable, class.SeriousCondition)
+	if continuable != Nil {
+		report := Nil
+		if ilos.InstanceOf(class.String, continuable) {
+			report = continuable
+		}
+		function := instance.NewFunction(instance.NewSymbol("CONTINUE"), func(e env.Environment, value ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+			return ContinueCondition(e, condition, value...)
+		})
+		e = pushRestarts(e, instance.NewRestart(instance.NewSymbol("CONTINUE"), report, function, Nil, condition))
+	}
 	_, c := e.Handler.(instance.Applicable).Apply(e, condition)
 	if ilos.InstanceOf(class.Continue, c) {
 		o, _ := c.(instance.Instance).GetSlotValue(instance.NewSymbol("IRIS.OBJECT"), class.Continue)
@@ -29,7 +41,7 @@ func Cerror(e env.Environment, continueString, errorString ilos.Instance, objs .
 	if err != nil {
 		return nil, err
 	}
-	condition := instance.Create(e, class.SimpleError, instance.NewSymbol("FORMAT-STRING"), errorString, instance.NewSymbol("FORAMT-OBJECTS"), arguments)
+	condition := instance.NewSimpleError(e, errorString, arguments)
 	ss, err := CreateStringOutputStream(e)
 	if err != nil {
 		return nil, err
@@ -44,35 +56,367 @@ func Cerror(e env.Environment, continueString, errorString ilos.Instance, objs .
 	return SignalCondition(e, condition, continuable)
 }
 
-func Error(e env.Environment, continueString, errorString ilos.Instance, objs ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+func Error(e env.Environment, errorString ilos.Instance, objs ...ilos.Instance) (ilos.Instance, ilos.Instance) {
 	arguments, err := List(e, objs...)
 	if err != nil {
 		return nil, err
 	}
-	condition := instance.Create(e, class.SimpleError, instance.NewSymbol("FORMAT-STRING"), errorString, instance.NewSymbol("FORAMT-OBJECTS"), arguments)
+	condition := instance.NewSimpleError(e, errorString, arguments)
 	return SignalCondition(e, condition, Nil)
 }
 
-func IgnoreError(e env.Environment, forms ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+// handle evaluates forms with a handler which escapes to this call when a
+// condition of one of classes is signaled. It returns the index of the class
+// which matched and the condition, or -1 if the forms exited without them.
+func handle(e env.Environment, classes []ilos.Class, forms ...ilos.Instance) (ilos.Instance, int, ilos.Instance, ilos.Instance) {
+	uid := instance.NewInteger(uniqueInt())
+	outer := e.Handler
+	e.Handler = instance.NewFunction(instance.NewSymbol("IRIS.HANDLER"), func(e env.Environment, condition ilos.Instance) (ilos.Instance, ilos.Instance) {
+		for i, c := range classes {
+			if ilos.InstanceOf(c, condition) {
+				return nil, newEscape(i, uid, condition)
+			}
+		}
+		e.Handler = outer
+		return outer.(instance.Applicable).Apply(e, condition)
+	})
 	ret, err := Progn(e, forms...)
-	if err != nil && ilos.InstanceOf(class.Error, err) {
+	if index, condition, ok := escaped(err, uid); ok {
+		return nil, index, condition, nil
+	}
+	return ret, -1, nil, err
+}
+
+// IgnoreErrors evaluates forms like progn. If an error is signaled during the
+// evaluation, the control is transferred to the ignore-errors form and nil is
+// returned.
+func IgnoreErrors(e env.Environment, forms ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	ret, index, _, err := handle(e, []ilos.Class{class.Error}, forms...)
+	if index >= 0 {
 		return Nil, nil
 	}
 	return ret, err
 }
 
-func ReportCondition(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
-	return Format(e, e.StandardOutput, instance.NewString([]rune("~A")), condition)
+// HandlerCase evaluates form with handlers established by clauses. Each clause
+// has the form (class-name (var) form*) or (class-name () form*). When a
+// condition which is an instance of the class named by class-name is
+// signaled, the control is transferred to the first such clause, the
+// condition is bound to var and the forms of the clause are evaluated as
+// progn. Conditions matching no clause are passed to the outer handler.
+func HandlerCase(e env.Environment, form ilos.Instance, clauses ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	classes := []ilos.Class{}
+	for _, clause := range clauses {
+		if err := ensure(e, class.Cons, clause); err != nil {
+			return nil, err
+		}
+		if err := ensure(e, class.List, clause.(*instance.Cons).Cdr); err != nil {
+			return nil, err
+		}
+		if clause.(instance.List).Length() < 2 {
+			return SignalCondition(e, instance.NewProgramError(e), Nil)
+		}
+		c, err := Class(e, clause.(*instance.Cons).Car)
+		if err != nil {
+			return nil, err
+		}
+		if err := ensure(e, class.List, clause.(instance.List).Nth(1)); err != nil {
+			return nil, err
+		}
+		if clause.(instance.List).Nth(1).(instance.List).Length() > 1 {
+			return SignalCondition(e, instance.NewProgramError(e), Nil)
+		}
+		classes = append(classes, c)
+	}
+	ret, index, condition, err := handle(e, classes, form)
+	if index < 0 {
+		return ret, err
+	}
+	clause := clauses[index].(instance.List).Slice()
+	lexical := e.NewLexical()
+	if vars := clause[1].(instance.List); vars.Length() == 1 {
+		if !lexical.Variable.Define(vars.Nth(0), condition) {
+			return SignalCondition(e, instance.NewImmutableBinding(e, vars.Nth(0)), Nil)
+		}
+	}
+	return Progn(lexical, clause[2:]...)
 }
 
 func ConditionContinuable(e env.Environment, condition ilos.Instance) (ilos.Instance, ilos.Instance) {
-	if continuable, ok := condition.(instance.Instance).GetSlotValue(instance.NewSymbol("IRIS.CONTINUABLE"), class.SeriousCondition); ok {
-		return continuable, nil
+	return conditionSlot(e, condition, "IRIS.CONTINUABLE", class.SeriousCondition)
+}
+
+// conditionSlot returns the value of the slot name of condition which is
+// defined by c, or nil if the slot is unbound. An error shall be signaled if
+// condition is not an instance of c (error-id. domain-error).
+func conditionSlot(e env.Environment, condition ilos.Instance, name string, c ilos.Class) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, c, condition); err != nil {
+		return nil, err
+	}
+	if value, ok := condition.(instance.Instance).GetSlotValue(instance.NewSymbol(name), c); ok {
+		return value, nil
+	}
+	return Nil, nil
+}
+
+// SimpleErrorFormatString returns the format string of simple-error.
+func SimpleErrorFormatString(e env.Environment, simpleError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, simpleError, "FORMAT-STRING", class.SimpleError)
+}
+
+// SimpleErrorFormatArguments returns the list of the format arguments of
+// simple-error.
+func SimpleErrorFormatArguments(e env.Environment, simpleError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, simpleError, "FORMAT-ARGUMENTS", class.SimpleError)
+}
+
+// ArithmeticErrorOperation returns the function which was being executed when
+// arithmetic-error was signaled.
+func ArithmeticErrorOperation(e env.Environment, arithmeticError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, arithmeticError, "OPERATION", class.ArithmeticError)
+}
+
+// ArithmeticErrorOperands returns the list of the operands of the operation
+// which signaled arithmetic-error.
+func ArithmeticErrorOperands(e env.Environment, arithmeticError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, arithmeticError, "OPERANDS", class.ArithmeticError)
+}
+
+// DomainErrorObject returns the object which caused domain-error.
+func DomainErrorObject(e env.Environment, domainError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, domainError, "IRIS.OBJECT", class.DomainError)
+}
+
+// DomainErrorExpectedClass returns the class which the object which caused
+// domain-error was expected to be an instance of.
+func DomainErrorExpectedClass(e env.Environment, domainError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, domainError, "EXPECTED-CLASS", class.DomainError)
+}
+
+// ParseErrorString returns the string which caused parse-error.
+func ParseErrorString(e env.Environment, parseError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, parseError, "STRING", class.ParseError)
+}
+
+// ParseErrorExpectedClass returns the class which the string was expected to
+// be parsed as.
+func ParseErrorExpectedClass(e env.Environment, parseError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, parseError, "EXPECTED-CLASS", class.ParseError)
+}
+
+// StreamErrorStream returns the stream which caused stream-error, or nil if
+// the error was not caused by a stream.
+func StreamErrorStream(e env.Environment, streamError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, streamError, "STREAM", class.StreamError)
+}
+
+// UndefinedEntityName returns the name of the entity which was undefined.
+func UndefinedEntityName(e env.Environment, undefinedEntity ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, undefinedEntity, "NAME", class.UndefinedEntity)
+}
+
+// UndefinedEntityNamespace returns the namespace (one of the symbols VARIABLE,
+// FUNCTION and CLASS) in which the entity was undefined.
+func UndefinedEntityNamespace(e env.Environment, undefinedEntity ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, undefinedEntity, "NAMESPACE", class.UndefinedEntity)
+}
+
+// ArityErrorName returns the name of the function which was called with a
+// wrong number of arguments.
+func ArityErrorName(e env.Environment, arityError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, arityError, "NAME", class.ArityError)
+}
+
+// ArityErrorExpectedMin returns the minimum number of arguments which the
+// function accepts.
+func ArityErrorExpectedMin(e env.Environment, arityError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, arityError, "EXPECTED-MIN", class.ArityError)
+}
+
+// ArityErrorExpectedMax returns the maximum number of arguments which the
+// function accepts, or nil if it accepts any number of arguments.
+func ArityErrorExpectedMax(e env.Environment, arityError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, arityError, "EXPECTED-MAX", class.ArityError)
+}
+
+// ArityErrorActual returns the number of arguments which were given.
+func ArityErrorActual(e env.Environment, arityError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, arityError, "ACTUAL", class.ArityError)
+}
+
+// IndexOutOfRangeSequence returns the sequence or array which was accessed.
+func IndexOutOfRangeSequence(e env.Environment, indexOutOfRange ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, indexOutOfRange, "SEQUENCE", class.IndexOutOfRange)
+}
+
+// IndexOutOfRangeIndex returns the index which was out of range.
+func IndexOutOfRangeIndex(e env.Environment, indexOutOfRange ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, indexOutOfRange, "INDEX", class.IndexOutOfRange)
+}
+
+// ImmutableBindingName returns the name whose binding could not be
+// established or modified.
+func ImmutableBindingName(e env.Environment, immutableBinding ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, immutableBinding, "NAME", class.ImmutableBinding)
+}
+
+// InternalErrorName returns the name of the builtin which failed.
+func InternalErrorName(e env.Environment, internalError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, internalError, "NAME", class.InternalError)
+}
+
+// InternalErrorMessage returns the message describing how the builtin failed.
+func InternalErrorMessage(e env.Environment, internalError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, internalError, "MESSAGE", class.InternalError)
+}
+
+// FileErrorPathname returns the name of the file which could not be operated
+// on.
+func FileErrorPathname(e env.Environment, fileError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, fileError, "PATHNAME", class.FileError)
+}
+
+// FileErrorMessage returns the message of the operating system describing why
+// the operation failed.
+func FileErrorMessage(e env.Environment, fileError ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return conditionSlot(e, fileError, "MESSAGE", class.FileError)
+}
+
+// ReportCondition is the generic function which writes a human readable
+// description of condition to stream. The methods for the built-in condition
+// classes are defined by the report functions below, and users may define
+// methods for their own condition classes.
+func ReportCondition(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	fun, ok := e.Function.Get(instance.NewSymbol("REPORT-CONDITION"))
+	if !ok {
+		return SignalCondition(e, instance.NewUndefinedFunction(e, instance.NewSymbol("REPORT-CONDITION")), Nil)
+	}
+	return fun.(instance.Applicable).Apply(e.NewDynamic(), condition, stream)
+}
+
+func report(e env.Environment, stream ilos.Instance, formatString string, objs ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if _, err := Format(e, stream, instance.NewString([]rune(formatString)), objs...); err != nil {
+		return nil, err
 	}
 	return Nil, nil
 }
 
+func reportSeriousCondition(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return report(e, stream, "A condition of class ~A was signaled.", condition.Class())
+}
+
+func reportError(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return report(e, stream, "An error of class ~A was signaled.", condition.Class())
+}
+
+func reportSimpleError(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	formatString, _ := SimpleErrorFormatString(e, condition)
+	formatArguments, _ := SimpleErrorFormatArguments(e, condition)
+	if !ilos.InstanceOf(class.String, formatString) || !isProperList(formatArguments) {
+		return reportError(e, condition, stream)
+	}
+	return report(e, stream, string(formatString.(instance.String)), formatArguments.(instance.List).Slice()...)
+}
+
+func reportArithmeticError(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	operation, _ := ArithmeticErrorOperation(e, condition)
+	operands, _ := ArithmeticErrorOperands(e, condition)
+	return report(e, stream, "Arithmetic error in ~A with operands ~S.", operation, operands)
+}
+
+func reportDivisionByZero(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	operation, _ := ArithmeticErrorOperation(e, condition)
+	operands, _ := ArithmeticErrorOperands(e, condition)
+	return report(e, stream, "Division by zero in ~A with operands ~S.", operation, operands)
+}
+
+func reportDomainError(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	object, _ := DomainErrorObject(e, condition)
+	if expected, _ := conditionSlot(e, condition, "IRIS.EXPECTED", class.DomainError); expected != Nil {
+		return report(e, stream, "~S is not one of ~S.", object, expected)
+	}
+	expectedClass, _ := DomainErrorExpectedClass(e, condition)
+	return report(e, stream, "~S is not an instance of ~A.", object, expectedClass)
+}
+
+func reportParseError(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	str, _ := ParseErrorString(e, condition)
+	expectedClass, _ := ParseErrorExpectedClass(e, condition)
+	return report(e, stream, "Cannot parse ~S as ~A.", str, expectedClass)
+}
+
+func reportUndefinedEntity(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	name, _ := UndefinedEntityName(e, condition)
+	namespace, _ := UndefinedEntityNamespace(e, condition)
+	return report(e, stream, "The ~A ~A is undefined.", instance.NewString([]rune(strings.ToLower(namespace.String()))), name)
+}
+
+func reportStreamError(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	s, _ := StreamErrorStream(e, condition)
+	if s == Nil {
+		return report(e, stream, "A stream error was signaled.")
+	}
+	return report(e, stream, "A stream error was signaled on ~A.", s)
+}
+
+func reportEndOfStream(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	s, _ := StreamErrorStream(e, condition)
+	if s == Nil {
+		return report(e, stream, "Unexpected end of stream.")
+	}
+	return report(e, stream, "Unexpected end of stream on ~A.", s)
+}
+
+func reportArityError(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	name, _ := ArityErrorName(e, condition)
+	min, _ := ArityErrorExpectedMin(e, condition)
+	max, _ := ArityErrorExpectedMax(e, condition)
+	actual, _ := ArityErrorActual(e, condition)
+	switch {
+	case max == Nil:
+		return report(e, stream, "~A expects at least ~A arguments but was given ~A.", name, min, actual)
+	case min == max:
+		return report(e, stream, "~A expects ~A arguments but was given ~A.", name, min, actual)
+	default:
+		return report(e, stream, "~A expects ~A to ~A arguments but was given ~A.", name, min, max, actual)
+	}
+}
+
+func reportIndexOutOfRange(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	sequence, _ := IndexOutOfRangeSequence(e, condition)
+	index, _ := IndexOutOfRangeIndex(e, condition)
+	return report(e, stream, "The index ~A is out of range for ~S.", index, sequence)
+}
+
+func reportImmutableBinding(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	name, _ := ImmutableBindingName(e, condition)
+	return report(e, stream, "The binding of ~A is immutable.", name)
+}
+
+func reportInternalError(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	name, _ := InternalErrorName(e, condition)
+	message, _ := InternalErrorMessage(e, condition)
+	return report(e, stream, "Internal error in ~A: ~A", name, message)
+}
+
+func reportFileError(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	pathname, _ := FileErrorPathname(e, condition)
+	message, _ := FileErrorMessage(e, condition)
+	return report(e, stream, "File error on ~S: ~A.", pathname, message)
+}
+
+func reportControlError(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return report(e, stream, "Control was transferred to an invalid destination.")
+}
+
+func reportStorageExhausted(e env.Environment, condition, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return report(e, stream, "Storage is exhausted.")
+}
+
 func ContinueCondition(e env.Environment, condition ilos.Instance, value ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.SeriousCondition, condition); err != nil {
+		return nil, err
+	}
 	if b, ok := condition.(instance.Instance).GetSlotValue(instance.NewSymbol("IRIS.CONTINUABLE"), class.SeriousCondition); !ok || b == Nil {
 		return nil, instance.Create(e, class.ProgramError)
 	}
@@ -90,7 +434,16 @@ func WithHandler(e env.Environment, handler ilos.Instance, forms ...ilos.Instanc
 	if err != nil {
 		return nil, err
 	}
-	e.Handler = fun
+	if err := ensure(e, class.Function, fun); err != nil {
+		return nil, err
+	}
+	// The handler is called in the dynamic environment of the signal, except
+	// that the handler active is the one outside this form.
+	outer := e.Handler
+	e.Handler = instance.NewFunction(instance.NewSymbol("IRIS.HANDLER"), func(e env.Environment, condition ilos.Instance) (ilos.Instance, ilos.Instance) {
+		e.Handler = outer
+		return fun.(instance.Applicable).Apply(e, condition)
+	})
 	ret, err := Progn(e, forms...)
 	if err != nil {
 		return nil, err
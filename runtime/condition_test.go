@@ -4,7 +4,13 @@
 
 package runtime
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/islisp-dev/iris/runtime/env"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
 
 func TestSignalCondition(t *testing.T) {
 	tests := []test{
@@ -35,3 +41,259 @@ func TestSignalCondition(t *testing.T) {
 	}
 	execTests(t, SignalCondition, tests)
 }
+
+func TestIgnoreErrors(t *testing.T) {
+	tests := []test{
+		{
+			exp:     `(ignore-errors (+ 1 2))`,
+			want:    `3`,
+			wantErr: false,
+		},
+		{
+			exp:     `(ignore-errors (error "err") 1)`,
+			want:    `nil`,
+			wantErr: false,
+		},
+		{
+			exp:     `(ignore-errors (car 1))`,
+			want:    `nil`,
+			wantErr: false,
+		},
+		{
+			exp:     `(catch 'foo (ignore-errors (throw 'foo 1)) 2)`,
+			want:    `1`,
+			wantErr: false,
+		},
+		{
+			exp:     `(labels ((f () (car 1)) (g () (f))) (ignore-errors (g) 1))`,
+			want:    `nil`,
+			wantErr: false,
+		},
+	}
+	execTests(t, IgnoreErrors, tests)
+}
+
+func TestHandlerCase(t *testing.T) {
+	tests := []test{
+		{
+			exp:     `(handler-case (+ 1 2) (<error> () 0))`,
+			want:    `3`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (car 1) (<domain-error> () 'domain) (<error> () 'error))`,
+			want:    `'domain`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (car 1) (<arithmetic-error> () 'arithmetic) (<program-error> () 'program))`,
+			want:    `'program`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (error "err") (<simple-error> (c) (instancep c (class <simple-error>))))`,
+			want:    `t`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (handler-case (car 1) (<arithmetic-error> () 'inner)) (<error> () 'outer))`,
+			want:    `'outer`,
+			wantErr: false,
+		},
+		{
+			exp:     `(labels ((f () (car 1)) (g () (f))) (handler-case (g) (<domain-error> () 'caught)))`,
+			want:    `'caught`,
+			wantErr: false,
+		},
+		{
+			exp:     `(flet ((f (x) x)) (handler-case (f) (<program-error> () 'arity)))`,
+			want:    `'arity`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (car 1) (<arithmetic-error> () 'arithmetic))`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(handler-case (car 1) (<undefined-class> () 'arithmetic))`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	}
+	execTests(t, HandlerCase, tests)
+}
+
+func TestConditionAccessors(t *testing.T) {
+	// Apply turns the panic of a builtin into an <internal-error>.
+	panicking := instance.NewSymbol("PANIC-FOR-ACCESSORS")
+	TopLevel.Function.Define(panicking, instance.NewFunction(panicking, func(e env.Environment) (ilos.Instance, ilos.Instance) {
+		panic("panic for accessors")
+	}))
+	defer delete(TopLevel.Function[0], panicking)
+	tests := []test{
+		{
+			exp:     `(handler-case (error "foo ~A" 1 2) (<simple-error> (c) (simple-error-format-string c)))`,
+			want:    `"foo ~A"`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (error "foo ~A" 1 2) (<simple-error> (c) (simple-error-format-arguments c)))`,
+			want:    `'(1 2)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (car 1) (<domain-error> (c) (domain-error-object c)))`,
+			want:    `1`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (car 1) (<domain-error> (c) (eq (domain-error-expected-class c) (class <cons>))))`,
+			want:    `t`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (div 1 0) (<arithmetic-error> (c) (arithmetic-error-operands c)))`,
+			want:    `'(1 0)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (parse-number "x") (<parse-error> (c) (parse-error-string c)))`,
+			want:    `"x"`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case undefined-variable-for-accessors (<undefined-entity> (c) (list (undefined-entity-name c) (undefined-entity-namespace c))))`,
+			want:    `'(undefined-variable-for-accessors variable)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (undefined-function-for-accessors) (<undefined-entity> (c) (undefined-entity-namespace c)))`,
+			want:    `'function`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (read-char (create-string-input-stream "")) (<stream-error> (c) (streamp (stream-error-stream c))))`,
+			want:    `t`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (car 1 2) (<arity-error> (c) (list (arity-error-name c) (arity-error-expected-min c) (arity-error-expected-max c) (arity-error-actual c))))`,
+			want:    `'(car 1 1 2)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (format) (<arity-error> (c) (arity-error-expected-max c)))`,
+			want:    `nil`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (elt '(a b) 2) (<index-out-of-range> (c) (list (index-out-of-range-sequence c) (index-out-of-range-index c))))`,
+			want:    `'((a b) 2)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (aref (vector 1 2) -1) (<index-out-of-range> (c) (index-out-of-range-index c)))`,
+			want:    `-1`,
+			wantErr: false,
+		},
+		{
+			exp:     `(progn (defconstant immutable-for-accessors 1) (handler-case (defconstant immutable-for-accessors 2) (<immutable-binding> (c) (immutable-binding-name c))))`,
+			want:    `'immutable-for-accessors`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (elt '(a b) 2) (<program-error> (c) t))`,
+			want:    `t`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (panic-for-accessors) (<internal-error> (c) (internal-error-name c)))`,
+			want:    `'panic-for-accessors`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (panic-for-accessors) (<program-error> (c) (internal-error-message c)))`,
+			want:    `"panic for accessors"`,
+			wantErr: false,
+		},
+		{
+			exp:     `(simple-error-format-string 1)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	}
+	execTests(t, SimpleErrorFormatString, tests)
+}
+
+func TestReportCondition(t *testing.T) {
+	tests := []test{
+		{
+			exp: `
+			(let ((s (create-string-output-stream)))
+				(handler-case (error "foo ~A" 1) (<error> (c) (report-condition c s)))
+				(get-output-stream-string s))
+			`,
+			want:    `"foo 1"`,
+			wantErr: false,
+		},
+		{
+			exp: `
+			(let ((s (create-string-output-stream)))
+				(handler-case (car 1) (<error> (c) (report-condition c s)))
+				(get-output-stream-string s))
+			`,
+			want:    `"1 is not an instance of <CONS>."`,
+			wantErr: false,
+		},
+		{
+			exp: `
+			(let ((s (create-string-output-stream)))
+				(handler-case undefined-variable-for-report (<error> (c) (report-condition c s)))
+				(get-output-stream-string s))
+			`,
+			want:    `"The variable UNDEFINED-VARIABLE-FOR-REPORT is undefined."`,
+			wantErr: false,
+		},
+		{
+			exp: `
+			(let ((s (create-string-output-stream)))
+				(handler-case (div 1 0) (<error> (c) (report-condition c s)))
+				(get-output-stream-string s))
+			`,
+			want:    `"Division by zero in DIV with operands (1 0)."`,
+			wantErr: false,
+		},
+		{
+			exp: `
+			(let ((s (create-string-output-stream)))
+				(handler-case (cons 1) (<error> (c) (report-condition c s)))
+				(get-output-stream-string s))
+			`,
+			want:    `"CONS expects 2 arguments but was given 1."`,
+			wantErr: false,
+		},
+		{
+			exp: `
+			(let ((s (create-string-output-stream)))
+				(handler-case (elt (vector 1 2) 5) (<error> (c) (report-condition c s)))
+				(get-output-stream-string s))
+			`,
+			want:    `"The index 5 is out of range for #(1 2)."`,
+			wantErr: false,
+		},
+		{
+			exp: `
+			(progn
+				(defclass <my-error> (<error>) ())
+				(defmethod report-condition ((c <my-error>) stream) (format stream "mine"))
+				(let ((s (create-string-output-stream)))
+					(handler-case (signal-condition (create (class <my-error>)) nil) (<error> (c) (report-condition c s)))
+					(get-output-stream-string s)))
+			`,
+			want:    `"mine"`,
+			wantErr: false,
+		},
+	}
+	execTests(t, ReportCondition, tests)
+}
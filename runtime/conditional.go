@@ -24,7 +24,7 @@ func If(e env.Environment, testForm, thenForm ilos.Instance, elseForm ...ilos.In
 		return Eval(e, thenForm)
 	}
 	if len(elseForm) > 1 {
-		return SignalCondition(e, instance.NewArityError(e), Nil)
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("IF"), 2, 3, 2+len(elseForm)), Nil)
 	}
 	if len(elseForm) == 0 {
 		return Nil, nil
@@ -45,7 +45,7 @@ func Cond(e env.Environment, testFrom ...ilos.Instance) (ilos.Instance, ilos.Ins
 		}
 		s := tf.(instance.List).Slice()
 		if len(s) == 0 {
-			return SignalCondition(e, instance.NewArityError(e), Nil)
+			return SignalCondition(e, instance.NewProgramError(e), Nil)
 		}
 		ret, err := Eval(e, s[0])
 		if err != nil {
@@ -83,7 +83,7 @@ func Case(e env.Environment, key ilos.Instance, pattern ...ilos.Instance) (ilos.
 		}
 		form := pat.(instance.List).Slice()
 		if len(form) < 1 {
-			return SignalCondition(e, instance.NewArityError(e), Nil)
+			return SignalCondition(e, instance.NewProgramError(e), Nil)
 		}
 		if idx == len(pattern)-1 && form[0] == T {
 			return Progn(e, form[1:]...)
@@ -133,7 +133,7 @@ func CaseUsing(e env.Environment, pred, key ilos.Instance, pattern ...ilos.Insta
 		}
 		form := pat.(instance.List).Slice()
 		if len(form) < 1 {
-			return SignalCondition(e, instance.NewArityError(e), Nil)
+			return SignalCondition(e, instance.NewProgramError(e), Nil)
 		}
 		if idx == len(pattern)-1 && form[0] == T {
 			return Progn(e, form[1:]...)
@@ -0,0 +1,269 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/islisp-dev/iris/reader/parser"
+	"github.com/islisp-dev/iris/reader/tokenizer"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+var update = flag.Bool("update", false, "rewrite the conformance report")
+
+const (
+	conformanceDir    = "testdata/conformance"
+	conformanceReport = "testdata/conformance/REPORT.md"
+)
+
+// conformanceCase is an expectation in a conformance file: form evaluates to
+// the value of want, or signals a condition of the class named want if
+// signals is true.
+type conformanceCase struct {
+	form    ilos.Instance
+	want    ilos.Instance
+	signals bool
+}
+
+// conformanceSection is a section of the standard and its cases.
+type conformanceSection struct {
+	number string
+	title  string
+	cases  []conformanceCase
+}
+
+// readConformanceFile reads the sections in the conformance file at path,
+// which consists of the following forms:
+//
+// (section "number" "title") starts a section of the standard.
+//
+// form => want expects form to evaluate to the value of want.
+//
+// form signals class-name expects form to signal a condition of the class.
+//
+// The forms of a file are evaluated in order, so a case may depend on the
+// definitions made by the cases before it.
+func readConformanceFile(path string) ([]*conformanceSection, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	r := tokenizer.NewReader(file)
+	read := func() (ilos.Instance, error) {
+		obj, err := parser.Parse(r)
+		if err != nil {
+			if ilos.InstanceOf(class.EndOfStream, err) {
+				return nil, nil
+			}
+			return nil, fmt.Errorf("%v: %v", path, err)
+		}
+		return obj, nil
+	}
+	sections := []*conformanceSection{}
+	for {
+		form, err := read()
+		if err != nil || form == nil {
+			return sections, err
+		}
+		if ilos.InstanceOf(class.Cons, form) && form.(*instance.Cons).Car == instance.NewSymbol("SECTION") {
+			xs := form.(instance.List).Slice()
+			if len(xs) != 3 || !ilos.InstanceOf(class.String, xs[1]) || !ilos.InstanceOf(class.String, xs[2]) {
+				return nil, fmt.Errorf("%v: malformed section %v", path, form)
+			}
+			sections = append(sections, &conformanceSection{number: string(xs[1].(instance.String)), title: string(xs[2].(instance.String))})
+			continue
+		}
+		if len(sections) == 0 {
+			return nil, fmt.Errorf("%v: %v is not in a section", path, form)
+		}
+		arrow, err := read()
+		if err != nil {
+			return nil, err
+		}
+		want, err := read()
+		if err != nil {
+			return nil, err
+		}
+		if want == nil || (arrow != instance.NewSymbol("=>") && arrow != instance.NewSymbol("SIGNALS")) {
+			return nil, fmt.Errorf("%v: %v is not followed by => or signals", path, form)
+		}
+		s := sections[len(sections)-1]
+		s.cases = append(s.cases, conformanceCase{form: form, want: want, signals: arrow == instance.NewSymbol("SIGNALS")})
+	}
+}
+
+// run evaluates the form of c and returns a description of the mismatch, or
+// an empty string if c passes.
+func (c conformanceCase) run() string {
+	got, err := Eval(TopLevel, c.form)
+	describe := func() string {
+		if err != nil {
+			if ilos.InstanceOf(class.SeriousCondition, err) {
+				return fmt.Sprintf("signaled %v", err.Class())
+			}
+			return "exited non-locally"
+		}
+		return fmt.Sprintf("returned %v", got)
+	}
+	if c.signals {
+		want, fail := Class(TopLevel, c.want)
+		if fail != nil {
+			return fmt.Sprintf("%v is not a class", c.want)
+		}
+		if err == nil || !ilos.InstanceOf(want, err) {
+			return fmt.Sprintf("%v, want a condition of class %v", describe(), c.want)
+		}
+		return ""
+	}
+	want, fail := Eval(TopLevel, c.want)
+	if fail != nil {
+		return fmt.Sprintf("%v cannot be evaluated", c.want)
+	}
+	if err != nil {
+		return fmt.Sprintf("%v, want %v", describe(), want)
+	}
+	if ok, _ := Equal(TopLevel, got, want); ok == Nil {
+		return fmt.Sprintf("%v, want %v", describe(), want)
+	}
+	return ""
+}
+
+// globals returns a copy of the global definitions of the top level.
+func globals() []map[ilos.Instance]ilos.Instance {
+	copies := []map[ilos.Instance]ilos.Instance{}
+	for _, m := range []map[ilos.Instance]ilos.Instance{
+		TopLevel.Function[0], TopLevel.Variable[0], TopLevel.DynamicVariable[0],
+		TopLevel.Class[0], TopLevel.Macro[0], TopLevel.Special[0], TopLevel.Constant[0],
+	} {
+		c := map[ilos.Instance]ilos.Instance{}
+		for k, v := range m {
+			c[k] = v
+		}
+		copies = append(copies, c)
+	}
+	return copies
+}
+
+// restoreGlobals undoes the global definitions made since saved was taken by
+// globals.
+func restoreGlobals(saved []map[ilos.Instance]ilos.Instance) {
+	for i, m := range []map[ilos.Instance]ilos.Instance{
+		TopLevel.Function[0], TopLevel.Variable[0], TopLevel.DynamicVariable[0],
+		TopLevel.Class[0], TopLevel.Macro[0], TopLevel.Special[0], TopLevel.Constant[0],
+	} {
+		for k := range m {
+			if _, ok := saved[i][k]; !ok {
+				delete(m, k)
+			}
+		}
+		for k, v := range saved[i] {
+			m[k] = v
+		}
+	}
+}
+
+// conformanceReportOf returns the report in Markdown of the sections with
+// the failures of their cases.
+func conformanceReportOf(sections []*conformanceSection, failures map[*conformanceSection][]string) string {
+	var b strings.Builder
+	b.WriteString("# ISLisp conformance\n\n")
+	b.WriteString("This file is generated by `go test ./runtime -run TestConformance -update`\n")
+	b.WriteString("from the expectations in this directory. Do not edit it by hand.\n\n")
+	b.WriteString("| Section | Title | Passed | Failed | Total |\n")
+	b.WriteString("| --- | --- | ---: | ---: | ---: |\n")
+	passed, total := 0, 0
+	for _, s := range sections {
+		n, f := len(s.cases), len(failures[s])
+		fmt.Fprintf(&b, "| %v | %v | %d | %d | %d |\n", s.number, s.title, n-f, f, n)
+		passed, total = passed+n-f, total+n
+	}
+	fmt.Fprintf(&b, "| | **Total** | %d | %d | %d |\n", passed, total-passed, total)
+	if passed == total {
+		return b.String()
+	}
+	b.WriteString("\n## Failures\n")
+	for _, s := range sections {
+		if len(failures[s]) == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n### %v %v\n\n", s.number, s.title)
+		for _, f := range failures[s] {
+			fmt.Fprintf(&b, "- %v\n", f)
+		}
+	}
+	return b.String()
+}
+
+// TestConformance evaluates the expectations in the conformance files and
+// compares the results with the committed report, so that a change in the
+// conformance to the standard, for better or worse, is noticed. Run it with
+// -update to rewrite the report.
+func TestConformance(t *testing.T) {
+	paths, err := filepath.Glob(filepath.Join(conformanceDir, "*.lsp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	sort.Strings(paths)
+	sections := []*conformanceSection{}
+	failures := map[*conformanceSection][]string{}
+	for _, path := range paths {
+		ss, err := readConformanceFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		saved := globals()
+		for _, s := range ss {
+			for _, c := range s.cases {
+				if message := c.run(); message != "" {
+					failures[s] = append(failures[s], fmt.Sprintf("`%v` %v", c.form, message))
+				}
+			}
+		}
+		restoreGlobals(saved)
+		sections = append(sections, ss...)
+	}
+	report := conformanceReportOf(sections, failures)
+	if *update {
+		if err := ioutil.WriteFile(conformanceReport, []byte(report), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+	data, err := ioutil.ReadFile(conformanceReport)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) == report {
+		return
+	}
+	committed := map[string]bool{}
+	for _, line := range strings.Split(string(data), "\n") {
+		committed[line] = true
+	}
+	generated := map[string]bool{}
+	for _, line := range strings.Split(report, "\n") {
+		generated[line] = true
+		if !committed[line] {
+			t.Errorf("+ %v", line)
+		}
+	}
+	for _, line := range strings.Split(string(data), "\n") {
+		if !generated[line] {
+			t.Errorf("- %v", line)
+		}
+	}
+	t.Errorf("the conformance differs from %v; run go test -run TestConformance -update if it is expected", conformanceReport)
+}
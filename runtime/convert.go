@@ -11,6 +11,50 @@ import (
 	"github.com/islisp-dev/iris/runtime/ilos/instance"
 )
 
+// Safety controls the checks of the: if it is positive, the signals an error
+// when the value does not match its declared class. Otherwise, the declaration
+// is trusted without checking.
+var Safety = 1
+
+// The evaluates form and returns its value, declaring that the value is an
+// instance of the class named class-name. If Safety is positive, an error shall
+// be signaled if it is not (error-id. domain-error); otherwise the consequences
+// are undefined.
+func The(e env.Environment, className, form ilos.Instance) (ilos.Instance, ilos.Instance) {
+	c, err := Class(e, className)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := Eval(e, form)
+	if err != nil {
+		return nil, err
+	}
+	if Safety > 0 {
+		if err := ensure(e, c, ret); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// Assure evaluates form and returns its value. An error shall be signaled if
+// the value is not an instance of the class named class-name (error-id.
+// domain-error), regardless of Safety.
+func Assure(e env.Environment, className, form ilos.Instance) (ilos.Instance, ilos.Instance) {
+	c, err := Class(e, className)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := Eval(e, form)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensure(e, c, ret); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
 func Convert(e env.Environment, object, class1 ilos.Instance) (ilos.Instance, ilos.Instance) {
 	object, err := Eval(e, object)
 	if err != nil {
@@ -22,7 +66,7 @@ func Convert(e env.Environment, object, class1 ilos.Instance) (ilos.Instance, il
 	}
 	switch object.Class().String() {
 	case class.Character.String():
-		switch class1.(instance.BuiltInClass).String() {
+		switch class1.String() {
 		case class.Character.String():
 			return object, nil
 		case class.Integer.String():
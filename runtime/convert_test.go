@@ -15,3 +15,85 @@ func TestConvert(t *testing.T) {
 		},
 	})
 }
+
+func TestThe(t *testing.T) {
+	execTests(t, The, []test{
+		{
+			exp:     `(the <integer> 1)`,
+			want:    `1`,
+			wantErr: false,
+		},
+		{
+			exp:     `(the <number> (+ 1 2))`,
+			want:    `3`,
+			wantErr: false,
+		},
+		{
+			exp:     `(the <integer> "1")`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(handler-case (the <string> 1) (<domain-error> (c) (domain-error-object c)))`,
+			want:    `1`,
+			wantErr: false,
+		},
+		{
+			exp:     `(car (the <cons> '(a b)))`,
+			want:    `'a`,
+			wantErr: false,
+		},
+		{
+			exp:     `(length (the <list> '(a b)))`,
+			want:    `2`,
+			wantErr: false,
+		},
+		{
+			exp:     `(the <undefined-class-for-the> 1)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestTheUnchecked(t *testing.T) {
+	Safety = 0
+	defer func() { Safety = 1 }()
+	execTests(t, The, []test{
+		{
+			exp:     `(the <integer> "1")`,
+			want:    `"1"`,
+			wantErr: false,
+		},
+		{
+			exp:     `(assure <integer> "1")`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestAssure(t *testing.T) {
+	execTests(t, Assure, []test{
+		{
+			exp:     `(assure <integer> 1)`,
+			want:    `1`,
+			wantErr: false,
+		},
+		{
+			exp:     `(assure <list> nil)`,
+			want:    `nil`,
+			wantErr: false,
+		},
+		{
+			exp:     `(assure <integer> 1.5)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(elt (assure <string> "abc") (assure <integer> 1))`,
+			want:    `#\b`,
+			wantErr: false,
+		},
+	})
+}
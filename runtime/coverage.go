@@ -0,0 +1,327 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"io/ioutil"
+	"sort"
+	"strings"
+
+	"github.com/islisp-dev/iris/reader/parser"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// Coverage counts the evaluations of the forms read from the named inputs,
+// such as the source files, while it is taken.
+type Coverage struct {
+	// Code returns the lists in the forms given which are evaluated as code,
+	// which are the forms covered, such as lint.Code. All the lists are
+	// taken as code if it is nil.
+	Code    func(forms []ilos.Instance) []*instance.Cons
+	sources map[*instance.Cons]parser.Source
+	// old is the forms read before the coverage is started, which are in
+	// sources while it is shared with the stepper.
+	old    map[*instance.Cons]bool
+	counts map[*instance.Cons]int
+}
+
+// coverage is the coverage being taken, or nil.
+var coverage *Coverage
+
+// StartCoverage starts recording where the forms are read from and counting
+// their evaluations.
+func StartCoverage() *Coverage {
+	if coverage != nil {
+		coverage.Stop()
+	}
+	c := &Coverage{sources: useSources(), old: map[*instance.Cons]bool{}, counts: map[*instance.Cons]int{}}
+	for cons := range c.sources {
+		c.old[cons] = true
+	}
+	coverage = c
+	return c
+}
+
+// Stop stops taking c. Only the forms read while it was taken are kept.
+func (c *Coverage) Stop() {
+	if coverage == c {
+		coverage = nil
+		sources := map[*instance.Cons]parser.Source{}
+		for cons, source := range c.sources {
+			if !c.old[cons] {
+				sources[cons] = source
+			}
+		}
+		c.sources, c.old = sources, nil
+		releaseSources()
+	}
+}
+
+func (c *Coverage) count(form ilos.Instance) {
+	if cons, ok := form.(*instance.Cons); ok {
+		if _, ok := c.sources[cons]; ok && !c.old[cons] {
+			c.counts[cons]++
+		}
+	}
+}
+
+// elements returns the elements of list, ignoring the last cdr of a dotted
+// list.
+func elements(list ilos.Instance) []ilos.Instance {
+	xs := []ilos.Instance{}
+	for cons, ok := list.(*instance.Cons); ok; cons, ok = cons.Cdr.(*instance.Cons) {
+		xs = append(xs, cons.Car)
+	}
+	return xs
+}
+
+// isCode reports whether form is evaluated as a call, that is, its operator
+// is a lambda form or names a function, a macro or a special form.
+func isCode(form *instance.Cons) bool {
+	if operator, ok := form.Car.(*instance.Cons); ok {
+		return operator.Car == instance.NewSymbol("LAMBDA")
+	}
+	if _, ok := TopLevel.Function[:1].Get(form.Car); ok {
+		return true
+	}
+	if _, ok := TopLevel.Macro[:1].Get(form.Car); ok {
+		return true
+	}
+	_, ok := TopLevel.Special[:1].Get(form.Car)
+	return ok
+}
+
+// lists returns form and the lists in it.
+func lists(form ilos.Instance) []*instance.Cons {
+	cons, ok := form.(*instance.Cons)
+	if !ok {
+		return nil
+	}
+	return append(append([]*instance.Cons{cons}, lists(cons.Car)...), lists(cons.Cdr)...)
+}
+
+// coveredFile is a source and the forms evaluated as code in it.
+type coveredFile struct {
+	name string
+	// forms are sorted by their positions, the outer first.
+	forms []*instance.Cons
+	// text is the content of the source, or nil if it cannot be read.
+	text []rune
+}
+
+// files returns the sources of the forms read, sorted by name.
+func (c *Coverage) files() []*coveredFile {
+	children := map[*instance.Cons]bool{}
+	for cons := range c.sources {
+		for _, x := range elements(cons) {
+			if child, ok := x.(*instance.Cons); ok {
+				children[child] = true
+			}
+		}
+	}
+	files := map[string]*coveredFile{}
+	roots := []ilos.Instance{}
+	for cons, source := range c.sources {
+		if files[source.Name] == nil {
+			files[source.Name] = &coveredFile{name: source.Name}
+		}
+		if !children[cons] {
+			roots = append(roots, cons)
+		}
+	}
+	code := []*instance.Cons{}
+	if c.Code != nil {
+		code = c.Code(roots)
+	} else {
+		for _, root := range roots {
+			code = append(code, lists(root)...)
+		}
+	}
+	seen := map[*instance.Cons]bool{}
+	for _, form := range code {
+		if _, ok := c.sources[form]; ok && isCode(form) && !seen[form] {
+			seen[form] = true
+			f := files[c.sources[form].Name]
+			f.forms = append(f.forms, form)
+		}
+	}
+	sorted := []*coveredFile{}
+	for _, f := range files {
+		sort.Slice(f.forms, func(i, j int) bool {
+			a, b := c.sources[f.forms[i]], c.sources[f.forms[j]]
+			if a.Start.Offset != b.Start.Offset {
+				return a.Start.Offset < b.Start.Offset
+			}
+			return a.End.Offset > b.End.Offset
+		})
+		if text, err := ioutil.ReadFile(f.name); err == nil {
+			f.text = []rune(string(text))
+		}
+		sorted = append(sorted, f)
+	}
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].name < sorted[j].name })
+	return sorted
+}
+
+// covered returns the number of the forms in f which are evaluated.
+func (c *Coverage) covered(f *coveredFile) int {
+	n := 0
+	for _, form := range f.forms {
+		if c.counts[form] > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+// excerpt returns the first line of the text of form, shortened if it is too
+// long.
+func (c *Coverage) excerpt(f *coveredFile, form *instance.Cons) string {
+	source := c.sources[form]
+	text := []rune(form.String())
+	if source.End.Offset <= len(f.text) {
+		text = f.text[source.Start.Offset:source.End.Offset]
+	}
+	line := strings.SplitN(string(text), "\n", 2)[0]
+	if rs := []rune(line); len(rs) > 60 {
+		line = string(rs[:60])
+	}
+	if line != string(text) {
+		line += " ..."
+	}
+	return line
+}
+
+func coverageRatio(covered, total int) string {
+	percent := 100.0
+	if total > 0 {
+		percent = float64(covered) * 100 / float64(total)
+	}
+	return fmt.Sprintf("%d of %d forms evaluated (%.1f%%)", covered, total, percent)
+}
+
+// WriteText writes to w how many forms are evaluated in each source, and where
+// the forms not evaluated are, except the ones inside them.
+func (c *Coverage) WriteText(w io.Writer) error {
+	var b strings.Builder
+	covered, total := 0, 0
+	for _, f := range c.files() {
+		n := c.covered(f)
+		covered, total = covered+n, total+len(f.forms)
+		fmt.Fprintf(&b, "%s: %s\n", f.name, coverageRatio(n, len(f.forms)))
+		end := -1
+		for _, form := range f.forms {
+			source := c.sources[form]
+			if c.counts[form] > 0 || source.Start.Offset < end {
+				continue
+			}
+			end = source.End.Offset
+			fmt.Fprintf(&b, "  %s:%d:%d: %s\n", f.name, source.Start.Line, source.Start.Column, c.excerpt(f, form))
+		}
+	}
+	fmt.Fprintf(&b, "total: %s\n", coverageRatio(covered, total))
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteLCOV writes the coverage to w in the LCOV format. The count of a line
+// is the least number of the evaluations of the forms beginning on the line,
+// so a line is not hit unless all of them are evaluated.
+func (c *Coverage) WriteLCOV(w io.Writer) error {
+	var b strings.Builder
+	for _, f := range c.files() {
+		counts := map[int]int{}
+		lines := []int{}
+		for _, form := range f.forms {
+			line, count := c.sources[form].Start.Line, c.counts[form]
+			if least, ok := counts[line]; !ok {
+				lines = append(lines, line)
+			} else if least < count {
+				count = least
+			}
+			counts[line] = count
+		}
+		sort.Ints(lines)
+		fmt.Fprintf(&b, "TN:\nSF:%s\n", f.name)
+		hit := 0
+		for _, line := range lines {
+			fmt.Fprintf(&b, "DA:%d,%d\n", line, counts[line])
+			if counts[line] > 0 {
+				hit++
+			}
+		}
+		fmt.Fprintf(&b, "LF:%d\nLH:%d\nend_of_record\n", len(lines), hit)
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+const coverageHTMLHeader = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Coverage</title>
+<style>
+body { font-family: sans-serif; }
+pre { font-family: monospace; }
+.evaluated { background-color: #d4f4d4; }
+.unevaluated { background-color: #f8d0d0; }
+</style>
+</head>
+<body>
+`
+
+// WriteHTML writes the coverage to w as an HTML document, in which the text of
+// each source is shown with the forms evaluated and not evaluated in
+// different colors. The number of the evaluations of a form is shown as its
+// title.
+func (c *Coverage) WriteHTML(w io.Writer) error {
+	var b strings.Builder
+	b.WriteString(coverageHTMLHeader)
+	covered, total := 0, 0
+	files := c.files()
+	for _, f := range files {
+		n := c.covered(f)
+		covered, total = covered+n, total+len(f.forms)
+	}
+	fmt.Fprintf(&b, "<h1>Coverage: %s</h1>\n", html.EscapeString(coverageRatio(covered, total)))
+	for _, f := range files {
+		fmt.Fprintf(&b, "<h2>%s: %s</h2>\n", html.EscapeString(f.name), html.EscapeString(coverageRatio(c.covered(f), len(f.forms))))
+		if f.text == nil {
+			b.WriteString("<p>The source cannot be read.</p>\n")
+			continue
+		}
+		b.WriteString("<pre>")
+		ends := []int{}
+		forms := f.forms
+		for i, r := range f.text {
+			for len(ends) > 0 && ends[len(ends)-1] == i {
+				b.WriteString("</span>")
+				ends = ends[:len(ends)-1]
+			}
+			for len(forms) > 0 && c.sources[forms[0]].Start.Offset == i {
+				kind, count := "evaluated", c.counts[forms[0]]
+				if count == 0 {
+					kind = "unevaluated"
+				}
+				fmt.Fprintf(&b, `<span class="%s" title="evaluations: %d">`, kind, count)
+				ends = append(ends, c.sources[forms[0]].End.Offset)
+				forms = forms[1:]
+			}
+			b.WriteString(html.EscapeString(string(r)))
+		}
+		for range ends {
+			b.WriteString("</span>")
+		}
+		b.WriteString("</pre>\n")
+	}
+	b.WriteString("</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
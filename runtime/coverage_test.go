@@ -0,0 +1,89 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/islisp-dev/iris/reader/parser"
+	"github.com/islisp-dev/iris/reader/tokenizer"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
+)
+
+const coverageSource = `(if (< 1 2)
+    (list 1)
+    (list 2))
+(let ((x (+ 1 2)))
+  (cond ((= x 3) 'three)
+        (t (car '(1 2)))))
+`
+
+func TestCoverage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "source.lsp")
+	if err := ioutil.WriteFile(path, []byte(coverageSource), 0666); err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	c := StartCoverage()
+	r := tokenizer.NewReader(file)
+	r.Name = path
+	for {
+		form, err := parser.Parse(r)
+		if err != nil {
+			if !ilos.InstanceOf(class.EndOfStream, err) {
+				t.Fatal(err)
+			}
+			break
+		}
+		if _, err := Eval(TopLevel, form); err != nil {
+			t.Fatal(err)
+		}
+	}
+	c.Stop()
+	if parser.Sources != nil || coverage != nil {
+		t.Errorf("Stop() did not stop the coverage")
+	}
+	var out bytes.Buffer
+	if err := c.WriteText(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := path + ": 8 of 11 forms evaluated (72.7%)\n" +
+		"  " + path + ":3:5: (list 2)\n" +
+		"  " + path + ":6:12: (car '(1 2))\n" +
+		"total: 8 of 11 forms evaluated (72.7%)\n"
+	if out.String() != want {
+		t.Errorf("WriteText() = %q, want %q", out.String(), want)
+	}
+	out.Reset()
+	if err := c.WriteLCOV(&out); err != nil {
+		t.Fatal(err)
+	}
+	want = "TN:\nSF:" + path + "\nDA:1,1\nDA:2,1\nDA:3,0\nDA:4,1\nDA:5,1\nDA:6,0\nLF:6\nLH:4\nend_of_record\n"
+	if out.String() != want {
+		t.Errorf("WriteLCOV() = %q, want %q", out.String(), want)
+	}
+	out.Reset()
+	if err := c.WriteHTML(&out); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		`<span class="evaluated" title="evaluations: 1">(list 1)</span>`,
+		`<span class="unevaluated" title="evaluations: 0">(car <span class="unevaluated" title="evaluations: 0">&#39;(1 2)</span>)</span>`,
+	} {
+		if !strings.Contains(out.String(), s) {
+			t.Errorf("WriteHTML() has no %q", s)
+		}
+	}
+}
@@ -17,19 +17,25 @@ import (
 // binding for name can be ely established by a binding form. The result of the
 // evaluation of form is bound to the variable named by name. The binding and
 // the object created as the result of evaluating the second argument are
-// immutable. The symbol named name is returned.
-func Defconstant(e env.Environment, name, form ilos.Instance) (ilos.Instance, ilos.Instance) {
+// immutable. The symbol named name is returned. A documentation string may
+// follow form.
+func Defconstant(e env.Environment, name, form ilos.Instance, documentation ...ilos.Instance) (ilos.Instance, ilos.Instance) {
 	if err := ensure(e, class.Symbol, name); err != nil {
 		return nil, err
 	}
+	doc, err := optionalDocumentation(e, "DEFCONSTANT", 2, documentation)
+	if err != nil {
+		return nil, err
+	}
 	if _, ok := e.Constant[:1].Get(name); ok {
-		return SignalCondition(e, instance.NewImmutableBinding(e), Nil)
+		return SignalCondition(e, instance.NewImmutableBinding(e, name), Nil)
 	}
 	ret, err := Eval(e, form)
 	if err != nil {
 		return nil, err
 	}
 	e.Constant[:1].Define(name, ret)
+	document(e, name, "VARIABLE", doc)
 	return name, nil
 }
 
@@ -40,37 +46,48 @@ func Defconstant(e env.Environment, name, form ilos.Instance) (ilos.Instance, il
 // defining variables and not for modifying them. The symbol named name is
 // returned. A lexical variable binding for name can still be ely established by
 // a binding form; in that case, the e binding lexically shadows the outer
-// binding of name defined by defe.
-func Defglobal(e env.Environment, name, form ilos.Instance) (ilos.Instance, ilos.Instance) {
+// binding of name defined by defe. A documentation string may follow form.
+func Defglobal(e env.Environment, name, form ilos.Instance, documentation ...ilos.Instance) (ilos.Instance, ilos.Instance) {
 	if err := ensure(e, class.Symbol, name); err != nil {
 		return nil, err
 	}
+	doc, err := optionalDocumentation(e, "DEFGLOBAL", 2, documentation)
+	if err != nil {
+		return nil, err
+	}
 	if _, ok := e.Constant[:1].Get(name); ok {
-		return SignalCondition(e, instance.NewImmutableBinding(e), Nil)
+		return SignalCondition(e, instance.NewImmutableBinding(e, name), Nil)
 	}
 	ret, err := Eval(e, form)
 	if err != nil {
 		return nil, err
 	}
 	e.Variable[:1].Define(name, ret)
+	document(e, name, "VARIABLE", doc)
 	return name, nil
 }
 
 // Defdynamic is used to define a dynamic variable identifier in the dynamic
 // variable namespace. The scope of name is the entire current toplevel scope
-// except the body form.The symbol named name is returned.
-func Defdynamic(e env.Environment, name, form ilos.Instance) (ilos.Instance, ilos.Instance) {
+// except the body form.The symbol named name is returned. A documentation
+// string may follow form.
+func Defdynamic(e env.Environment, name, form ilos.Instance, documentation ...ilos.Instance) (ilos.Instance, ilos.Instance) {
 	if err := ensure(e, class.Symbol, name); err != nil {
 		return nil, err
 	}
+	doc, err := optionalDocumentation(e, "DEFDYNAMIC", 2, documentation)
+	if err != nil {
+		return nil, err
+	}
 	if _, ok := e.Constant[:1].Get(name); ok {
-		return SignalCondition(e, instance.NewImmutableBinding(e), Nil)
+		return SignalCondition(e, instance.NewImmutableBinding(e, name), Nil)
 	}
 	ret, err := Eval(e, form)
 	if err != nil {
 		return nil, err
 	}
 	e.DynamicVariable[:1].Define(name, ret)
+	document(e, name, "DYNAMIC", doc)
 	return name, nil
 }
 
@@ -82,15 +99,19 @@ func Defdynamic(e env.Environment, name, form ilos.Instance) (ilos.Instance, ilo
 // binding between function-name and the function object is immutable. defun
 // returns the function name which is the symbol named function-name. The free
 // identifiers in the body form* (i.e., those which are not contained in the
-// lambda list) follow the rules of lexical scoping.
+// lambda list) follow the rules of lexical scoping. A string at the beginning
+// of form* is the documentation of the function if other forms follow it.
 func Defun(e env.Environment, functionName, lambdaList ilos.Instance, forms ...ilos.Instance) (ilos.Instance, ilos.Instance) {
 	if err := ensure(e, class.Symbol, functionName); err != nil {
 		return nil, err
 	}
+	doc, forms := splitDocumentation(forms)
 	ret, err := newNamedFunction(e, functionName, lambdaList, forms...)
 	if err != nil {
 		return nil, err
 	}
 	e.Function[:1].Define(functionName, ret)
+	document(e, functionName, "FUNCTION", doc)
+	e.LambdaList.Set(functionName, instance.NewSymbol("FUNCTION"), lambdaList)
 	return functionName, nil
 }
@@ -0,0 +1,250 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/islisp-dev/iris/runtime/env"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// documentationKinds are the kinds of the documentation strings, in the order
+// in which documentation looks for them if the kind is not given. Functions,
+// macros and generic functions are documented as FUNCTION, global variables
+// and constants as VARIABLE, and dynamic variables as DYNAMIC.
+var documentationKinds = []ilos.Instance{
+	instance.NewSymbol("FUNCTION"),
+	instance.NewSymbol("VARIABLE"),
+	instance.NewSymbol("DYNAMIC"),
+	instance.NewSymbol("CLASS"),
+}
+
+// splitDocumentation returns the documentation string at the beginning of the
+// body of defun or defmacro, or nil if there is not, and the rest of the body.
+// A string is the documentation only if it is followed by another form, since
+// otherwise it is the value of the body.
+func splitDocumentation(forms []ilos.Instance) (ilos.Instance, []ilos.Instance) {
+	if len(forms) > 1 && ilos.InstanceOf(class.String, forms[0]) {
+		return forms[0], forms[1:]
+	}
+	return nil, forms
+}
+
+// optionalDocumentation returns the documentation string given to the
+// defining form named name as the last of its arguments, or nil if it is not
+// given. required is the number of the other arguments.
+func optionalDocumentation(e env.Environment, name string, required int, documentation []ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if len(documentation) > 1 {
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol(name), required, required+1, required+len(documentation)), Nil)
+	}
+	if len(documentation) == 0 {
+		return nil, nil
+	}
+	if err := ensure(e, class.String, documentation[0]); err != nil {
+		return nil, err
+	}
+	return documentation[0], nil
+}
+
+// document records documentation as the documentation of name of kind, or
+// forgets the documentation if it is nil, since name is redefined without it.
+func document(e env.Environment, name ilos.Instance, kind string, documentation ilos.Instance) {
+	if documentation == nil {
+		e.Documentation.Delete(name, instance.NewSymbol(kind))
+		return
+	}
+	e.Documentation.Set(name, instance.NewSymbol(kind), documentation)
+}
+
+// Documentation returns the documentation string of the definition of name of
+// kind, which is one of function, variable, dynamic and class, or nil if it is
+// not documented. Without kind, the first documentation string of the kinds
+// in this order is returned. An error shall be signaled if kind is none of
+// them (error-id. domain-error).
+func Documentation(e env.Environment, name ilos.Instance, kind ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if len(kind) > 1 {
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("DOCUMENTATION"), 1, 2, len(kind)+1), Nil)
+	}
+	if err := ensure(e, class.Symbol, name); err != nil {
+		return nil, err
+	}
+	kinds := documentationKinds
+	if len(kind) == 1 {
+		if err := ensure(e, class.Symbol, kind[0]); err != nil {
+			return nil, err
+		}
+		known := false
+		for _, k := range documentationKinds {
+			known = known || k == kind[0]
+		}
+		if !known {
+			expected, _ := List(e, documentationKinds...)
+			return SignalCondition(e, instance.NewDomainErrorOneOf(e, kind[0], class.Symbol, expected), Nil)
+		}
+		kinds = kind
+	}
+	for _, k := range kinds {
+		if documentation, ok := e.Documentation.Get(name, k); ok {
+			return documentation, nil
+		}
+	}
+	return Nil, nil
+}
+
+// describeArity returns the description of the numbers of the arguments of a
+// built-in function.
+func describeArity(min, max int) string {
+	plural := func(n int) string {
+		if n == 1 {
+			return "1 argument"
+		}
+		return fmt.Sprintf("%v arguments", n)
+	}
+	switch {
+	case max < 0:
+		return "at least " + plural(min)
+	case min == max:
+		return plural(min)
+	}
+	return fmt.Sprintf("%v to %v", min, plural(max))
+}
+
+// describeSymbol writes the bindings of name in the namespaces of e, each
+// followed by its documentation string.
+func describeSymbol(e env.Environment, name ilos.Instance, w *strings.Builder) {
+	bound := false
+	entry := func(kind string, format string, args ...interface{}) {
+		bound = true
+		fmt.Fprintf(w, "  "+format+"\n", args...)
+		if documentation, ok := e.Documentation.Get(name, instance.NewSymbol(kind)); ok {
+			for _, line := range strings.Split(string(documentation.(instance.String)), "\n") {
+				fmt.Fprintf(w, "    %v\n", line)
+			}
+		}
+	}
+	if _, ok := e.Special[:1].Get(name); ok {
+		entry("", "Special form")
+	}
+	if _, ok := e.Macro[:1].Get(name); ok {
+		if lambdaList, ok := e.LambdaList.Get(name, instance.NewSymbol("MACRO")); ok {
+			entry("FUNCTION", "Macro: %v", instance.NewCons(name, lambdaList))
+		} else {
+			entry("FUNCTION", "Macro")
+		}
+	}
+	if function, ok := e.Function[:1].Get(name); ok {
+		switch f := untraced(function).(type) {
+		case *instance.GenericFunction:
+			entry("FUNCTION", "Generic function: %v", instance.NewCons(name, f.LambdaList()))
+			f.MapMethods(func(qualifier ilos.Instance, classList []ilos.Class, function ilos.Instance) ilos.Instance {
+				classes := []string{}
+				for _, c := range classList {
+					classes = append(classes, c.String())
+				}
+				if qualifier != nil {
+					fmt.Fprintf(w, "    Method: %v (%v)\n", qualifier, strings.Join(classes, " "))
+				} else {
+					fmt.Fprintf(w, "    Method: (%v)\n", strings.Join(classes, " "))
+				}
+				return function
+			})
+		case instance.Function:
+			if lambdaList, ok := e.LambdaList.Get(name, instance.NewSymbol("FUNCTION")); ok {
+				entry("FUNCTION", "Function: %v", instance.NewCons(name, lambdaList))
+			} else {
+				entry("FUNCTION", "Built-in function taking %v", describeArity(f.Arity()))
+			}
+		default:
+			entry("FUNCTION", "Function")
+		}
+	}
+	if value, ok := e.Constant[:1].Get(name); ok {
+		entry("VARIABLE", "Constant: %v", value)
+	}
+	if value, ok := e.Variable[:1].Get(name); ok {
+		entry("VARIABLE", "Global variable: %v", value)
+	}
+	if value, ok := e.DynamicVariable[:1].Get(name); ok {
+		entry("DYNAMIC", "Dynamic variable: %v", value)
+	}
+	if c, ok := e.Class[:1].Get(name); ok {
+		entry("CLASS", "Class: %v", c)
+		supers := []string{}
+		for _, super := range c.(ilos.Class).Supers() {
+			supers = append(supers, super.String())
+		}
+		if len(supers) > 0 {
+			fmt.Fprintf(w, "    Superclasses: %v\n", strings.Join(supers, " "))
+		}
+		slots := []string{}
+		for _, slot := range c.(ilos.Class).Slots() {
+			slots = append(slots, slot.String())
+		}
+		if len(slots) > 0 {
+			fmt.Fprintf(w, "    Slots: %v\n", strings.Join(slots, " "))
+		}
+	}
+	if !bound {
+		w.WriteString("  Not bound\n")
+	}
+}
+
+// Describe writes the description of object to stream, or the standard output
+// if stream is not given, and returns nil. A symbol is described by its
+// bindings in the namespaces, which are the lambda lists of the functions and
+// the macros, the methods of the generic functions, the values of the
+// variables and the superclasses and the slots of the classes, with their
+// documentation strings. The other objects are described by their classes.
+func Describe(e env.Environment, object ilos.Instance, stream ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if len(stream) > 1 {
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("DESCRIBE"), 1, 2, len(stream)+1), Nil)
+	}
+	output := e.StandardOutput
+	if len(stream) == 1 {
+		if ok, _ := OutputStreamP(e, stream[0]); ok == Nil {
+			return SignalCondition(e, instance.NewDomainError(e, stream[0], class.Stream), Nil)
+		}
+		output = stream[0]
+	}
+	description := &strings.Builder{}
+	fmt.Fprintf(description, "%v\n", object)
+	if ilos.InstanceOf(class.Symbol, object) {
+		describeSymbol(e, object, description)
+	} else {
+		fmt.Fprintf(description, "  Instance of %v\n", object.Class())
+	}
+	if _, err := Format(e, output, instance.NewString([]rune("~A")), instance.NewString([]rune(description.String()))); err != nil {
+		return nil, err
+	}
+	return Nil, nil
+}
+
+// Apropos returns the sorted list of the symbols bound in a global namespace
+// whose names contain string, ignoring the case.
+func Apropos(e env.Environment, str ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.String, str); err != nil {
+		return nil, err
+	}
+	substring := strings.ToUpper(string(str.(instance.String)))
+	found := map[ilos.Instance]bool{}
+	symbols := []ilos.Instance{}
+	for _, bindings := range []map[ilos.Instance]ilos.Instance{
+		e.Function[0], e.Macro[0], e.Special[0], e.Variable[0], e.Constant[0], e.DynamicVariable[0], e.Class[0],
+	} {
+		for name := range bindings {
+			if !found[name] && strings.Contains(strings.ToUpper(name.String()), substring) {
+				found[name] = true
+				symbols = append(symbols, name)
+			}
+		}
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].String() < symbols[j].String() })
+	return List(e, symbols...)
+}
@@ -0,0 +1,166 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"testing"
+
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+func TestDocumentation(t *testing.T) {
+	defer func() {
+		for _, name := range []string{"DOC-SQUARE", "DOC-STRING", "DOC-TWICE", "DOC-AREA"} {
+			delete(TopLevel.Function[0], instance.NewSymbol(name))
+		}
+		delete(TopLevel.Macro[0], instance.NewSymbol("DOC-TWICE"))
+		delete(TopLevel.Variable[0], instance.NewSymbol("*DOC-LIMIT*"))
+		delete(TopLevel.DynamicVariable[0], instance.NewSymbol("*DOC-DEPTH*"))
+		delete(TopLevel.Class[0], instance.NewSymbol("<DOC-POINT>"))
+	}()
+	execTests(t, Documentation, []test{
+		{
+			exp:     `(defun doc-square (x) "Returns the square of X." (* x x))`,
+			want:    `'doc-square`,
+			wantErr: false,
+		},
+		{
+			exp:     `(list (doc-square 3) (documentation 'doc-square) (documentation 'doc-square 'function) (documentation 'doc-square 'variable))`,
+			want:    `'(9 "Returns the square of X." "Returns the square of X." nil)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(progn (defun doc-string () "not documentation") (list (doc-string) (documentation 'doc-string)))`,
+			want:    `'("not documentation" nil)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(progn (defun doc-square (x) (* x x)) (documentation 'doc-square))`,
+			want:    `nil`,
+			wantErr: false,
+		},
+		{
+			exp:     `(progn (defmacro doc-twice (x) "Evaluates X twice." (list 'progn x x)) (documentation 'doc-twice))`,
+			want:    `"Evaluates X twice."`,
+			wantErr: false,
+		},
+		{
+			exp:     `(progn (defglobal *doc-limit* 10 "The limit.") (list *doc-limit* (documentation '*doc-limit* 'variable)))`,
+			want:    `'(10 "The limit.")`,
+			wantErr: false,
+		},
+		{
+			exp:     `(progn (defdynamic *doc-depth* 0 "The depth.") (documentation '*doc-depth* 'dynamic))`,
+			want:    `"The depth."`,
+			wantErr: false,
+		},
+		{
+			exp:     `(progn (defclass <doc-point> () (x) (:documentation "A point.")) (documentation '<doc-point> 'class))`,
+			want:    `"A point."`,
+			wantErr: false,
+		},
+		{
+			exp:     `(progn (defgeneric doc-area (shape) (:documentation "Returns the area.")) (documentation 'doc-area))`,
+			want:    `"Returns the area."`,
+			wantErr: false,
+		},
+		{
+			exp:     `(defglobal *doc-limit* 10 'limit)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(defglobal *doc-limit* 10 "The limit." "More.")`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(documentation 'doc-square 'method)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(handler-case (documentation '<doc-point> 'type) (<domain-error> (c) (let ((s (create-string-output-stream))) (report-condition c s) (get-output-stream-string s))))`,
+			want:    `"TYPE is not one of (FUNCTION VARIABLE DYNAMIC CLASS)."`,
+			wantErr: false,
+		},
+	})
+}
+
+func TestDescribe(t *testing.T) {
+	defer func() {
+		for _, name := range []string{"DESCRIBE-SQUARE", "DESCRIBE-AREA", "DESCRIBE-LINES"} {
+			delete(TopLevel.Function[0], instance.NewSymbol(name))
+		}
+		delete(TopLevel.Constant[0], instance.NewSymbol("DESCRIBE-E"))
+		delete(TopLevel.Class[0], instance.NewSymbol("<DESCRIBE-POINT>"))
+	}()
+	execTests(t, Describe, []test{
+		{
+			exp:     `(defun describe-lines (:rest lines) (let ((s (create-string-output-stream))) (mapc (lambda (line) (format s "~A~%" line)) lines) (get-output-stream-string s)))`,
+			want:    `'describe-lines`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((s (create-string-output-stream))) (defun describe-square (x) "Returns the square of X." (* x x)) (describe 'describe-square s) (get-output-stream-string s))`,
+			want:    `(describe-lines "DESCRIBE-SQUARE" "  Function: (DESCRIBE-SQUARE X)" "    Returns the square of X.")`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((s (create-string-output-stream))) (defconstant describe-e 2 "Almost e.") (describe 'describe-e s) (get-output-stream-string s))`,
+			want:    `(describe-lines "DESCRIBE-E" "  Constant: 2" "    Almost e.")`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((s (create-string-output-stream))) (defclass <describe-point> () (x y)) (describe '<describe-point> s) (get-output-stream-string s))`,
+			want:    `(describe-lines "<DESCRIBE-POINT>" "  Class: <DESCRIBE-POINT>" "    Superclasses: <STANDARD-OBJECT>" "    Slots: X Y")`,
+			wantErr: false,
+		},
+		{
+			exp: `(let ((s (create-string-output-stream)))
+			        (defgeneric describe-area (shape))
+			        (defmethod describe-area ((p <describe-point>)) 0)
+			        (defmethod describe-area :around ((p <describe-point>)) (call-next-method))
+			        (describe 'describe-area s)
+			        (get-output-stream-string s))`,
+			want:    `(describe-lines "DESCRIBE-AREA" "  Generic function: (DESCRIBE-AREA SHAPE)" "    Method: (<DESCRIBE-POINT>)" "    Method: :AROUND (<DESCRIBE-POINT>)")`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((s (create-string-output-stream))) (describe 'car s) (describe 'if s) (describe 'describe-nothing s) (describe 1 s) (get-output-stream-string s))`,
+			want:    `(describe-lines "CAR" "  Built-in function taking 1 argument" "IF" "  Special form" "DESCRIBE-NOTHING" "  Not bound" "1" "  Instance of <INTEGER>")`,
+			wantErr: false,
+		},
+		{
+			exp:     `(describe 'car 1)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestApropos(t *testing.T) {
+	defer func() {
+		delete(TopLevel.Function[0], instance.NewSymbol("APROPOS-PROBE"))
+		delete(TopLevel.Variable[0], instance.NewSymbol("*APROPOS-PROBE*"))
+	}()
+	execTests(t, Apropos, []test{
+		{
+			exp:     `(progn (defun apropos-probe () 1) (defglobal *apropos-probe* 1) (apropos "ropos-pro"))`,
+			want:    `'(*apropos-probe* apropos-probe)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(apropos "no such symbol")`,
+			want:    `nil`,
+			wantErr: false,
+		},
+		{
+			exp:     `(apropos 'car)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
@@ -42,7 +42,7 @@ func SetDynamic(e env.Environment, form, var1 ilos.Instance) (ilos.Instance, ilo
 	}
 	form, err := Eval(e, form)
 	if err != nil {
-		return nil, form
+		return nil, err
 	}
 	if e.DynamicVariable.Set(var1, form) {
 		return form, nil
@@ -76,7 +76,7 @@ func DynamicLet(e env.Environment, varForm ilos.Instance, bodyForm ...ilos.Insta
 			return nil, err
 		}
 		if cadr.(instance.List).Length() != 2 {
-		return SignalCondition(e, instance.NewArityError(e), Nil)
+			return SignalCondition(e, instance.NewProgramError(e), Nil)
 		}
 		f, err := Eval(e, cadr.(instance.List).Nth(1))
 		if err != nil {
@@ -86,7 +86,7 @@ func DynamicLet(e env.Environment, varForm ilos.Instance, bodyForm ...ilos.Insta
 	}
 	for v, f := range vfs {
 		if !e.DynamicVariable.Define(v, f) {
-			return SignalCondition(e, instance.NewImmutableBinding(e), Nil)
+			return SignalCondition(e, instance.NewImmutableBinding(e, v), Nil)
 		}
 	}
 	return Progn(e, bodyForm...)
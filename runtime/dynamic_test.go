@@ -0,0 +1,28 @@
+package runtime
+
+import "testing"
+
+func TestSetDynamic(t *testing.T) {
+	execTests(t, SetDynamic, []test{
+		{
+			exp:     `(defdynamic *set-dynamic* 1)`,
+			want:    `'*set-dynamic*`,
+			wantErr: false,
+		},
+		{
+			exp:     `(set-dynamic 2 '*set-dynamic*)`,
+			want:    `2`,
+			wantErr: false,
+		},
+		{
+			exp:     `(set-dynamic '(car 1) '*set-dynamic*)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(set-dynamic 3 '*unbound-dynamic*)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
@@ -5,9 +5,20 @@
 package env
 
 import (
+	"time"
+
 	"github.com/islisp-dev/iris/runtime/ilos"
 )
 
+// Clock is the source of the time functions. Replace the clock of an
+// environment to make timings deterministic, for example in tests.
+type Clock interface {
+	// Now returns the current real time.
+	Now() time.Time
+	// RunTime returns the processor time used by the process so far.
+	RunTime() time.Duration
+}
+
 // Environment struct is the struct for keeping functions and variables
 type Environment struct {
 	// Lexical
@@ -22,6 +33,13 @@ type Environment struct {
 	Special  stack
 	Property map2
 	Constant stack
+	// Documentation maps the names and the kinds of the global definitions,
+	// such as FUNCTION or CLASS, to their documentation strings.
+	Documentation map2
+	// LambdaList maps the names of the functions and the macros defined by
+	// defun and defmacro, with the symbol FUNCTION or MACRO, to their lambda
+	// lists.
+	LambdaList map2
 
 	// Dynamic
 	CatchTag        stack
@@ -30,6 +48,20 @@ type Environment struct {
 	StandardOutput  ilos.Instance
 	ErrorOutput     ilos.Instance
 	Handler         ilos.Instance
+	Clock           Clock
+	// Frame is the innermost function call, which is shown in backtraces.
+	Frame *Frame
+
+	// Declarations of the arguments of the current function call. They are
+	// not inherited by new environments.
+	Declarations []Declaration
+}
+
+// Declaration records that Object was declared to be an instance of Class by
+// the or assure.
+type Declaration struct {
+	Object ilos.Instance
+	Class  ilos.Class
 }
 
 // New creates new eironment
@@ -48,6 +80,8 @@ func NewEnvironment(stdin, stdout, stderr, handler ilos.Instance) Environment {
 	e.Special = NewStack()
 	e.Constant = NewStack()
 	e.Property = NewMap2()
+	e.Documentation = NewMap2()
+	e.LambdaList = NewMap2()
 
 	// Dynamic
 	e.CatchTag = NewStack()
@@ -59,6 +93,9 @@ func NewEnvironment(stdin, stdout, stderr, handler ilos.Instance) Environment {
 	return *e
 }
 
+// MergeLexical takes the lexical bindings of the environment before, where a
+// function was defined, into the environment where it is called. The handler
+// is dynamic, so that of the caller is kept.
 func (e *Environment) MergeLexical(before Environment) {
 	e.BlockTag = before.BlockTag.Append(e.BlockTag[1:])
 	e.TagbodyTag = before.TagbodyTag.Append(e.TagbodyTag[1:])
@@ -70,13 +107,15 @@ func (e *Environment) MergeLexical(before Environment) {
 	e.Special = before.Special.Append(e.Special[1:])
 	e.Constant = before.Constant.Append(e.Constant[1:])
 	e.Property = before.Property
+	e.Documentation = before.Documentation
+	e.LambdaList = before.LambdaList
 
 	e.CatchTag = before.CatchTag.Append(e.CatchTag[1:])
 	e.DynamicVariable = before.DynamicVariable.Append(e.DynamicVariable[1:])
 	e.StandardInput = before.StandardInput
 	e.StandardOutput = before.StandardOutput
 	e.ErrorOutput = before.ErrorOutput
-	e.Handler = before.Handler
+	e.Clock = before.Clock
 }
 
 func (before *Environment) NewLexical() Environment {
@@ -92,6 +131,8 @@ func (before *Environment) NewLexical() Environment {
 	e.Special = before.Special.Append(e.Special)
 	e.Constant = before.Constant.Append(e.Constant)
 	e.Property = before.Property
+	e.Documentation = before.Documentation
+	e.LambdaList = before.LambdaList
 
 	e.CatchTag = before.CatchTag.Append(e.CatchTag)
 	e.DynamicVariable = before.DynamicVariable.Append(e.DynamicVariable)
@@ -99,6 +140,8 @@ func (before *Environment) NewLexical() Environment {
 	e.StandardOutput = before.StandardOutput
 	e.ErrorOutput = before.ErrorOutput
 	e.Handler = before.Handler
+	e.Clock = before.Clock
+	e.Frame = before.Frame
 
 	return e
 }
@@ -116,6 +159,8 @@ func (before *Environment) NewDynamic() Environment {
 	e.Special = stack{before.Special[0]}.Append(e.Special)
 	e.Constant = stack{before.Constant[0]}.Append(e.Constant)
 	e.Property = before.Property
+	e.Documentation = before.Documentation
+	e.LambdaList = before.LambdaList
 
 	e.CatchTag = before.CatchTag.Append(e.CatchTag)
 	e.DynamicVariable = before.DynamicVariable.Append(e.DynamicVariable)
@@ -123,6 +168,8 @@ func (before *Environment) NewDynamic() Environment {
 	e.StandardOutput = before.StandardOutput
 	e.ErrorOutput = before.ErrorOutput
 	e.Handler = before.Handler
+	e.Clock = before.Clock
+	e.Frame = before.Frame
 
 	return e
 }
@@ -0,0 +1,35 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package env
+
+import (
+	"github.com/islisp-dev/iris/runtime/ilos"
+)
+
+// Frame is a function call in progress. The frames are linked to the frames
+// of their callers, so that the innermost frame gives the backtrace.
+type Frame struct {
+	Name      ilos.Instance
+	Arguments []ilos.Instance
+	// Environment is the environment in which the body of the function is
+	// evaluated, or nil if the function is not defined in Lisp.
+	Environment *Environment
+	Parent      *Frame
+}
+
+// PushFrame returns a frame for the call of the function named name with
+// arguments, which is called from the current frame of e.
+func (e *Environment) PushFrame(name ilos.Instance, arguments []ilos.Instance) *Frame {
+	return &Frame{Name: name, Arguments: arguments, Parent: e.Frame}
+}
+
+// Frames returns the frames of e, the innermost first.
+func (e *Environment) Frames() []*Frame {
+	frames := []*Frame{}
+	for f := e.Frame; f != nil; f = f.Parent {
+		frames = append(frames, f)
+	}
+	return frames
+}
@@ -5,12 +5,18 @@
 package runtime
 
 import (
+	"strings"
+
 	"github.com/islisp-dev/iris/runtime/env"
 	"github.com/islisp-dev/iris/runtime/ilos"
 	"github.com/islisp-dev/iris/runtime/ilos/class"
 	"github.com/islisp-dev/iris/runtime/ilos/instance"
 )
 
+var useValue = instance.NewSymbol("USE-VALUE")
+var storeValue = instance.NewSymbol("STORE-VALUE")
+var retry = instance.NewSymbol("RETRY")
+
 func evalArguments(e env.Environment, arguments ilos.Instance) (ilos.Instance, ilos.Instance) {
 	// if arguments ends here
 	if arguments == Nil {
@@ -104,7 +110,9 @@ func evalFunction(e env.Environment, car, cdr ilos.Instance) (ilos.Instance, ilo
 		if err != nil {
 			return nil, err, true
 		}
-		ret, err := fun.(instance.Applicable).Apply(e.NewDynamic(), arguments.(instance.List).Slice()...)
+		d := e.NewDynamic()
+		d.Declarations = declarations(e, cdr, arguments)
+		ret, err := applyFrame(d, car, fun, arguments.(instance.List).Slice())
 		if err != nil {
 			return nil, err, true
 		}
@@ -113,6 +121,26 @@ func evalFunction(e env.Environment, car, cdr ilos.Instance) (ilos.Instance, ilo
 	return nil, nil, false
 }
 
+// declarations collects the classes declared by the or assure forms among the
+// argument forms, so that the callee can skip checking them again.
+func declarations(e env.Environment, forms, arguments ilos.Instance) []env.Declaration {
+	var ds []env.Declaration
+	for ilos.InstanceOf(class.Cons, forms) && ilos.InstanceOf(class.Cons, arguments) {
+		form := forms.(*instance.Cons).Car
+		if ilos.InstanceOf(class.Cons, form) && isProperList(form) && form.(instance.List).Length() == 3 {
+			car := form.(*instance.Cons).Car
+			if car == instance.NewSymbol("THE") || car == instance.NewSymbol("ASSURE") {
+				if c, ok := e.Class[:1].Get(form.(instance.List).Nth(1)); ok {
+					ds = append(ds, env.Declaration{Object: arguments.(*instance.Cons).Car, Class: c.(ilos.Class)})
+				}
+			}
+		}
+		forms = forms.(*instance.Cons).Cdr
+		arguments = arguments.(*instance.Cons).Cdr
+	}
+	return ds
+}
+
 func evalCons(e env.Environment, obj ilos.Instance) (ilos.Instance, ilos.Instance) {
 	if err := ensure(e, class.Cons, obj); err != nil {
 		return nil, err
@@ -124,6 +152,10 @@ func evalCons(e env.Environment, obj ilos.Instance) (ilos.Instance, ilos.Instanc
 	if a, b, c := evalLambda(e, car, cdr); c {
 		return a, b
 	}
+	// the operator of any other form is an identifier
+	if !ilos.InstanceOf(class.Symbol, car) {
+		return SignalCondition(e, instance.NewProgramError(e), Nil)
+	}
 	// get special instance has value of Function interface
 	if a, b, c := evalSpecial(e, car, cdr); c {
 		return a, b
@@ -136,7 +168,30 @@ func evalCons(e env.Environment, obj ilos.Instance) (ilos.Instance, ilos.Instanc
 	if a, b, c := evalFunction(e, car, cdr); c {
 		return a, b
 	}
-	return SignalCondition(e, instance.NewUndefinedFunction(e, car), Nil)
+	index, arguments, err := signalRestartable(e, instance.NewUndefinedFunction(e, car), useValue, storeValue, retry)
+	if index >= 0 && index != 2 {
+		if len(arguments) != 1 {
+			name := []ilos.Instance{useValue, storeValue}[index]
+			return SignalCondition(e, instance.NewArityError(e, name, 1, 1, len(arguments)), Nil)
+		}
+		if err := ensure(e, class.Function, arguments[0]); err != nil {
+			return nil, err
+		}
+	}
+	switch index {
+	case 0: // use-value
+		args, err := evalArguments(e, cdr)
+		if err != nil {
+			return nil, err
+		}
+		return arguments[0].(instance.Applicable).Apply(e.NewDynamic(), args.(instance.List).Slice()...)
+	case 1: // store-value
+		e.Function[:1].Define(car, arguments[0])
+		return evalCons(e, obj)
+	case 2: // retry
+		return evalCons(e, obj)
+	}
+	return nil, err
 }
 
 func evalVariable(e env.Environment, obj ilos.Instance) (ilos.Instance, ilos.Instance) {
@@ -146,7 +201,21 @@ func evalVariable(e env.Environment, obj ilos.Instance) (ilos.Instance, ilos.Ins
 	if val, ok := e.Constant.Get(obj); ok {
 		return val, nil
 	}
-	return SignalCondition(e, instance.NewUndefinedVariable(e, obj), Nil)
+	index, arguments, err := signalRestartable(e, instance.NewUndefinedVariable(e, obj), useValue, storeValue, retry)
+	if index >= 0 && index != 2 && len(arguments) != 1 {
+		name := []ilos.Instance{useValue, storeValue}[index]
+		return SignalCondition(e, instance.NewArityError(e, name, 1, 1, len(arguments)), Nil)
+	}
+	switch index {
+	case 0: // use-value
+		return arguments[0], nil
+	case 1: // store-value
+		e.Variable[:1].Define(obj, arguments[0])
+		return arguments[0], nil
+	case 2: // retry
+		return evalVariable(e, obj)
+	}
+	return nil, err
 }
 
 // Eval evaluates any classs
@@ -155,6 +224,10 @@ func Eval(e env.Environment, obj ilos.Instance) (ilos.Instance, ilos.Instance) {
 		return Nil, nil
 	}
 	if ilos.InstanceOf(class.Symbol, obj) {
+		// Keywords such as :if-exists evaluate to themselves
+		if strings.HasPrefix(string(obj.(instance.Symbol)), ":") {
+			return obj, nil
+		}
 		ret, err := evalVariable(e, obj)
 		if err != nil {
 			return nil, err
@@ -162,6 +235,12 @@ func Eval(e env.Environment, obj ilos.Instance) (ilos.Instance, ilos.Instance) {
 		return ret, nil
 	}
 	if ilos.InstanceOf(class.Cons, obj) {
+		if coverage != nil {
+			coverage.count(obj)
+		}
+		if stepper != nil {
+			return step(e, obj.(*instance.Cons))
+		}
 		ret, err := evalCons(e, obj)
 		if err != nil {
 			return nil, err
@@ -0,0 +1,27 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import "testing"
+
+func TestEval(t *testing.T) {
+	execTests(t, Eval, []test{
+		{
+			exp:     `:if-exists`,
+			want:    `:if-exists`,
+			wantErr: false,
+		},
+		{
+			exp:     `(list :rest :overwrite)`,
+			want:    `'(:rest :overwrite)`,
+			wantErr: false,
+		},
+		{
+			exp:     `undefined-variable-for-eval`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
@@ -0,0 +1,243 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/islisp-dev/iris/runtime/env"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// pathnameOf returns the string x as a path. An error shall be signaled if x
+// is not a string (error-id. domain-error).
+func pathnameOf(e env.Environment, x ilos.Instance) (string, ilos.Instance) {
+	if err := ensure(e, class.String, x); err != nil {
+		return "", err
+	}
+	return string(x.(instance.String)), nil
+}
+
+// signalFileError signals a file error on path for the error of the operating
+// system.
+func signalFileError(e env.Environment, path string, fail error) (ilos.Instance, ilos.Instance) {
+	message := fail.Error()
+	switch fail := fail.(type) {
+	case *os.PathError:
+		message = fail.Err.Error()
+	case *os.LinkError:
+		message = fail.Err.Error()
+	}
+	return SignalCondition(e, instance.NewFileError(e, instance.NewString([]rune(path)), message), Nil)
+}
+
+// ProbeFile returns the absolute path of the file named pathname if it
+// exists; otherwise, returns nil.
+func ProbeFile(e env.Environment, pathname ilos.Instance) (ilos.Instance, ilos.Instance) {
+	path, err := pathnameOf(e, pathname)
+	if err != nil {
+		return nil, err
+	}
+	if _, fail := os.Stat(path); fail != nil {
+		if os.IsNotExist(fail) {
+			return Nil, nil
+		}
+		return signalFileError(e, path, fail)
+	}
+	absolute, fail := filepath.Abs(path)
+	if fail != nil {
+		return signalFileError(e, path, fail)
+	}
+	return instance.NewString([]rune(absolute)), nil
+}
+
+// DeleteFile deletes the file or empty directory named pathname and returns t.
+func DeleteFile(e env.Environment, pathname ilos.Instance) (ilos.Instance, ilos.Instance) {
+	path, err := pathnameOf(e, pathname)
+	if err != nil {
+		return nil, err
+	}
+	if fail := os.Remove(path); fail != nil {
+		return signalFileError(e, path, fail)
+	}
+	return T, nil
+}
+
+// RenameFile renames the file named pathname to newName and returns newName.
+func RenameFile(e env.Environment, pathname, newName ilos.Instance) (ilos.Instance, ilos.Instance) {
+	path, err := pathnameOf(e, pathname)
+	if err != nil {
+		return nil, err
+	}
+	newPath, err := pathnameOf(e, newName)
+	if err != nil {
+		return nil, err
+	}
+	if fail := os.Rename(path, newPath); fail != nil {
+		return signalFileError(e, path, fail)
+	}
+	return newName, nil
+}
+
+// Directory returns the sorted list of the paths matching pattern, in which
+// * matches any sequence of characters except the separator, ? matches a
+// single character and [...] matches a character class.
+func Directory(e env.Environment, pattern ilos.Instance) (ilos.Instance, ilos.Instance) {
+	path, err := pathnameOf(e, pattern)
+	if err != nil {
+		return nil, err
+	}
+	matches, fail := filepath.Glob(path)
+	if fail != nil {
+		return signalFileError(e, path, fail)
+	}
+	sort.Strings(matches)
+	paths := []ilos.Instance{}
+	for _, match := range matches {
+		paths = append(paths, instance.NewString([]rune(match)))
+	}
+	return List(e, paths...)
+}
+
+// EnsureDirectoriesExist creates the directories in pathname which do not
+// exist and returns pathname. The last component of pathname is taken as a
+// file name unless pathname ends with a separator.
+func EnsureDirectoriesExist(e env.Environment, pathname ilos.Instance) (ilos.Instance, ilos.Instance) {
+	path, err := pathnameOf(e, pathname)
+	if err != nil {
+		return nil, err
+	}
+	dir := path
+	if !strings.HasSuffix(path, string(filepath.Separator)) && !strings.HasSuffix(path, "/") {
+		dir = filepath.Dir(path)
+	}
+	if fail := os.MkdirAll(dir, 0777); fail != nil {
+		return signalFileError(e, path, fail)
+	}
+	return pathname, nil
+}
+
+// FileWriteDate returns the time when the file named pathname was last
+// written, in universal time.
+func FileWriteDate(e env.Environment, pathname ilos.Instance) (ilos.Instance, ilos.Instance) {
+	path, err := pathnameOf(e, pathname)
+	if err != nil {
+		return nil, err
+	}
+	info, fail := os.Stat(path)
+	if fail != nil {
+		return signalFileError(e, path, fail)
+	}
+	return instance.NewInteger(int(info.ModTime().Unix()) + universalTimeOffset), nil
+}
+
+// FileSize returns the size in bytes of the file named pathname.
+func FileSize(e env.Environment, pathname ilos.Instance) (ilos.Instance, ilos.Instance) {
+	path, err := pathnameOf(e, pathname)
+	if err != nil {
+		return nil, err
+	}
+	info, fail := os.Stat(path)
+	if fail != nil {
+		return signalFileError(e, path, fail)
+	}
+	return instance.NewInteger(int(info.Size())), nil
+}
+
+// CurrentDirectory returns the absolute path of the current directory.
+func CurrentDirectory(e env.Environment) (ilos.Instance, ilos.Instance) {
+	dir, fail := os.Getwd()
+	if fail != nil {
+		return signalFileError(e, ".", fail)
+	}
+	return instance.NewString([]rune(dir)), nil
+}
+
+// SetCurrentDirectory changes the current directory to pathname and returns
+// its absolute path.
+func SetCurrentDirectory(e env.Environment, pathname ilos.Instance) (ilos.Instance, ilos.Instance) {
+	path, err := pathnameOf(e, pathname)
+	if err != nil {
+		return nil, err
+	}
+	if fail := os.Chdir(path); fail != nil {
+		return signalFileError(e, path, fail)
+	}
+	return CurrentDirectory(e)
+}
+
+// PathJoin joins the strings pathnames with the separator into a single path,
+// which is cleaned by removing redundant separators and dots.
+func PathJoin(e env.Environment, pathnames ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	paths := []string{}
+	for _, pathname := range pathnames {
+		path, err := pathnameOf(e, pathname)
+		if err != nil {
+			return nil, err
+		}
+		paths = append(paths, path)
+	}
+	return instance.NewString([]rune(filepath.Join(paths...))), nil
+}
+
+// PathBasename returns the last component of pathname.
+func PathBasename(e env.Environment, pathname ilos.Instance) (ilos.Instance, ilos.Instance) {
+	path, err := pathnameOf(e, pathname)
+	if err != nil {
+		return nil, err
+	}
+	return instance.NewString([]rune(filepath.Base(path))), nil
+}
+
+// PathDirectory returns pathname without its last component.
+func PathDirectory(e env.Environment, pathname ilos.Instance) (ilos.Instance, ilos.Instance) {
+	path, err := pathnameOf(e, pathname)
+	if err != nil {
+		return nil, err
+	}
+	return instance.NewString([]rune(filepath.Dir(path))), nil
+}
+
+// PathExtension returns the extension of the last component of pathname
+// without the dot, or nil if it has none.
+func PathExtension(e env.Environment, pathname ilos.Instance) (ilos.Instance, ilos.Instance) {
+	path, err := pathnameOf(e, pathname)
+	if err != nil {
+		return nil, err
+	}
+	ext := filepath.Ext(path)
+	if ext == "" {
+		return Nil, nil
+	}
+	return instance.NewString([]rune(ext[1:])), nil
+}
+
+// MergePathnames returns pathname if it is absolute, and otherwise pathname
+// relative to the directory defaults, which defaults to the current
+// directory.
+func MergePathnames(e env.Environment, pathname ilos.Instance, defaults ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	path, err := pathnameOf(e, pathname)
+	if err != nil {
+		return nil, err
+	}
+	if len(defaults) > 1 {
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("MERGE-PATHNAMES"), 1, 2, 1+len(defaults)), Nil)
+	}
+	if filepath.IsAbs(path) {
+		return pathname, nil
+	}
+	var dir ilos.Instance
+	if len(defaults) == 1 {
+		dir = defaults[0]
+	} else if dir, err = CurrentDirectory(e); err != nil {
+		return nil, err
+	}
+	return PathJoin(e, dir, pathname)
+}
@@ -0,0 +1,117 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestFileOperations(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "sub", "a.txt")
+	renamed := filepath.Join(dir, "sub", "b.txt")
+	execTests(t, ProbeFile, []test{
+		{
+			exp:     fmt.Sprintf(`(probe-file %q)`, path),
+			want:    `nil`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(ensure-directories-exist %q)`, path),
+			want:    fmt.Sprintf(`%q`, path),
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(let ((s (open-output-file %q))) (format s "hello") (close s) (probe-file %q))`, path, path),
+			want:    fmt.Sprintf(`%q`, path),
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(file-size %q)`, path),
+			want:    `5`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(< (- (get-universal-time) 60) (file-write-date %q))`, path),
+			want:    `t`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(rename-file %q %q)`, path, renamed),
+			want:    fmt.Sprintf(`%q`, renamed),
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(directory %q)`, filepath.Join(dir, "sub", "*.txt")),
+			want:    fmt.Sprintf(`(list %q)`, renamed),
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(delete-file %q)`, renamed),
+			want:    `t`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(delete-file %q)`, renamed),
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     fmt.Sprintf(`(handler-case (file-size %q) (<stream-error> (c) (list (instancep c (class <file-error>)) (file-error-pathname c))))`, renamed),
+			want:    fmt.Sprintf(`(list t %q)`, renamed),
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(handler-case (open-input-file %q) (<file-error> (c) (file-error-pathname c)))`, renamed),
+			want:    fmt.Sprintf(`%q`, renamed),
+			wantErr: false,
+		},
+		{
+			exp:     `(probe-file 1)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestPathnames(t *testing.T) {
+	execTests(t, PathJoin, []test{
+		{
+			exp:     `(path-join "a" "b/" "c.lsp")`,
+			want:    `"a/b/c.lsp"`,
+			wantErr: false,
+		},
+		{
+			exp:     `(path-basename "a/b/c.lsp")`,
+			want:    `"c.lsp"`,
+			wantErr: false,
+		},
+		{
+			exp:     `(path-directory "a/b/c.lsp")`,
+			want:    `"a/b"`,
+			wantErr: false,
+		},
+		{
+			exp:     `(list (path-extension "a/b/c.lsp") (path-extension "a/b/c"))`,
+			want:    `'("lsp" nil)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(list (merge-pathnames "c.lsp" "/a/b") (merge-pathnames "/c.lsp" "/a/b"))`,
+			want:    `'("/a/b/c.lsp" "/c.lsp")`,
+			wantErr: false,
+		},
+		{
+			exp:     `(merge-pathnames "c.lsp" "/a" "/b")`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
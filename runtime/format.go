@@ -11,51 +11,58 @@ import (
 	"github.com/islisp-dev/iris/runtime/ilos/instance"
 )
 
+// write prints a to stream, which has been checked by ensureStream.
+func write(stream ilos.Instance, a ...interface{}) (ilos.Instance, ilos.Instance) {
+	fmt.Fprint(stream.(instance.Stream), a...)
+	if err := signaled(stream.(instance.Stream)); err != nil {
+		return nil, err
+	}
+	return Nil, nil
+}
+
 func FormatObject(e env.Environment, stream, object, escapep ilos.Instance) (ilos.Instance, ilos.Instance) {
-	if ok, _ := OpenStreamP(e, stream); ok == Nil {
-		return SignalCondition(e, instance.NewDomainError(e, stream, class.Stream), Nil)
+	stream = streamOf(e, stream)
+	if err := ensureStream(e, stream, false); err != nil {
+		return nil, err
 	}
 	if escapep == T {
-		fmt.Fprint(stream.(instance.Stream), object)
-		return Nil, nil
+		return write(stream, object)
 	}
 	if ok, _ := Stringp(e, object); ok == T {
-		fmt.Fprint(stream.(instance.Stream), string(object.(instance.String)))
-		return Nil, nil
+		return write(stream, string(object.(instance.String)))
 	}
 	if ok, _ := Characterp(e, object); ok == T {
-		fmt.Fprint(stream.(instance.Stream), string(object.(instance.Character)))
-		return Nil, nil
+		return write(stream, string(object.(instance.Character)))
 	}
-	fmt.Fprint(stream.(instance.Stream), object)
-	return Nil, nil
+	return write(stream, object)
 }
 
 func FormatChar(e env.Environment, stream, object ilos.Instance) (ilos.Instance, ilos.Instance) {
-	if ok, _ := OpenStreamP(e, stream); ok == Nil {
-		return SignalCondition(e, instance.NewDomainError(e, stream, class.Stream), Nil)
+	stream = streamOf(e, stream)
+	if err := ensureStream(e, stream, false); err != nil {
+		return nil, err
 	}
 	if ok, _ := Characterp(e, object); ok == Nil {
 		return SignalCondition(e, instance.NewDomainError(e, object, class.Character), Nil)
 	}
-	fmt.Fprint(stream.(instance.Stream), string(object.(instance.Character)))
-	return Nil, nil
+	return write(stream, string(object.(instance.Character)))
 }
 
 func FormatFloat(e env.Environment, stream, object ilos.Instance) (ilos.Instance, ilos.Instance) {
-	if ok, _ := OpenStreamP(e, stream); ok == Nil {
-		return SignalCondition(e, instance.NewDomainError(e, stream, class.Stream), Nil)
+	stream = streamOf(e, stream)
+	if err := ensureStream(e, stream, false); err != nil {
+		return nil, err
 	}
 	if ok, _ := Floatp(e, object); ok == Nil {
 		return SignalCondition(e, instance.NewDomainError(e, object, class.Float), Nil)
 	}
-	fmt.Fprint(stream.(instance.Stream), float64(object.(instance.Float)))
-	return Nil, nil
+	return write(stream, float64(object.(instance.Float)))
 }
 
 func FormatInteger(e env.Environment, stream, object, radix ilos.Instance) (ilos.Instance, ilos.Instance) {
-	if ok, _ := OpenStreamP(e, stream); ok == Nil {
-		return SignalCondition(e, instance.NewDomainError(e, stream, class.Stream), Nil)
+	stream = streamOf(e, stream)
+	if err := ensureStream(e, stream, false); err != nil {
+		return nil, err
 	}
 	if ok, _ := Integerp(e, object); ok == Nil {
 		return SignalCondition(e, instance.NewDomainError(e, object, class.Integer), Nil)
@@ -65,14 +72,24 @@ func FormatInteger(e env.Environment, stream, object, radix ilos.Instance) (ilos
 	}
 	i := int(object.(instance.Integer))
 	r := int(radix.(instance.Integer))
-	fmt.Fprint(stream.(instance.Stream), strconv.FormatInt(int64(i), r))
-	return Nil, nil
+	return write(stream, strconv.FormatInt(int64(i), r))
 }
 
 func FormatTab(e env.Environment, stream, num ilos.Instance) (ilos.Instance, ilos.Instance) {
+	stream = streamOf(e, stream)
+	if err := ensureStream(e, stream, false); err != nil {
+		return nil, err
+	}
+	if err := ensure(e, class.Integer, num); err != nil {
+		return nil, err
+	}
 	n := int(num.(instance.Integer))
-	if *stream.(instance.Stream).Column < n {
-		for i := *stream.(instance.Stream).Column; i < n; i++ {
+	column, err := lineColumn(stream.(instance.Stream))
+	if err != nil {
+		return nil, err
+	}
+	if column < n {
+		for i := column; i < n; i++ {
 			if _, err := FormatChar(e, stream, instance.NewCharacter(' ')); err != nil {
 				return nil, err
 			}
@@ -83,7 +100,15 @@ func FormatTab(e env.Environment, stream, num ilos.Instance) (ilos.Instance, ilo
 }
 
 func FormatFreshLine(e env.Environment, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
-	if *stream.(instance.Stream).Column != 0 {
+	stream = streamOf(e, stream)
+	if err := ensureStream(e, stream, false); err != nil {
+		return nil, err
+	}
+	column, err := lineColumn(stream.(instance.Stream))
+	if err != nil {
+		return nil, err
+	}
+	if column != 0 {
 		return FormatChar(e, stream, instance.NewCharacter('\n'))
 	}
 	return Nil, nil
@@ -109,56 +134,56 @@ func Format(e env.Environment, stream, formatString ilos.Instance, formatArgumen
 		switch str[start:end] {
 		case "~A":
 			if index >= len(formatArguments) {
-				_, err = SignalCondition(e, instance.NewArityError(e), Nil)
+				_, err = SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("FORMAT"), index+3, -1, len(formatArguments)+2), Nil)
 			} else {
 				_, err = FormatObject(e, stream, formatArguments[index], Nil)
 				index++
 			}
 		case "~B":
 			if index >= len(formatArguments) {
-				_, err = SignalCondition(e, instance.NewArityError(e), Nil)
+				_, err = SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("FORMAT"), index+3, -1, len(formatArguments)+2), Nil)
 			} else {
 				_, err = FormatInteger(e, stream, formatArguments[index], instance.NewInteger(2))
 				index++
 			}
 		case "~C":
 			if index >= len(formatArguments) {
-				_, err = SignalCondition(e, instance.NewArityError(e), Nil)
+				_, err = SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("FORMAT"), index+3, -1, len(formatArguments)+2), Nil)
 			} else {
 				_, err = FormatChar(e, stream, formatArguments[index])
 				index++
 			}
 		case "~D":
 			if index >= len(formatArguments) {
-				_, err = SignalCondition(e, instance.NewArityError(e), Nil)
+				_, err = SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("FORMAT"), index+3, -1, len(formatArguments)+2), Nil)
 			} else {
 				_, err = FormatInteger(e, stream, formatArguments[index], instance.NewInteger(10))
 				index++
 			}
 		case "~G":
 			if index >= len(formatArguments) {
-				_, err = SignalCondition(e, instance.NewArityError(e), Nil)
+				_, err = SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("FORMAT"), index+3, -1, len(formatArguments)+2), Nil)
 			} else {
 				_, err = FormatFloat(e, stream, formatArguments[index])
 				index++
 			}
 		case "~O":
 			if index >= len(formatArguments) {
-				_, err = SignalCondition(e, instance.NewArityError(e), Nil)
+				_, err = SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("FORMAT"), index+3, -1, len(formatArguments)+2), Nil)
 			} else {
 				_, err = FormatInteger(e, stream, formatArguments[index], instance.NewInteger(8))
 				index++
 			}
 		case "~S":
 			if index >= len(formatArguments) {
-				_, err = SignalCondition(e, instance.NewArityError(e), Nil)
+				_, err = SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("FORMAT"), index+3, -1, len(formatArguments)+2), Nil)
 			} else {
 				_, err = FormatObject(e, stream, formatArguments[index], T)
 				index++
 			}
 		case "~X":
 			if index >= len(formatArguments) {
-				_, err = SignalCondition(e, instance.NewArityError(e), Nil)
+				_, err = SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("FORMAT"), index+3, -1, len(formatArguments)+2), Nil)
 			} else {
 				_, err = FormatInteger(e, stream, formatArguments[index], instance.NewInteger(16))
 				index++
@@ -174,7 +199,7 @@ func Format(e env.Environment, stream, formatString ilos.Instance, formatArgumen
 			if len(s) > 2 {
 				if s[len(s)-1] == 'R' {
 					if index >= len(formatArguments) {
-						_, err = SignalCondition(e, instance.NewArityError(e), Nil)
+						_, err = SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("FORMAT"), index+3, -1, len(formatArguments)+2), Nil)
 					} else {
 						n, _ := strconv.Atoi(s[1 : len(s)-1])
 						if n < 2 || 36 < n {
@@ -98,7 +98,7 @@ func Labels(e env.Environment, functions ilos.Instance, bodyForm ...ilos.Instanc
 		}
 		definition := function.(instance.List).Slice()
 		if len(definition) < 2 {
-		return SignalCondition(e, instance.NewArityError(e), Nil)
+			return SignalCondition(e, instance.NewProgramError(e), Nil)
 		}
 		functionName := definition[0]
 		lambdaList := definition[1]
@@ -108,7 +108,7 @@ func Labels(e env.Environment, functions ilos.Instance, bodyForm ...ilos.Instanc
 			return nil, err
 		}
 		if !e.Function.Define(functionName, fun) {
-			return SignalCondition(e, instance.NewImmutableBinding(e), Nil)
+			return SignalCondition(e, instance.NewImmutableBinding(e, functionName), Nil)
 		}
 	}
 	return Progn(e, bodyForm...)
@@ -127,7 +127,7 @@ func Flet(e env.Environment, functions ilos.Instance, bodyForm ...ilos.Instance)
 		}
 		definition := function.(instance.List).Slice()
 		if len(definition) < 2 {
-		return SignalCondition(e, instance.NewArityError(e), Nil)
+			return SignalCondition(e, instance.NewProgramError(e), Nil)
 		}
 		functionName := definition[0]
 		lambdaList := definition[1]
@@ -137,7 +137,7 @@ func Flet(e env.Environment, functions ilos.Instance, bodyForm ...ilos.Instance)
 			return nil, err
 		}
 		if !newEnv.Function.Define(functionName, fun) {
-			return SignalCondition(e, instance.NewImmutableBinding(e), Nil)
+			return SignalCondition(e, instance.NewImmutableBinding(e, functionName), Nil)
 		}
 	}
 	return Progn(newEnv, bodyForm...)
@@ -152,6 +152,9 @@ func Apply(e env.Environment, function ilos.Instance, obj ...ilos.Instance) (ilo
 	if err := ensure(e, class.Function, function); err != nil {
 		return nil, err
 	}
+	if len(obj) == 0 {
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("APPLY"), 2, -1, 1), Nil)
+	}
 	if err := ensure(e, class.List, obj[len(obj)-1]); err != nil {
 		return nil, err
 	}
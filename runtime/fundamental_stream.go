@@ -0,0 +1,148 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"errors"
+	"io"
+	"unicode/utf8"
+
+	"github.com/islisp-dev/iris/runtime/env"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+var fundamentalStreamSlot = instance.NewSymbol("IRIS.STREAM")
+var eof = instance.NewSymbol(":EOF")
+
+// errSignaled is returned by a fundamentalStream to stop the reader or writer
+// when a method signaled a condition, which is kept in err.
+var errSignaled = errors.New("condition signaled")
+
+// fundamentalStream reads and writes an instance of <fundamental-stream> by
+// calling the generic functions of the stream protocol on it.
+type fundamentalStream struct {
+	e      env.Environment
+	object ilos.Instance
+	err    ilos.Instance
+}
+
+func (f *fundamentalStream) call(name string, arguments ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	generic, _ := f.e.Function.Get(instance.NewSymbol(name))
+	return generic.(instance.Applicable).Apply(f.e.NewDynamic(), append([]ilos.Instance{f.object}, arguments...)...)
+}
+
+// Read reads a single character with stream-read-char, which returns :eof at
+// the end of the stream.
+func (f *fundamentalStream) Read(p []byte) (int, error) {
+	ret, err := f.call("STREAM-READ-CHAR")
+	if err != nil {
+		f.err = err
+		return 0, errSignaled
+	}
+	if ret == eof {
+		return 0, io.EOF
+	}
+	c, ok := ret.(instance.Character)
+	if !ok || len(p) < utf8.RuneLen(rune(c)) {
+		_, f.err = SignalCondition(f.e, instance.NewDomainError(f.e, ret, class.Character), Nil)
+		return 0, errSignaled
+	}
+	return utf8.EncodeRune(p, rune(c)), nil
+}
+
+// Write writes p with stream-write-string.
+func (f *fundamentalStream) Write(p []byte) (int, error) {
+	if _, err := f.call("STREAM-WRITE-STRING", instance.NewString([]rune(string(p)))); err != nil {
+		f.err = err
+		return 0, errSignaled
+	}
+	return len(p), nil
+}
+
+// streamOf returns the Stream which reads and writes obj if obj is an
+// instance of <fundamental-stream>, and obj itself otherwise. The Stream is
+// kept in obj, so that a character read ahead is not lost between calls.
+func streamOf(e env.Environment, obj ilos.Instance) ilos.Instance {
+	object, ok := obj.(instance.Instance)
+	if !ok || !ilos.InstanceOf(class.FundamentalStream, obj) {
+		return obj
+	}
+	if s, ok := object.GetSlotValue(fundamentalStreamSlot, class.FundamentalStream); ok {
+		s.(instance.Stream).Writer.(*fundamentalStream).e = e
+		return s
+	}
+	f := &fundamentalStream{e: e, object: obj}
+	s := instance.NewStream(f, f)
+	object.SetSlotValue(fundamentalStreamSlot, s, class.FundamentalStream)
+	return s
+}
+
+// signaled returns the condition signaled by a method called through s, if
+// any, and forgets it.
+func signaled(s instance.Stream) ilos.Instance {
+	f, ok := s.Writer.(*fundamentalStream)
+	if !ok || f.err == nil {
+		return nil
+	}
+	err := f.err
+	f.err = nil
+	return err
+}
+
+// lineColumn returns the column of the next character written to s, as told
+// by stream-line-column for a <fundamental-stream> if it knows.
+func lineColumn(s instance.Stream) (int, ilos.Instance) {
+	if f, ok := s.Writer.(*fundamentalStream); ok {
+		column, err := f.call("STREAM-LINE-COLUMN")
+		if err != nil {
+			return 0, err
+		}
+		if c, ok := column.(instance.Integer); ok {
+			return int(c), nil
+		}
+	}
+	return *s.Column, nil
+}
+
+// StreamReadChar is the default method of stream-read-char, which has to be
+// implemented by an input stream.
+func StreamReadChar(e env.Environment, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return SignalCondition(e, instance.NewStreamError(e, stream), Nil)
+}
+
+// StreamWriteChar is the default method of stream-write-char, which has to be
+// implemented by an output stream.
+func StreamWriteChar(e env.Environment, stream, character ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return SignalCondition(e, instance.NewStreamError(e, stream), Nil)
+}
+
+// StreamWriteString is the default method of stream-write-string, which writes
+// the characters of string one by one with stream-write-char.
+func StreamWriteString(e env.Environment, stream, str ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.String, str); err != nil {
+		return nil, err
+	}
+	generic, _ := e.Function.Get(instance.NewSymbol("STREAM-WRITE-CHAR"))
+	for _, c := range str.(instance.String) {
+		if _, err := generic.(instance.Applicable).Apply(e.NewDynamic(), stream, instance.NewCharacter(c)); err != nil {
+			return nil, err
+		}
+	}
+	return str, nil
+}
+
+// StreamLineColumn is the default method of stream-line-column, which returns
+// nil to tell that the column is counted by the writer.
+func StreamLineColumn(e env.Environment, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return Nil, nil
+}
+
+// StreamFinishOutput is the default method of stream-finish-output, which
+// does nothing.
+func StreamFinishOutput(e env.Environment, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return Nil, nil
+}
@@ -0,0 +1,118 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import "testing"
+
+func TestFundamentalStream(t *testing.T) {
+	execTests(t, Format, []test{
+		{
+			exp: `
+			(defclass <doubling-stream> (<fundamental-stream>)
+				((out :initarg out :reader doubling-stream-out)
+				 (finished :initform nil :accessor doubling-stream-finished)))
+			`,
+			want:    `'<doubling-stream>`,
+			wantErr: false,
+		},
+		{
+			exp: `
+			(defmethod stream-write-char ((s <doubling-stream>) c)
+				(format-char (doubling-stream-out s) c)
+				(format-char (doubling-stream-out s) c))
+			`,
+			want:    `'stream-write-char`,
+			wantErr: false,
+		},
+		{
+			exp: `
+			(defmethod stream-finish-output ((s <doubling-stream>))
+				(setf (doubling-stream-finished s) t))
+			`,
+			want:    `'stream-finish-output`,
+			wantErr: false,
+		},
+		{
+			exp: `
+			(let ((o (create-string-output-stream)))
+				(with-standard-output (create (class <doubling-stream>) 'out o)
+					(format (standard-output) "a~D~5Tc" 12))
+				(get-output-stream-string o))
+			`,
+			want:    `"aa1122    cc"`,
+			wantErr: false,
+		},
+		{
+			exp: `
+			(let ((s (create (class <doubling-stream>) 'out (create-string-output-stream))))
+				(list (streamp s) (output-stream-p s) (open-stream-p s)
+				      (progn (finish-output s) (doubling-stream-finished s))
+				      (progn (close s) (open-stream-p s))))
+			`,
+			want:    `'(t t t t nil)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(format (create (class <doubling-stream>) 'out 1) "a")`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(read-char (create (class <doubling-stream>) 'out 1))`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp: `
+			(defclass <list-stream> (<fundamental-stream>)
+				((chars :initarg chars :accessor list-stream-chars)))
+			`,
+			want:    `'<list-stream>`,
+			wantErr: false,
+		},
+		{
+			exp: `
+			(defmethod stream-read-char ((s <list-stream>))
+				(if (null (list-stream-chars s))
+					:eof
+					(let ((c (car (list-stream-chars s))))
+						(setf (list-stream-chars s) (cdr (list-stream-chars s)))
+						c)))
+			`,
+			want:    `'stream-read-char`,
+			wantErr: false,
+		},
+		{
+			exp: `
+			(let ((s (create (class <list-stream>) 'chars (list #\( #\a #\space #\b #\) #\newline #\x #\y))))
+				(list (read s) (read-line s) (preview-char s) (read-line s) (read-line s nil 'eof)))
+			`,
+			want:    `'((a b) "" #\x "xy" eof)`,
+			wantErr: false,
+		},
+		{
+			exp: `
+			(let ((s (create (class <list-stream>) 'chars (list #\a 1))))
+				(list (read-char s) (catch 'c (with-handler (lambda (c) (throw 'c 'error)) (read-char s)))))
+			`,
+			want:    `'(#\a error)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(defmethod stream-line-column ((s <doubling-stream>)) 0)`,
+			want:    `'stream-line-column`,
+			wantErr: false,
+		},
+		{
+			exp: `
+			(let ((o (create-string-output-stream)))
+				(format (create (class <doubling-stream>) 'out o) "ab~&c")
+				(get-output-stream-string o))
+			`,
+			want:    `"aabbcc"`,
+			wantErr: false,
+		},
+	})
+}
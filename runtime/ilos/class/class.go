@@ -55,3 +55,13 @@ var CatchTag = instance.CatchTagClass
 var TagbodyTag = instance.TagbodyTagClass
 var BlockTag = instance.BlockTagClass
 var Continue = instance.ContinueClass
+var Exit = instance.ExitClass
+var Restart = instance.RestartClass
+var ArityError = instance.ArityErrorClass
+var IndexOutOfRange = instance.IndexOutOfRangeClass
+var ImmutableBinding = instance.ImmutableBindingClass
+var InternalError = instance.InternalErrorClass
+var FileError = instance.FileErrorClass
+var Process = instance.ProcessClass
+var AssertionFailure = instance.AssertionFailureClass
+var FundamentalStream = instance.FundamentalStreamClass
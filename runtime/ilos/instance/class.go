@@ -38,20 +38,34 @@ var FloatingPointUnderflowClass = NewBuiltInClass("<FLOATING-POINT-UNDERFLOW>",
 var ControlErrorClass = NewBuiltInClass("<CONTROL-ERROR>", ErrorClass)
 var ParseErrorClass = NewBuiltInClass("<PARSE-ERROR>", ErrorClass, "STRING", "EXPECTED-CLASS")
 var ProgramErrorClass = NewBuiltInClass("<PROGRAM-ERROR>", ErrorClass)
-var DomainErrorClass = NewBuiltInClass("<DOMAIN-ERROR>", ProgramErrorClass, "IRIS.OBJECT", "EXPECTED-CLASS")
+var DomainErrorClass = NewBuiltInClass("<DOMAIN-ERROR>", ProgramErrorClass, "IRIS.OBJECT", "EXPECTED-CLASS", "IRIS.EXPECTED")
 var UndefinedEntityClass = NewBuiltInClass("<UNDEFINED-ENTITY>", ProgramErrorClass, "NAME", "NAMESPACE")
 var UndefinedVariableClass = NewBuiltInClass("<UNDEFINED-VARIABLE>", UndefinedEntityClass)
 var UndefinedFunctionClass = NewBuiltInClass("<UNDEFINED-FUNCTION>", UndefinedEntityClass)
 var SimpleErrorClass = NewBuiltInClass("<SIMPLE-ERROR>", ErrorClass, "FORMAT-STRING", "FORMAT-ARGUMENTS")
-var StreamErrorClass = NewBuiltInClass("<STREAM-ERROR>", ErrorClass)
+var StreamErrorClass = NewBuiltInClass("<STREAM-ERROR>", ErrorClass, "STREAM")
 var EndOfStreamClass = NewBuiltInClass("<END-OF-STREAM>", StreamErrorClass)
 var StorageExhaustedClass = NewBuiltInClass("<STORAGE-EXHAUSTED>", SeriousConditionClass)
 var StandardObjectClass = NewBuiltInClass("<STANDARD-OBJECT>", ObjectClass)
 var StreamClass = NewBuiltInClass("<STREAM>", ObjectClass, "STREAM")
 
 // Implementation defined
-var EscapeClass = NewBuiltInClass("<ESCAPE>", ObjectClass, "IRIS.TAG")
+var EscapeClass = NewBuiltInClass("<ESCAPE>", ObjectClass, "IRIS.TAG", "IRIS.UID")
 var CatchTagClass = NewBuiltInClass("<THROW>", EscapeClass, "IRIS.OBJECT")
 var TagbodyTagClass = NewBuiltInClass("<TAGBODY-TAG>", EscapeClass)
 var BlockTagClass = NewBuiltInClass("<BLOCK-TAG>", EscapeClass, "IRIS.OBJECT")
 var ContinueClass = NewBuiltInClass("<CONTINUE>", EscapeClass, "IRIS.OBJECT")
+var ExitClass = NewBuiltInClass("<EXIT>", EscapeClass, "IRIS.OBJECT")
+var RestartClass = NewBuiltInClass("<RESTART>", ObjectClass)
+var ArityErrorClass = NewBuiltInClass("<ARITY-ERROR>", ProgramErrorClass, "NAME", "EXPECTED-MIN", "EXPECTED-MAX", "ACTUAL")
+var IndexOutOfRangeClass = NewBuiltInClass("<INDEX-OUT-OF-RANGE>", ProgramErrorClass, "SEQUENCE", "INDEX")
+var ImmutableBindingClass = NewBuiltInClass("<IMMUTABLE-BINDING>", ProgramErrorClass, "NAME")
+var InternalErrorClass = NewBuiltInClass("<INTERNAL-ERROR>", ProgramErrorClass, "NAME", "MESSAGE")
+var FileErrorClass = NewBuiltInClass("<FILE-ERROR>", StreamErrorClass, "PATHNAME", "MESSAGE")
+var ProcessClass = NewBuiltInClass("<PROCESS>", ObjectClass)
+var AssertionFailureClass = NewBuiltInClass("<ASSERTION-FAILURE>", SimpleErrorClass)
+
+// FundamentalStreamClass is the superclass of the streams defined in Lisp by
+// methods on the generic functions stream-read-char, stream-write-string, and
+// so on. The slot IRIS.STREAM holds the Stream which calls them.
+var FundamentalStreamClass = NewStandardClass(NewSymbol("<FUNDAMENTAL-STREAM>"), []ilos.Class{StandardObjectClass, StreamClass}, []ilos.Instance{NewSymbol("IRIS.STREAM")}, map[ilos.Instance]ilos.Instance{}, map[ilos.Instance]ilos.Instance{}, StandardClassClass, T)
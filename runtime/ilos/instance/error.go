@@ -9,6 +9,17 @@ import (
 	"github.com/islisp-dev/iris/runtime/ilos"
 )
 
+// signal passes the non-continuable condition to the active handler. It is
+// used where the SignalCondition of the runtime package is out of reach.
+func signal(e env.Environment, condition ilos.Instance) (ilos.Instance, ilos.Instance) {
+	condition.(Instance).SetSlotValue(NewSymbol("IRIS.CONTINUABLE"), Nil, SeriousConditionClass)
+	if e.Handler == nil {
+		return nil, condition
+	}
+	_, err := e.Handler.(Applicable).Apply(e, condition)
+	return nil, err
+}
+
 func NewArithmeticError(e env.Environment, operation, operands ilos.Instance) ilos.Instance {
 	return Create(e, ArithmeticErrorClass,
 		NewSymbol("OPERATION"), operation,
@@ -27,6 +38,16 @@ func NewParseError(e env.Environment, str, expectedClass ilos.Instance) ilos.Ins
 		NewSymbol("EXPECTED-CLASS"), expectedClass)
 }
 
+// NewDomainErrorOneOf returns the domain-error of object, which is not one of
+// the list of the objects expected of expectedClass.
+func NewDomainErrorOneOf(e env.Environment, object ilos.Instance, expectedClass ilos.Class, expected ilos.Instance) ilos.Instance {
+	return Create(e, DomainErrorClass,
+		NewSymbol("CAUSE"), NewSymbol("DOMAIN-ERROR"),
+		NewSymbol("IRIS.OBJECT"), object,
+		NewSymbol("EXPECTED-CLASS"), expectedClass,
+		NewSymbol("IRIS.EXPECTED"), expected)
+}
+
 func NewDomainError(e env.Environment, object ilos.Instance, expectedClass ilos.Class) ilos.Instance {
 	return Create(e, DomainErrorClass,
 		NewSymbol("CAUSE"), NewSymbol("DOMAIN-ERROR"),
@@ -52,16 +73,57 @@ func NewUndefinedClass(e env.Environment, name ilos.Instance) ilos.Instance {
 		NewSymbol("NAMESPACE"), NewSymbol("CLASS"))
 }
 
-func NewArityError(e env.Environment) ilos.Instance {
-	return Create(e, ProgramErrorClass)
+func NewUndefinedEntity(e env.Environment, name, namespace ilos.Instance) ilos.Instance {
+	return Create(e, UndefinedEntityClass,
+		NewSymbol("NAME"), name,
+		NewSymbol("NAMESPACE"), namespace)
 }
 
-func NewIndexOutOfRange(e env.Environment) ilos.Instance {
+func NewProgramError(e env.Environment) ilos.Instance {
 	return Create(e, ProgramErrorClass)
 }
 
-func NewImmutableBinding(e env.Environment) ilos.Instance {
-	return Create(e, ProgramErrorClass)
+// NewArityError returns an arity error for name, which accepts between min and
+// max arguments but was given actual. A negative max means that any number of
+// arguments from min is accepted.
+func NewArityError(e env.Environment, name ilos.Instance, min, max, actual int) ilos.Instance {
+	var expectedMax ilos.Instance = Nil
+	if max >= 0 {
+		expectedMax = NewInteger(max)
+	}
+	return Create(e, ArityErrorClass,
+		NewSymbol("NAME"), name,
+		NewSymbol("EXPECTED-MIN"), NewInteger(min),
+		NewSymbol("EXPECTED-MAX"), expectedMax,
+		NewSymbol("ACTUAL"), NewInteger(actual))
+}
+
+func NewIndexOutOfRange(e env.Environment, sequence, index ilos.Instance) ilos.Instance {
+	return Create(e, IndexOutOfRangeClass,
+		NewSymbol("SEQUENCE"), sequence,
+		NewSymbol("INDEX"), index)
+}
+
+func NewImmutableBinding(e env.Environment, name ilos.Instance) ilos.Instance {
+	return Create(e, ImmutableBindingClass,
+		NewSymbol("NAME"), name)
+}
+
+// NewInternalError returns an error which reports that the builtin name
+// panicked with message instead of signaling a proper condition.
+func NewInternalError(e env.Environment, name ilos.Instance, message string) ilos.Instance {
+	return Create(e, InternalErrorClass,
+		NewSymbol("NAME"), name,
+		NewSymbol("MESSAGE"), NewString([]rune(message)))
+}
+
+// NewFileError returns an error which reports that the operating system failed
+// to operate on the file named pathname with message.
+func NewFileError(e env.Environment, pathname ilos.Instance, message string) ilos.Instance {
+	return Create(e, FileErrorClass,
+		NewSymbol("STREAM"), Nil,
+		NewSymbol("PATHNAME"), pathname,
+		NewSymbol("MESSAGE"), NewString([]rune(message)))
 }
 
 func NewSimpleError(e env.Environment, formatString, formatArguments ilos.Instance) ilos.Instance {
@@ -70,10 +132,24 @@ func NewSimpleError(e env.Environment, formatString, formatArguments ilos.Instan
 		NewSymbol("FORMAT-ARGUMENTS"), formatArguments)
 }
 
+// NewAssertionFailure returns an error which reports that an assertion of a
+// test failed, described by formatString and formatArguments.
+func NewAssertionFailure(e env.Environment, formatString, formatArguments ilos.Instance) ilos.Instance {
+	return Create(e, AssertionFailureClass,
+		NewSymbol("FORMAT-STRING"), formatString,
+		NewSymbol("FORMAT-ARGUMENTS"), formatArguments)
+}
+
 func NewControlError(e env.Environment) ilos.Instance {
 	return Create(e, ControlErrorClass)
 }
 
-func NewStreamError(e env.Environment) ilos.Instance {
-	return Create(e, StreamErrorClass)
+func NewStreamError(e env.Environment, stream ilos.Instance) ilos.Instance {
+	return Create(e, StreamErrorClass,
+		NewSymbol("STREAM"), stream)
+}
+
+func NewEndOfStream(e env.Environment, stream ilos.Instance) ilos.Instance {
+	return Create(e, EndOfStreamClass,
+		NewSymbol("STREAM"), stream)
 }
@@ -30,20 +30,46 @@ func (Function) Class() ilos.Class {
 	return FunctionClass
 }
 
+// Name returns the name given to f when it was made.
+func (f Function) Name() ilos.Instance {
+	return f.name
+}
+
+// Function returns the Go function which f applies.
+func (f Function) Function() interface{} {
+	return f.function
+}
+
 func (f Function) String() string {
 	return fmt.Sprintf("#%v", f.Class())
 }
 
-func (f Function) Apply(e env.Environment, arguments ...ilos.Instance) (ilos.Instance, ilos.Instance) {
-	fv := reflect.ValueOf(f.function)
+// Arity returns the minimum and the maximum numbers of the arguments of f.
+// The maximum is -1 if any number of arguments may follow the minimum.
+func (f Function) Arity() (int, int) {
 	ft := reflect.TypeOf(f.function)
+	if ft.IsVariadic() {
+		return ft.NumIn() - 2, -1
+	}
+	return ft.NumIn() - 1, ft.NumIn() - 1
+}
+
+func (f Function) Apply(e env.Environment, arguments ...ilos.Instance) (ret, err ilos.Instance) {
+	fv := reflect.ValueOf(f.function)
 	argv := []reflect.Value{reflect.ValueOf(e)}
 	for _, cadr := range arguments {
 		argv = append(argv, reflect.ValueOf(cadr))
 	}
-	if ft.NumIn() != len(argv) && (!ft.IsVariadic() || ft.NumIn()-2 >= len(argv)) {
-		return nil, NewArityError(e)
+	if min, max := f.Arity(); len(arguments) < min || max >= 0 && len(arguments) > max {
+		return signal(e, NewArityError(e, f.name, min, max, len(arguments)))
 	}
+	defer func() {
+		// A builtin which fails to check its arguments must not take the
+		// whole process down, so its panic is signaled as an internal error.
+		if r := recover(); r != nil {
+			ret, err = signal(e, NewInternalError(e, f.name, fmt.Sprint(r)))
+		}
+	}()
 	rets := fv.Call(argv)
 	a, _ := rets[0].Interface().(ilos.Instance)
 	b, _ := rets[1].Interface().(ilos.Instance)
@@ -69,6 +95,11 @@ func NewGenericFunction(funcSpec, lambdaList, methodCombination ilos.Instance, g
 	return &GenericFunction{funcSpec, lambdaList, methodCombination, genericFunctionClass, []method{}}
 }
 
+// LambdaList returns the lambda list given to f when it was made.
+func (f *GenericFunction) LambdaList() ilos.Instance {
+	return f.lambdaList
+}
+
 func (f *GenericFunction) AddMethod(qualifier, lambdaList ilos.Instance, classList []ilos.Class, function ilos.Instance) bool {
 	if f.lambdaList.(List).Length() != lambdaList.(List).Length() {
 		return false
@@ -90,6 +121,15 @@ func (f *GenericFunction) AddMethod(qualifier, lambdaList ilos.Instance, classLi
 	return true
 }
 
+// MapMethods replaces the function of each method of f with the result of
+// calling fn with the qualifier, the classes of the parameters and the function
+// of the method.
+func (f *GenericFunction) MapMethods(fn func(qualifier ilos.Instance, classList []ilos.Class, function ilos.Instance) ilos.Instance) {
+	for i, method := range f.methods {
+		f.methods[i].function = fn(method.qualifier, method.classList, method.function).(Function)
+	}
+}
+
 func (f *GenericFunction) Class() ilos.Class {
 	return f.genericFunctionClass
 }
@@ -108,7 +148,11 @@ func (f *GenericFunction) Apply(e env.Environment, arguments ...ilos.Instance) (
 		}
 	}
 	if (variadic && len(parameters)-2 > len(arguments)) || (!variadic && len(parameters) != len(arguments)) {
-		return nil, NewArityError(e)
+		min, max := len(parameters), len(parameters)
+		if variadic {
+			min, max = len(parameters)-2, -1
+		}
+		return signal(e, NewArityError(e, f.funcSpec, min, max, len(arguments)))
 	}
 	methods := []method{}
 	for _, method := range f.methods {
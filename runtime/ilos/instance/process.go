@@ -0,0 +1,68 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package instance
+
+import (
+	"fmt"
+	"os/exec"
+	"time"
+
+	"github.com/islisp-dev/iris/runtime/ilos"
+)
+
+// Process
+
+type Process struct {
+	Cmd    *exec.Cmd
+	Input  ilos.Instance // stream to the standard input of the process or nil
+	Output ilos.Instance // stream from the standard output of the process or nil
+	Error  ilos.Instance // stream from the standard error of the process or nil
+	done   chan struct{}
+}
+
+// NewProcess returns the process of cmd, which has been started, and waits
+// for it in the background.
+func NewProcess(cmd *exec.Cmd, input, output, error ilos.Instance) ilos.Instance {
+	p := &Process{cmd, input, output, error, make(chan struct{})}
+	go func() {
+		cmd.Wait()
+		close(p.done)
+	}()
+	return p
+}
+
+// Wait waits for the process to exit, but no longer than timeout unless it is
+// negative, and reports whether the process has exited.
+func (p *Process) Wait(timeout time.Duration) bool {
+	if timeout < 0 {
+		<-p.done
+		return true
+	}
+	select {
+	case <-p.done:
+		return true
+	case <-time.After(timeout):
+		return false
+	}
+}
+
+// ExitCode returns the exit code of the process, which is -1 if the process
+// was terminated by a signal, or false if the process has not exited yet.
+func (p *Process) ExitCode() (int, bool) {
+	select {
+	case <-p.done:
+		return p.Cmd.ProcessState.ExitCode(), true
+	default:
+		return 0, false
+	}
+}
+
+func (*Process) Class() ilos.Class {
+	return ProcessClass
+}
+
+func (p *Process) String() string {
+	return fmt.Sprintf("#<PROCESS %v>", p.Cmd.Process.Pid)
+}
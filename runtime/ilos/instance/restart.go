@@ -0,0 +1,33 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package instance
+
+import (
+	"fmt"
+
+	"github.com/islisp-dev/iris/runtime/ilos"
+)
+
+// Restart
+
+type Restart struct {
+	Name        ilos.Instance
+	Report      ilos.Instance
+	Function    ilos.Instance // applied to the arguments of invoke-restart
+	Interactive ilos.Instance // returns the arguments of interactive invocation
+	Condition   ilos.Instance // associated condition or nil
+}
+
+func NewRestart(name, report, function, interactive, condition ilos.Instance) ilos.Instance {
+	return &Restart{name, report, function, interactive, condition}
+}
+
+func (*Restart) Class() ilos.Class {
+	return RestartClass
+}
+
+func (r *Restart) String() string {
+	return fmt.Sprintf("#<RESTART %v>", r.Name)
+}
@@ -5,7 +5,9 @@
 package instance
 
 import (
+	"bufio"
 	"io"
+	"os"
 	"strings"
 
 	"github.com/islisp-dev/iris/reader/tokenizer"
@@ -16,13 +18,76 @@ type Stream struct {
 	Column *int
 	Reader *tokenizer.Reader
 	Writer io.Writer
+	// Bytes reads the input of a binary stream, whose elements are 8-bit
+	// integers instead of characters. Reader is nil then.
+	Bytes  *bufio.Reader
+	Binary bool
+	// File is the file underlying a file stream, or nil.
+	File *os.File
+	// Closed is shared by all copies of the stream and set by Close.
+	Closed *bool
 }
 
 func NewStream(r io.Reader, w io.Writer) ilos.Instance {
 	if r == nil {
-		return Stream{new(int), nil, w}
+		return Stream{Column: new(int), Writer: w, Closed: new(bool)}
 	}
-	return Stream{new(int), tokenizer.NewReader(r), w}
+	return Stream{Column: new(int), Reader: tokenizer.NewReader(r), Writer: w, Closed: new(bool)}
+}
+
+// NewFileStream returns a stream on file for input, output or both. The
+// elements of the stream are 8-bit integers if binary is true, or characters
+// otherwise.
+func NewFileStream(file *os.File, input, output, binary bool) ilos.Instance {
+	s := Stream{Column: new(int), Binary: binary, File: file, Closed: new(bool)}
+	if input && binary {
+		s.Bytes = bufio.NewReader(file)
+	}
+	if input && !binary {
+		s.Reader = tokenizer.NewReader(file)
+	}
+	// The output is buffered unless the same file is also read, where the
+	// buffered output would be out of sync with the input.
+	if output && input {
+		s.Writer = file
+	}
+	if output && !input {
+		s.Writer = bufio.NewWriter(file)
+	}
+	return s
+}
+
+// Flush writes any buffered output to the underlying writer.
+func (s Stream) Flush() error {
+	if w, ok := s.Writer.(*bufio.Writer); ok {
+		return w.Flush()
+	}
+	return nil
+}
+
+// Buffered returns the number of bytes of output which have not been written
+// to the underlying writer yet.
+func (s Stream) Buffered() int {
+	if w, ok := s.Writer.(*bufio.Writer); ok {
+		return w.Buffered()
+	}
+	return 0
+}
+
+// Close flushes the stream and releases the underlying file, if any. Closing
+// a closed stream has no effect.
+func (s Stream) Close() error {
+	if *s.Closed {
+		return nil
+	}
+	*s.Closed = true
+	err := s.Flush()
+	if s.File != nil {
+		if fail := s.File.Close(); err == nil {
+			err = fail
+		}
+	}
+	return err
 }
 
 func (Stream) Class() ilos.Class {
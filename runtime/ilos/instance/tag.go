@@ -29,3 +29,11 @@ func NewTagbodyTag(tag, uid ilos.Instance) ilos.Instance {
 		NewSymbol("IRIS.TAG"), tag,
 		NewSymbol("IRIS.UID"), uid)
 }
+
+// NewExit returns an escape which terminates the program with status after the
+// cleanup forms of the active unwind-protect forms are evaluated.
+func NewExit(status ilos.Instance) ilos.Instance {
+	return Create(env.NewEnvironment(nil, nil, nil, nil),
+		ExitClass,
+		NewSymbol("IRIS.OBJECT"), status)
+}
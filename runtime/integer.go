@@ -46,7 +46,7 @@ func Div(e env.Environment, z1, z2 ilos.Instance) (ilos.Instance, ilos.Instance)
 		if err != nil {
 			return nil, err
 		}
-		return SignalCondition(e, instance.NewArithmeticError(e, operation, operands), Nil)
+		return SignalCondition(e, instance.NewDivisionByZero(e, operation, operands), Nil)
 	}
 	if a*b < 0 { // Issue #2
 		return instance.NewInteger(a/b - 1), nil
@@ -112,6 +112,9 @@ func Lcm(e env.Environment, z1, z2 ilos.Instance) (ilos.Instance, ilos.Instance)
 	if err != nil {
 		return nil, err
 	}
+	if a == 0 || b == 0 {
+		return instance.NewInteger(0), nil
+	}
 	return instance.NewInteger(a * b / gcd(a, b)), nil
 }
 
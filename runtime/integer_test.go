@@ -0,0 +1,23 @@
+package runtime
+
+import "testing"
+
+func TestLcm(t *testing.T) {
+	execTests(t, Lcm, []test{
+		{
+			exp:     `(lcm 4 6)`,
+			want:    `12`,
+			wantErr: false,
+		},
+		{
+			exp:     `(lcm 0 5)`,
+			want:    `0`,
+			wantErr: false,
+		},
+		{
+			exp:     `(lcm 0 0)`,
+			want:    `0`,
+			wantErr: false,
+		},
+	})
+}
@@ -71,10 +71,10 @@ func For(e env.Environment, iterationSpecs, endTestAndResults ilos.Instance, for
 				return nil, err
 			}
 			if !a.Variable.Define(var1, init) {
-				return SignalCondition(e, instance.NewImmutableBinding(e), Nil)
+				return SignalCondition(e, instance.NewImmutableBinding(e, var1), Nil)
 			}
 		default:
-			return SignalCondition(e, instance.NewArityError(e), Nil)
+			return SignalCondition(e, instance.NewProgramError(e), Nil)
 		}
 	}
 	if err := ensure(e, class.List, endTestAndResults); err != nil {
@@ -82,7 +82,7 @@ func For(e env.Environment, iterationSpecs, endTestAndResults ilos.Instance, for
 	}
 	ends := endTestAndResults.(instance.List).Slice()
 	if len(ends) == 0 {
-		return SignalCondition(e, instance.NewArityError(e), Nil)
+		return SignalCondition(e, instance.NewProgramError(e), Nil)
 	}
 	endTest := ends[0]
 	results := ends[1:]
@@ -109,10 +109,10 @@ func For(e env.Environment, iterationSpecs, endTestAndResults ilos.Instance, for
 					return nil, err
 				}
 				if !b.Variable.Define(var1, step) {
-					return SignalCondition(e, instance.NewImmutableBinding(e), Nil)
+					return SignalCondition(e, instance.NewImmutableBinding(e, var1), Nil)
 				}
 			default:
-				return SignalCondition(e, instance.NewArityError(e), Nil)
+				return SignalCondition(e, instance.NewProgramError(e), Nil)
 			}
 		}
 		test, err = Eval(b, endTest)
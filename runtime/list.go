@@ -31,7 +31,7 @@ func CreateList(e env.Environment, i ilos.Instance, initialElement ...ilos.Insta
 		return nil, instance.NewDomainError(e, i, class.Integer)
 	}
 	if len(initialElement) > 1 {
-		return SignalCondition(e, instance.NewArityError(e), Nil)
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("CREATE-LIST"), 1, 2, 1+len(initialElement)), Nil)
 	}
 	elm := Nil
 	if len(initialElement) == 1 {
@@ -129,7 +129,10 @@ func Member(e env.Environment, obj, list ilos.Instance) (ilos.Instance, ilos.Ins
 	if ok, _ := Listp(e, list); ok == Nil {
 		return nil, instance.NewDomainError(e, list, class.List)
 	}
-	if !ilos.InstanceOf(class.Cons, list) || list.(*instance.Cons).Car == obj {
+	if !ilos.InstanceOf(class.Cons, list) {
+		return list, nil
+	}
+	if ok, _ := Eql(e, list.(*instance.Cons).Car, obj); ok == T {
 		return list, nil
 	}
 	if !ilos.InstanceOf(class.Cons, list.(*instance.Cons).Cdr) {
@@ -282,7 +285,7 @@ func Assoc(e env.Environment, obj, associationList ilos.Instance) (ilos.Instance
 	if ok, _ := Consp(e, car); ok == Nil {
 		return nil, instance.NewDomainError(e, car, class.Cons)
 	}
-	if car.(*instance.Cons).Car == obj { // eql
+	if ok, _ := Eql(e, car.(*instance.Cons).Car, obj); ok == T {
 		return car, nil
 	}
 	return Assoc(e, obj, cdr)
@@ -147,6 +147,11 @@ func TestMember(t *testing.T) {
 			want:    `nil`,
 			wantErr: false,
 		},
+		{
+			exp:     `(member "a" '("b" "a"))`,
+			want:    `nil`,
+			wantErr: false,
+		},
 		{
 			exp:     `(member 'c '(a b c a b c))`,
 			want:    `'(c a b c)`,
@@ -252,5 +257,10 @@ func TestAssoc(t *testing.T) {
 			want:    `nil`,
 			wantErr: false,
 		},
+		{
+			exp:     `(assoc "a" '(("b" . 1) ("a" . 2)))`,
+			want:    `nil`,
+			wantErr: false,
+		},
 	})
 }
@@ -15,16 +15,20 @@ import (
 // name is established when the macro-expansion function is invoked. macro-name
 // must be an identifier whose scope is the current toplevel scope in which the
 // defmacro form appears. lambda-list is as defined in page 23. The definition
-// point of macro-name is the closing parenthesis of the lambda-list.
+// point of macro-name is the closing parenthesis of the lambda-list. As in
+// defun, a string at the beginning of form* may be the documentation.
 func Defmacro(e env.Environment, macroName, lambdaList ilos.Instance, forms ...ilos.Instance) (ilos.Instance, ilos.Instance) {
 	if err := ensure(e, class.Symbol, macroName); err != nil {
 		return nil, err
 	}
+	doc, forms := splitDocumentation(forms)
 	ret, err := newNamedFunction(e, macroName, lambdaList, forms...)
 	if err != nil {
 		return nil, err
 	}
 	e.Macro[:1].Define(macroName, ret)
+	document(e, macroName, "FUNCTION", doc)
+	e.LambdaList.Set(macroName, instance.NewSymbol("MACRO"), lambdaList)
 	return macroName, nil
 }
 
@@ -5,8 +5,6 @@
 package runtime
 
 import (
-	"fmt"
-
 	"github.com/islisp-dev/iris/runtime/env"
 	"github.com/islisp-dev/iris/runtime/ilos"
 	"github.com/islisp-dev/iris/runtime/ilos/class"
@@ -20,14 +18,19 @@ func checkLambdaList(e env.Environment, lambdaList ilos.Instance) ilos.Instance
 	for i, cadr := range lambdaList.(instance.List).Slice() {
 		if cadr == instance.NewSymbol(":REST") || cadr == instance.NewSymbol("&REST") {
 			if lambdaList.(instance.List).Length() != i+2 {
-		_, err := SignalCondition(e, instance.NewArityError(e), Nil)
-		return err
+				_, err := SignalCondition(e, instance.NewProgramError(e), Nil)
+				return err
 			}
 		}
 	}
 	return nil
 }
 
+// lispFunction is the Go function of a function defined in Lisp, which pushes
+// the frame of its call itself, so that the calls by funcall, apply or mapcar
+// are seen by the debugger and the profiler as well as the calls evaluated.
+type lispFunction func(env.Environment, ...ilos.Instance) (ilos.Instance, ilos.Instance)
+
 func newNamedFunction(e env.Environment, functionName, lambdaList ilos.Instance, forms ...ilos.Instance) (ilos.Instance, ilos.Instance) {
 	lexical := e
 	if err := ensure(e, class.Symbol, functionName); err != nil {
@@ -44,10 +47,18 @@ func newNamedFunction(e env.Environment, functionName, lambdaList ilos.Instance,
 		}
 		parameters = append(parameters, cadr)
 	}
-	return instance.NewFunction(functionName.(instance.Symbol), func(e env.Environment, arguments ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return instance.NewFunction(functionName.(instance.Symbol), lispFunction(func(e env.Environment, arguments ...ilos.Instance) (ilos.Instance, ilos.Instance) {
 		e.MergeLexical(lexical)
+		e.Frame = e.PushFrame(functionName, arguments)
+		if p := currentProfile; p != nil && p.isRunning() {
+			defer p.enter(e.Frame)()
+		}
 		if (variadic && len(parameters)-2 > len(arguments)) || (!variadic && len(parameters) != len(arguments)) {
-		return SignalCondition(e, instance.NewArityError(e), Nil)
+			min, max := len(parameters), len(parameters)
+			if variadic {
+				min, max = len(parameters)-2, -1
+			}
+			return SignalCondition(e, instance.NewArityError(e, functionName, min, max, len(arguments)), Nil)
 		}
 		for idx := range parameters {
 			key := parameters[idx]
@@ -58,16 +69,16 @@ func newNamedFunction(e env.Environment, functionName, lambdaList ilos.Instance,
 					return nil, err
 				}
 				if !e.Variable.Define(key, value) {
-					return SignalCondition(e, instance.NewImmutableBinding(e), Nil)
+					return SignalCondition(e, instance.NewImmutableBinding(e, key), Nil)
 				}
 				break
 			}
 			value := arguments[idx]
 			if !e.Variable.Define(key, value) {
-				fmt.Print(key, value)
-				return SignalCondition(e, instance.NewImmutableBinding(e), Nil)
+				return SignalCondition(e, instance.NewImmutableBinding(e, key), Nil)
 			}
 		}
+		e.Frame.Environment = &e
 		return Progn(e, forms...)
-	}), nil
+	})), nil
 }
@@ -49,7 +49,7 @@ func Block(e env.Environment, tag ilos.Instance, body ...ilos.Instance) (ilos.In
 		return SignalCondition(e, instance.NewDomainError(e, tag, class.Object), Nil)
 	}
 	if !e.BlockTag.Define(tag, uid) {
-		return SignalCondition(e, instance.NewImmutableBinding(e), Nil)
+		return SignalCondition(e, instance.NewImmutableBinding(e, tag), Nil)
 	}
 	var fail ilos.Instance
 	sucess := Nil
@@ -101,7 +101,7 @@ func Catch(e env.Environment, tag ilos.Instance, body ...ilos.Instance) (ilos.In
 		return SignalCondition(e, instance.NewDomainError(e, tag, class.Object), Nil)
 	}
 	if !e.CatchTag.Define(tag, uid) {
-		return SignalCondition(e, instance.NewImmutableBinding(e), Nil)
+		return SignalCondition(e, instance.NewImmutableBinding(e, tag), Nil)
 	}
 	var fail ilos.Instance
 	sucess := Nil
@@ -148,7 +148,7 @@ func Tagbody(e env.Environment, body ...ilos.Instance) (ilos.Instance, ilos.Inst
 	for _, cadr := range body {
 		if !ilos.InstanceOf(class.Cons, cadr) {
 			if !e.TagbodyTag.Define(cadr, uid) { // ref cddr
-				return SignalCondition(e, instance.NewImmutableBinding(e), Nil)
+				return SignalCondition(e, instance.NewImmutableBinding(e, cadr), Nil)
 			}
 		}
 	}
@@ -217,10 +217,10 @@ func Go(e env.Environment, tag ilos.Instance) (ilos.Instance, ilos.Instance) {
 func UnwindProtect(e env.Environment, form ilos.Instance, cleanupForms ...ilos.Instance) (ilos.Instance, ilos.Instance) {
 	ret1, err1 := Eval(e, form)
 	ret2, err2 := Progn(e, cleanupForms...)
-	if ilos.InstanceOf(class.Escape, err2) {
-		return SignalCondition(e, instance.NewControlError(e), Nil)
-	}
 	if err2 != nil {
+		if ilos.InstanceOf(class.Escape, err2) {
+			return SignalCondition(e, instance.NewControlError(e), Nil)
+		}
 		return ret2, err2
 	}
 	return ret1, err1
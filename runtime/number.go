@@ -228,7 +228,7 @@ func Quotient(e env.Environment, dividend, divisor1 ilos.Instance, divisor ...il
 			for i := len(divisor) - 1; i >= 0; i-- {
 				arguments = instance.NewCons(divisor[i], arguments)
 			}
-			return SignalCondition(e, instance.NewArithmeticError(e, instance.NewSymbol("QUOTIENT"), arguments), Nil)
+			return SignalCondition(e, instance.NewDivisionByZero(e, instance.NewSymbol("QUOTIENT"), arguments), Nil)
 		}
 		if !flt && !b && int(quotient)%int(f) != 0 {
 			flt = true
@@ -0,0 +1,153 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"compress/gzip"
+	"io"
+)
+
+// protoBuffer encodes a message of protocol buffers, as much of the wire
+// format as the profiles of pprof need.
+type protoBuffer struct {
+	data []byte
+}
+
+func (b *protoBuffer) varint(x uint64) {
+	for x >= 0x80 {
+		b.data = append(b.data, byte(x)|0x80)
+		x >>= 7
+	}
+	b.data = append(b.data, byte(x))
+}
+
+func (b *protoBuffer) tag(field, wireType int) {
+	b.varint(uint64(field)<<3 | uint64(wireType))
+}
+
+func (b *protoBuffer) int64(field int, x int64) {
+	if x == 0 {
+		return
+	}
+	b.tag(field, 0)
+	b.varint(uint64(x))
+}
+
+func (b *protoBuffer) bytes(field int, data []byte) {
+	b.tag(field, 2)
+	b.varint(uint64(len(data)))
+	b.data = append(b.data, data...)
+}
+
+func (b *protoBuffer) string(field int, s string) {
+	b.bytes(field, []byte(s))
+}
+
+func (b *protoBuffer) packed(field int, xs []int64) {
+	if len(xs) == 0 {
+		return
+	}
+	p := &protoBuffer{}
+	for _, x := range xs {
+		p.varint(uint64(x))
+	}
+	b.bytes(field, p.data)
+}
+
+func (b *protoBuffer) message(field int, encode func(*protoBuffer)) {
+	p := &protoBuffer{}
+	encode(p)
+	b.bytes(field, p.data)
+}
+
+// pprofProfile is a profile in the format of pprof, described in
+// https://github.com/google/pprof/blob/main/proto/profile.proto. Each sample
+// is a stack of function names, the innermost first, and its values of the
+// sample types.
+type pprofProfile struct {
+	sampleTypes [][2]string
+	stacks      [][]string
+	values      [][]int64
+	periodType  [2]string
+	period      int64
+	timeNanos   int64
+	duration    int64
+	// defaultSampleType is the type of the sample values which pprof shows
+	// unless another one is chosen.
+	defaultSampleType string
+}
+
+// write writes p to w compressed by gzip, as pprof reads it.
+func (p *pprofProfile) write(w io.Writer) error {
+	indices := map[string]int64{"": 0}
+	table := []string{""}
+	index := func(s string) int64 {
+		if i, ok := indices[s]; ok {
+			return i
+		}
+		indices[s] = int64(len(table))
+		table = append(table, s)
+		return indices[s]
+	}
+	valueType := func(t [2]string) func(*protoBuffer) {
+		typ, unit := index(t[0]), index(t[1])
+		return func(b *protoBuffer) {
+			b.int64(1, typ)
+			b.int64(2, unit)
+		}
+	}
+	b := &protoBuffer{}
+	for _, t := range p.sampleTypes {
+		b.message(1, valueType(t))
+	}
+	// Each function has the only location, which has the same ID.
+	functions := map[string]int64{}
+	names := []string{}
+	for i, stack := range p.stacks {
+		ids := make([]int64, len(stack))
+		for j, name := range stack {
+			if _, ok := functions[name]; !ok {
+				functions[name] = int64(len(names) + 1)
+				names = append(names, name)
+			}
+			ids[j] = functions[name]
+		}
+		values := p.values[i]
+		b.message(2, func(b *protoBuffer) {
+			b.packed(1, ids)
+			b.packed(2, values)
+		})
+	}
+	for i := range names {
+		id := int64(i + 1)
+		b.message(4, func(b *protoBuffer) {
+			b.int64(1, id)
+			b.message(4, func(b *protoBuffer) { b.int64(1, id) })
+		})
+	}
+	for i, name := range names {
+		id, s := int64(i+1), index(name)
+		b.message(5, func(b *protoBuffer) {
+			b.int64(1, id)
+			b.int64(2, s)
+			b.int64(3, s)
+		})
+	}
+	periodType := valueType(p.periodType)
+	defaultSampleType := index(p.defaultSampleType)
+	for _, s := range table {
+		b.string(6, s)
+	}
+	b.int64(9, p.timeNanos)
+	b.int64(10, p.duration)
+	b.message(11, periodType)
+	b.int64(12, p.period)
+	b.int64(14, defaultSampleType)
+	z := gzip.NewWriter(w)
+	if _, err := z.Write(b.data); err != nil {
+		return err
+	}
+	return z.Close()
+}
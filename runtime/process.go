@@ -0,0 +1,324 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"io"
+	"os"
+	"os/exec"
+	"syscall"
+	"time"
+
+	"github.com/islisp-dev/iris/runtime/env"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+var streamKeyword = instance.NewSymbol(":STREAM")
+
+// seconds converts the non-negative number x of seconds to a duration.
+func seconds(e env.Environment, x ilos.Instance) (time.Duration, ilos.Instance) {
+	var s float64
+	switch x := x.(type) {
+	case instance.Integer:
+		s = float64(x)
+	case instance.Float:
+		s = float64(x)
+	default:
+		_, err := SignalCondition(e, instance.NewDomainError(e, x, class.Number), Nil)
+		return 0, err
+	}
+	if s < 0 {
+		_, err := SignalCondition(e, instance.NewDomainError(e, x, class.Number), Nil)
+		return 0, err
+	}
+	return time.Duration(s * float64(time.Second)), nil
+}
+
+// stringsOf returns the elements of list, which must be strings.
+func stringsOf(e env.Environment, list ilos.Instance) ([]string, ilos.Instance) {
+	if err := ensure(e, class.List, list); err != nil {
+		return nil, err
+	}
+	if !isProperList(list) {
+		_, err := SignalCondition(e, instance.NewDomainError(e, list, class.List), Nil)
+		return nil, err
+	}
+	ss := []string{}
+	for _, s := range list.(instance.List).Slice() {
+		if err := ensure(e, class.String, s); err != nil {
+			return nil, err
+		}
+		ss = append(ss, string(s.(instance.String)))
+	}
+	return ss, nil
+}
+
+// RunProgram runs the program named program with the list of strings
+// arguments and returns the process. The options are pairs of keywords and
+// values:
+//
+// :input, :output and :error connect the standard streams of the process. nil
+// (the default) connects them to the null device, :stream to a new stream
+// returned by process-input, process-output or process-error, and a stream to
+// that stream.
+//
+// :directory is the working directory and :environment is a list of strings
+// of the form "NAME=VALUE" which replaces the environment of the process.
+//
+// :wait tells whether to wait for the process to exit. It defaults to t unless
+// a new stream is requested. The process is always waited for if it is
+// connected to an existing stream, so a new stream, which could not be used
+// before the process exits, cannot be requested with an existing stream
+// (error-id. program-error).
+//
+// :timeout is the number of seconds after which the process is killed.
+func RunProgram(e env.Environment, program, arguments ilos.Instance, options ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.String, program); err != nil {
+		return nil, err
+	}
+	args, err := stringsOf(e, arguments)
+	if err != nil {
+		return nil, err
+	}
+	if len(options)%2 != 0 {
+		return SignalCondition(e, instance.NewProgramError(e), Nil)
+	}
+	cmd := exec.Command(string(program.(instance.String)), args...)
+	var input, output, errorOutput ilos.Instance = Nil, Nil, Nil
+	// child holds the ends of the pipes passed to the process, which are
+	// closed after starting it, and parent the other ends.
+	child, parent := []*os.File{}, []*os.File{}
+	defer func() {
+		for _, f := range child {
+			f.Close()
+		}
+	}()
+	wait, waitGiven, attached, piped := true, false, false, false
+	timeout := time.Duration(-1)
+	for i := 0; i < len(options); i += 2 {
+		key, value := options[i], options[i+1]
+		switch key {
+		case instance.NewSymbol(":INPUT"):
+			switch {
+			case value == Nil:
+			case value == streamKeyword:
+				r, w, fail := os.Pipe()
+				if fail != nil {
+					return SignalCondition(e, instance.NewStreamError(e, Nil), Nil)
+				}
+				child, parent = append(child, r), append(parent, w)
+				cmd.Stdin, input = r, instance.NewFileStream(w, false, true, false)
+				wait, piped = waitGiven && wait, true
+			default:
+				if ok, _ := InputStreamP(e, value); ok == Nil {
+					return SignalCondition(e, instance.NewDomainError(e, value, class.Stream), Nil)
+				}
+				s, err := attachedStream(e, value)
+				if err != nil {
+					return nil, err
+				}
+				cmd.Stdin, attached = s, true
+			}
+		case instance.NewSymbol(":OUTPUT"), instance.NewSymbol(":ERROR"):
+			var w io.Writer
+			switch {
+			case value == Nil:
+				w = nil
+			case value == streamKeyword:
+				r, pw, fail := os.Pipe()
+				if fail != nil {
+					return SignalCondition(e, instance.NewStreamError(e, Nil), Nil)
+				}
+				child, parent = append(child, pw), append(parent, r)
+				w = pw
+				if key == instance.NewSymbol(":OUTPUT") {
+					output = instance.NewFileStream(r, true, false, false)
+				} else {
+					errorOutput = instance.NewFileStream(r, true, false, false)
+				}
+				wait, piped = waitGiven && wait, true
+			default:
+				if ok, _ := OutputStreamP(e, value); ok == Nil {
+					return SignalCondition(e, instance.NewDomainError(e, value, class.Stream), Nil)
+				}
+				s, err := attachedStream(e, value)
+				if err != nil {
+					return nil, err
+				}
+				w, attached = s, true
+			}
+			if key == instance.NewSymbol(":OUTPUT") {
+				cmd.Stdout = w
+			} else {
+				cmd.Stderr = w
+			}
+		case instance.NewSymbol(":DIRECTORY"):
+			if err := ensure(e, class.String, value); err != nil {
+				return nil, err
+			}
+			cmd.Dir = string(value.(instance.String))
+		case instance.NewSymbol(":ENVIRONMENT"):
+			environment, err := stringsOf(e, value)
+			if err != nil {
+				return nil, err
+			}
+			cmd.Env = environment
+		case instance.NewSymbol(":WAIT"):
+			wait, waitGiven = value != Nil, true
+		case instance.NewSymbol(":TIMEOUT"):
+			if timeout, err = seconds(e, value); err != nil {
+				return nil, err
+			}
+		default:
+			return SignalCondition(e, instance.NewDomainError(e, key, class.Symbol), Nil)
+		}
+	}
+	if piped && attached {
+		for _, f := range parent {
+			f.Close()
+		}
+		return SignalCondition(e, instance.NewProgramError(e), Nil)
+	}
+	if fail := cmd.Start(); fail != nil {
+		for _, f := range parent {
+			f.Close()
+		}
+		arguments, _ := List(e, program, instance.NewString([]rune(fail.Error())))
+		return SignalCondition(e, instance.NewSimpleError(e, instance.NewString([]rune("Cannot run ~A: ~A")), arguments), Nil)
+	}
+	p := instance.NewProcess(cmd, input, output, errorOutput)
+	if timeout >= 0 {
+		time.AfterFunc(timeout, func() { cmd.Process.Kill() })
+	}
+	// An existing stream is written by the process only while it is waited
+	// for, so that the stream is never used concurrently.
+	if wait || attached {
+		p.(*instance.Process).Wait(-1)
+	}
+	return p, nil
+}
+
+// attachedStream returns the stream s connected to a process. A
+// <fundamental-stream> cannot be connected, since its methods would be called
+// outside of the interpreter.
+func attachedStream(e env.Environment, s ilos.Instance) (instance.Stream, ilos.Instance) {
+	stream, ok := s.(instance.Stream)
+	if !ok {
+		_, err := SignalCondition(e, instance.NewDomainError(e, s, class.Stream), Nil)
+		return instance.Stream{}, err
+	}
+	if err := ensureStream(e, stream, false); err != nil {
+		return instance.Stream{}, err
+	}
+	return stream, nil
+}
+
+// ProcessWait waits for process to exit and returns its exit code, which is -1
+// if the process was terminated by a signal. If timeout seconds pass before
+// that, nil is returned instead.
+func ProcessWait(e env.Environment, process ilos.Instance, timeout ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.Process, process); err != nil {
+		return nil, err
+	}
+	if len(timeout) > 1 {
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("PROCESS-WAIT"), 1, 2, 1+len(timeout)), Nil)
+	}
+	d := time.Duration(-1)
+	if len(timeout) == 1 {
+		var err ilos.Instance
+		if d, err = seconds(e, timeout[0]); err != nil {
+			return nil, err
+		}
+	}
+	if !process.(*instance.Process).Wait(d) {
+		return Nil, nil
+	}
+	return ProcessExitCode(e, process)
+}
+
+// ProcessExitCode returns the exit code of process, or nil if it has not
+// exited yet.
+func ProcessExitCode(e env.Environment, process ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.Process, process); err != nil {
+		return nil, err
+	}
+	if code, ok := process.(*instance.Process).ExitCode(); ok {
+		return instance.NewInteger(code), nil
+	}
+	return Nil, nil
+}
+
+// ProcessAliveP returns t if process has not exited yet; otherwise, returns
+// nil.
+func ProcessAliveP(e env.Environment, process ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.Process, process); err != nil {
+		return nil, err
+	}
+	if _, ok := process.(*instance.Process).ExitCode(); ok {
+		return Nil, nil
+	}
+	return T, nil
+}
+
+// ProcessKill sends the signal numbered signal, which defaults to 15
+// (SIGTERM), to process. It returns t if the signal was sent, and nil if the
+// process has exited or the signal is not supported by the system.
+func ProcessKill(e env.Environment, process ilos.Instance, signal ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.Process, process); err != nil {
+		return nil, err
+	}
+	if len(signal) > 1 {
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("PROCESS-KILL"), 1, 2, 1+len(signal)), Nil)
+	}
+	sig := syscall.SIGTERM
+	if len(signal) == 1 {
+		if err := ensure(e, class.Integer, signal[0]); err != nil {
+			return nil, err
+		}
+		sig = syscall.Signal(signal[0].(instance.Integer))
+	}
+	if err := process.(*instance.Process).Cmd.Process.Signal(sig); err != nil {
+		return Nil, nil
+	}
+	return T, nil
+}
+
+// ProcessPid returns the process ID of process.
+func ProcessPid(e env.Environment, process ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.Process, process); err != nil {
+		return nil, err
+	}
+	return instance.NewInteger(process.(*instance.Process).Cmd.Process.Pid), nil
+}
+
+// ProcessInput returns the stream to the standard input of process, or nil if
+// it was not requested by :input :stream. Closing the stream closes the
+// standard input of the process.
+func ProcessInput(e env.Environment, process ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.Process, process); err != nil {
+		return nil, err
+	}
+	return process.(*instance.Process).Input, nil
+}
+
+// ProcessOutput returns the stream from the standard output of process, or nil
+// if it was not requested by :output :stream.
+func ProcessOutput(e env.Environment, process ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.Process, process); err != nil {
+		return nil, err
+	}
+	return process.(*instance.Process).Output, nil
+}
+
+// ProcessError returns the stream from the standard error of process, or nil
+// if it was not requested by :error :stream.
+func ProcessError(e env.Environment, process ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.Process, process); err != nil {
+		return nil, err
+	}
+	return process.(*instance.Process).Error, nil
+}
@@ -0,0 +1,88 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+//go:build aix || darwin || dragonfly || freebsd || linux || netbsd || openbsd || solaris
+// +build aix darwin dragonfly freebsd linux netbsd openbsd solaris
+
+package runtime
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunProgram(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	execTests(t, RunProgram, []test{
+		{
+			exp:     `(process-exit-code (run-program "sh" (list "-c" "exit 3")))`,
+			want:    `3`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((o (create-string-output-stream))) (run-program "printf" (list "%s %s" "hello" "world") :output o) (get-output-stream-string o))`,
+			want:    `"hello world"`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(let ((p (run-program "pwd" (list "-P") :directory %q :output :stream))) (list (read-line (process-output p)) (process-wait p)))`, dir),
+			want:    fmt.Sprintf(`(list %q 0)`, dir),
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((p (run-program "sh" (list "-c" "echo $GREETING") :environment (list "GREETING=hi") :output :stream))) (read-line (process-output p)))`,
+			want:    `"hi"`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((p (run-program "cat" '() :input :stream :output :stream))) (format (process-input p) "ping~%") (close (process-input p)) (list (read-line (process-output p)) (read-line (process-output p) nil 'eof) (process-wait p)))`,
+			want:    `'("ping" eof 0)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((p (run-program "sh" (list "-c" "echo oops >&2") :error :stream))) (read-line (process-error p)))`,
+			want:    `"oops"`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((p (run-program "sleep" (list "10") :wait nil))) (list (process-alive-p p) (process-wait p 0.01) (process-kill p) (process-wait p) (process-alive-p p)))`,
+			want:    `'(t nil t -1 nil)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(process-exit-code (run-program "sleep" (list "10") :timeout 0.01))`,
+			want:    `-1`,
+			wantErr: false,
+		},
+		{
+			exp:     `(run-program "iris-no-such-program" '())`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(let ((o (create-string-output-stream))) (run-program "cat" '() :input :stream :output o))`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(run-program "cat" '() :input (create-string-input-stream "ping") :output :stream)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(run-program "echo" '() :colour t)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(run-program "echo" (list 1))`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
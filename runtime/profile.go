@@ -0,0 +1,442 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"runtime/metrics"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/islisp-dev/iris/runtime/env"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// Profile records where the time goes and the memory is allocated in the
+// calls of the Lisp functions, which are told by the frames of the calls.
+//
+// In the deterministic mode, every call is counted and timed, and its time
+// and allocations, excluding those of the calls it makes, are attributed to
+// the stack of the calls in progress. In the sampling mode, the stack is
+// sampled at intervals instead, which costs much less but is approximate.
+type Profile struct {
+	clock    env.Clock
+	sampling bool
+	interval time.Duration
+	start    time.Time
+	duration time.Duration
+	running  bool
+	// metrics are read for the allocations in the deterministic mode.
+	metrics []metrics.Sample
+	// calls are the deterministic calls in progress, the innermost last.
+	calls []*profileCall
+	// current holds the innermost frame in the sampling mode.
+	current atomic.Value
+	stop    chan struct{}
+	done    chan struct{}
+	// mutex guards running, duration and samples, since Stop and the sampler
+	// may run in other goroutines than the evaluation.
+	mutex   sync.Mutex
+	samples map[string]*profileSample
+}
+
+// profileSample is the sum of the values recorded for a stack of functions,
+// the innermost first.
+type profileSample struct {
+	stack []string
+	// count is the number of the calls or the samples.
+	count   int64
+	time    time.Duration
+	bytes   int64
+	objects int64
+}
+
+type profileCall struct {
+	start       time.Time
+	allocs      [2]uint64
+	children    time.Duration
+	childAllocs [2]uint64
+}
+
+// currentProfile is the profile being taken or the last one taken.
+var currentProfile *Profile
+
+func newAllocMetrics() []metrics.Sample {
+	return []metrics.Sample{{Name: "/gc/heap/allocs:bytes"}, {Name: "/gc/heap/allocs:objects"}}
+}
+
+// readAllocs returns the bytes and the objects allocated so far.
+func readAllocs(samples []metrics.Sample) [2]uint64 {
+	metrics.Read(samples)
+	return [2]uint64{samples[0].Value.Uint64(), samples[1].Value.Uint64()}
+}
+
+// StartProfile stops the current profile and starts a new one timed by clock,
+// which samples the calls every interval if sampling is true, and records
+// every call otherwise.
+func StartProfile(clock env.Clock, sampling bool, interval time.Duration) *Profile {
+	if currentProfile != nil {
+		currentProfile.Stop()
+	}
+	p := &Profile{
+		clock:    clock,
+		sampling: sampling,
+		interval: interval,
+		start:    clock.Now(),
+		running:  true,
+		metrics:  newAllocMetrics(),
+		samples:  map[string]*profileSample{},
+	}
+	p.current.Store((*env.Frame)(nil))
+	if sampling {
+		p.stop, p.done = make(chan struct{}), make(chan struct{})
+		go p.sample()
+	}
+	currentProfile = p
+	return p
+}
+
+// Stop stops taking p and waits for the sampler to exit. The calls in
+// progress are not recorded.
+func (p *Profile) Stop() {
+	p.mutex.Lock()
+	if !p.running {
+		p.mutex.Unlock()
+		return
+	}
+	p.running = false
+	p.duration = p.clock.Now().Sub(p.start)
+	p.mutex.Unlock()
+	if p.sampling {
+		close(p.stop)
+		<-p.done
+	}
+}
+
+// isRunning reports whether p is still taken.
+func (p *Profile) isRunning() bool {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	return p.running
+}
+
+// elapsed returns the duration of p so far.
+func (p *Profile) elapsed() time.Duration {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	if p.running {
+		return p.clock.Now().Sub(p.start)
+	}
+	return p.duration
+}
+
+// sample records the stack of the innermost frame every interval until p
+// stops. The time and the allocations since the last sample are attributed to
+// the stack, since the ticks may be dropped while the evaluation is busy.
+func (p *Profile) sample() {
+	defer close(p.done)
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	samples := newAllocMetrics()
+	last, lastTick := readAllocs(samples), time.Now()
+	for {
+		select {
+		case <-p.stop:
+			return
+		case tick := <-ticker.C:
+			allocs := readAllocs(samples)
+			if frame := p.current.Load().(*env.Frame); frame != nil {
+				p.add(stackOf(frame), tick.Sub(lastTick), allocs[0]-last[0], allocs[1]-last[1])
+			}
+			last, lastTick = allocs, tick
+		}
+	}
+}
+
+// stackOf returns the names of the functions of frame and its callers, the
+// innermost first.
+func stackOf(frame *env.Frame) []string {
+	stack := []string{}
+	for f := frame; f != nil; f = f.Parent {
+		stack = append(stack, f.Name.String())
+	}
+	return stack
+}
+
+func (p *Profile) add(stack []string, time time.Duration, bytes, objects uint64) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	key := strings.Join(stack, "\n")
+	s, ok := p.samples[key]
+	if !ok {
+		s = &profileSample{stack: stack}
+		p.samples[key] = s
+	}
+	s.count++
+	s.time += time
+	s.bytes += int64(bytes)
+	s.objects += int64(objects)
+}
+
+// enter records the beginning of the call of frame, and returns the function
+// which records its end.
+func (p *Profile) enter(frame *env.Frame) func() {
+	if p.sampling {
+		previous := p.current.Load()
+		p.current.Store(frame)
+		return func() { p.current.Store(previous) }
+	}
+	call := &profileCall{start: p.clock.Now(), allocs: readAllocs(p.metrics)}
+	p.calls = append(p.calls, call)
+	return func() {
+		if !p.isRunning() {
+			return
+		}
+		elapsed := p.clock.Now().Sub(call.start)
+		allocs := readAllocs(p.metrics)
+		p.calls = p.calls[:len(p.calls)-1]
+		bytes, objects := allocs[0]-call.allocs[0], allocs[1]-call.allocs[1]
+		if n := len(p.calls); n > 0 {
+			parent := p.calls[n-1]
+			parent.children += elapsed
+			parent.childAllocs[0] += bytes
+			parent.childAllocs[1] += objects
+		}
+		p.add(stackOf(frame), elapsed-call.children, bytes-call.childAllocs[0], objects-call.childAllocs[1])
+	}
+}
+
+// applyFrame applies function named name to arguments in e. The functions
+// defined in Lisp push their frames themselves. For the others, the frame of
+// the call is pushed here and the call is recorded in the profile being taken.
+func applyFrame(e env.Environment, name, function ilos.Instance, arguments []ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if !pushesFrame(function) {
+		e.Frame = e.PushFrame(name, arguments)
+		if p := currentProfile; p != nil && p.isRunning() {
+			defer p.enter(e.Frame)()
+		}
+	}
+	return function.(instance.Applicable).Apply(e, arguments...)
+}
+
+// pushesFrame returns true if function pushes the frames of its calls, that
+// is, if it is defined in Lisp or it is a generic function, whose methods are.
+func pushesFrame(function ilos.Instance) bool {
+	switch f := untraced(function).(type) {
+	case instance.Function:
+		_, ok := f.Function().(lispFunction)
+		return ok
+	case *instance.GenericFunction:
+		return true
+	}
+	return false
+}
+
+// sortedSamples returns the samples of p in the order of their stacks.
+func (p *Profile) sortedSamples() []profileSample {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+	keys := []string{}
+	for key := range p.samples {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	samples := []profileSample{}
+	for _, key := range keys {
+		samples = append(samples, *p.samples[key])
+	}
+	return samples
+}
+
+// Report writes the table of the functions in p to w, sorted by the time
+// spent in them, excluding the calls they make. The columns are the number of
+// the calls or the samples, the time in milliseconds spent in the function
+// itself and in total, and the bytes allocated in the function itself.
+func (p *Profile) Report(w io.Writer) error {
+	type row struct {
+		name        string
+		count       int64
+		self, total time.Duration
+		bytes       int64
+	}
+	rows := map[string]*row{}
+	get := func(name string) *row {
+		if rows[name] == nil {
+			rows[name] = &row{name: name}
+		}
+		return rows[name]
+	}
+	for _, s := range p.sortedSamples() {
+		r := get(s.stack[0])
+		r.count += s.count
+		r.self += s.time
+		r.bytes += s.bytes
+		seen := map[string]bool{}
+		for _, name := range s.stack {
+			if !seen[name] {
+				seen[name] = true
+				get(name).total += s.time
+			}
+		}
+	}
+	sorted := []*row{}
+	for _, r := range rows {
+		sorted = append(sorted, r)
+	}
+	sort.Slice(sorted, func(i, j int) bool {
+		if sorted[i].self != sorted[j].self {
+			return sorted[i].self > sorted[j].self
+		}
+		return sorted[i].name < sorted[j].name
+	})
+	count := "calls"
+	if p.sampling {
+		count = "samples"
+	}
+	if _, err := fmt.Fprintf(w, "%10s %12s %12s %12s  %s\n", count, "self ms", "total ms", "self bytes", "function"); err != nil {
+		return err
+	}
+	for _, r := range sorted {
+		if _, err := fmt.Fprintf(w, "%10d %12.3f %12.3f %12d  %s\n", r.count, float64(r.self)/float64(time.Millisecond), float64(r.total)/float64(time.Millisecond), r.bytes, r.name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WritePprof writes p to w in the format of pprof, so that go tool pprof can
+// show the Lisp functions.
+func (p *Profile) WritePprof(w io.Writer) error {
+	count := [2]string{"calls", "count"}
+	profile := &pprofProfile{periodType: count, period: 1}
+	if p.sampling {
+		count = [2]string{"samples", "count"}
+		profile.periodType, profile.period = [2]string{"time", "nanoseconds"}, int64(p.interval)
+	}
+	profile.sampleTypes = [][2]string{count, {"time", "nanoseconds"}, {"alloc_space", "bytes"}, {"alloc_objects", "count"}}
+	profile.defaultSampleType = "time"
+	profile.timeNanos = p.start.UnixNano()
+	profile.duration = int64(p.elapsed())
+	for _, s := range p.sortedSamples() {
+		profile.stacks = append(profile.stacks, s.stack)
+		profile.values = append(profile.values, []int64{s.count, int64(s.time), s.bytes, s.objects})
+	}
+	return profile.write(w)
+}
+
+// StartProfiling starts taking a profile of the calls of the Lisp functions,
+// and discards the last one. The options are:
+//
+// :mode is :deterministic to record every call, which is the default, or
+// :sampling to sample the calls in progress at intervals.
+//
+// :interval is the interval of the samples in internal time units, which is
+// 1000 by default.
+func StartProfiling(e env.Environment, options ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if len(options)%2 != 0 {
+		return SignalCondition(e, instance.NewProgramError(e), Nil)
+	}
+	sampling, interval := false, time.Millisecond
+	for i := 0; i < len(options); i += 2 {
+		key, value := options[i], options[i+1]
+		switch key {
+		case instance.NewSymbol(":MODE"):
+			switch value {
+			case instance.NewSymbol(":DETERMINISTIC"):
+				sampling = false
+			case instance.NewSymbol(":SAMPLING"):
+				sampling = true
+			default:
+				return SignalCondition(e, instance.NewDomainError(e, value, class.Symbol), Nil)
+			}
+		case instance.NewSymbol(":INTERVAL"):
+			if err := ensure(e, class.Integer, value); err != nil {
+				return nil, err
+			}
+			if int(value.(instance.Integer)) <= 0 {
+				return SignalCondition(e, instance.NewDomainError(e, value, class.Integer), Nil)
+			}
+			interval = time.Duration(value.(instance.Integer)) * time.Microsecond
+		default:
+			return SignalCondition(e, instance.NewDomainError(e, key, class.Symbol), Nil)
+		}
+	}
+	p := StartProfile(e.Clock, sampling, interval)
+	if e.Frame != nil {
+		p.current.Store(e.Frame.Parent)
+	}
+	return Nil, nil
+}
+
+// StopProfiling stops taking the profile and returns nil.
+func StopProfiling(e env.Environment) (ilos.Instance, ilos.Instance) {
+	if currentProfile != nil {
+		currentProfile.Stop()
+	}
+	return Nil, nil
+}
+
+func ensureProfile(e env.Environment) ilos.Instance {
+	if currentProfile != nil {
+		return nil
+	}
+	_, err := SignalCondition(e, instance.NewSimpleError(e, instance.NewString([]rune("No profile has been taken")), Nil), Nil)
+	return err
+}
+
+// ProfileReport writes the table of the functions in the profile to stream,
+// or the standard output if stream is not given, and returns nil.
+func ProfileReport(e env.Environment, stream ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if len(stream) > 1 {
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("PROFILE-REPORT"), 0, 1, len(stream)), Nil)
+	}
+	output := e.StandardOutput
+	if len(stream) == 1 {
+		if ok, _ := OutputStreamP(e, stream[0]); ok == Nil {
+			return SignalCondition(e, instance.NewDomainError(e, stream[0], class.Stream), Nil)
+		}
+		output = stream[0]
+	}
+	if err := ensureProfile(e); err != nil {
+		return nil, err
+	}
+	report := &strings.Builder{}
+	currentProfile.Report(report)
+	if _, err := Format(e, output, instance.NewString([]rune("~A")), instance.NewString([]rune(report.String()))); err != nil {
+		return nil, err
+	}
+	return Nil, nil
+}
+
+// WriteProfile writes the profile to the file named pathname in the format of
+// pprof and returns pathname.
+func WriteProfile(e env.Environment, pathname ilos.Instance) (ilos.Instance, ilos.Instance) {
+	path, err := pathnameOf(e, pathname)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensureProfile(e); err != nil {
+		return nil, err
+	}
+	file, fail := os.Create(path)
+	if fail != nil {
+		return signalFileError(e, path, fail)
+	}
+	if fail := currentProfile.WritePprof(file); fail != nil {
+		file.Close()
+		return signalFileError(e, path, fail)
+	}
+	if fail := file.Close(); fail != nil {
+		return signalFileError(e, path, fail)
+	}
+	return pathname, nil
+}
@@ -0,0 +1,201 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"bytes"
+	"compress/gzip"
+	"io/ioutil"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+func TestStartProfiling(t *testing.T) {
+	defer func() {
+		currentProfile = nil
+		delete(TopLevel.Function[0], instance.NewSymbol("PROFILE-FACT"))
+	}()
+	execTests(t, StartProfiling, []test{
+		{
+			exp:     `(defun profile-fact (n) (if (= n 0) 1 (* n (profile-fact (- n 1)))))`,
+			want:    `'profile-fact`,
+			wantErr: false,
+		},
+		{
+			exp:     `(profile-report)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(progn (start-profiling) (profile-fact 3) (stop-profiling))`,
+			want:    `nil`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((s (create-string-output-stream))) (profile-report s) (if (string-index "PROFILE-FACT" (get-output-stream-string s)) t nil))`,
+			want:    `t`,
+			wantErr: false,
+		},
+		{
+			exp:     `(progn (start-profiling :mode :sampling :interval 100) (profile-fact 3) (stop-profiling))`,
+			want:    `nil`,
+			wantErr: false,
+		},
+		{
+			exp:     `(start-profiling :mode :random)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(start-profiling :interval 0)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(start-profiling :mode)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(profile-report 1)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestProfileDeterministic(t *testing.T) {
+	defer func() {
+		currentProfile = nil
+		delete(TopLevel.Function[0], instance.NewSymbol("PROFILE-FACT"))
+	}()
+	withClock(&tickClock{}, func() {
+		for _, exp := range []string{
+			`(defun profile-fact (n) (if (= n 0) 1 (* n (profile-fact (- n 1)))))`,
+			`(progn (start-profiling) (profile-fact 1) (stop-profiling))`,
+		} {
+			obj, _ := readFromString(exp)
+			if _, err := Eval(TopLevel, obj); err != nil {
+				t.Fatalf("Eval(%v) err = %v", exp, err)
+			}
+		}
+	})
+	// Every call takes a second of the clock to enter and another to exit.
+	want := map[string]time.Duration{
+		"*\nPROFILE-FACT":               time.Second,
+		"-\nPROFILE-FACT":               time.Second,
+		"=\nPROFILE-FACT":               time.Second,
+		"=\nPROFILE-FACT\nPROFILE-FACT": time.Second,
+		"PROFILE-FACT":                  5 * time.Second,
+		"PROFILE-FACT\nPROFILE-FACT":    2 * time.Second,
+	}
+	samples := currentProfile.sortedSamples()
+	if len(samples) != len(want) {
+		t.Errorf("samples = %v, want %v", samples, want)
+	}
+	for _, s := range samples {
+		key := strings.Join(s.stack, "\n")
+		if s.count != 1 || s.time != want[key] {
+			t.Errorf("sample %q = %v calls, %v, want 1 call, %v", key, s.count, s.time, want[key])
+		}
+	}
+}
+
+func TestProfileMapcar(t *testing.T) {
+	defer func() {
+		currentProfile = nil
+		delete(TopLevel.Function[0], instance.NewSymbol("PROFILE-TWICE"))
+	}()
+	for _, exp := range []string{
+		`(defun profile-twice (n) (* n 2))`,
+		`(progn (start-profiling) (mapcar #'profile-twice '(1 2)) (stop-profiling))`,
+	} {
+		obj, _ := readFromString(exp)
+		if _, err := Eval(TopLevel, obj); err != nil {
+			t.Fatalf("Eval(%v) err = %v", exp, err)
+		}
+	}
+	// The functions called by mapcar are profiled as the calls evaluated.
+	for _, s := range currentProfile.sortedSamples() {
+		if strings.Join(s.stack, "\n") == "PROFILE-TWICE\nMAPCAR" {
+			if s.count != 2 {
+				t.Errorf("sample %q = %v calls, want 2", "PROFILE-TWICE\nMAPCAR", s.count)
+			}
+			return
+		}
+	}
+	t.Errorf("samples = %v, want PROFILE-TWICE called by MAPCAR", currentProfile.sortedSamples())
+}
+
+func TestProfileStopConcurrently(t *testing.T) {
+	defer func() {
+		currentProfile = nil
+		delete(TopLevel.Function[0], instance.NewSymbol("PROFILE-LOOP"))
+	}()
+	obj, _ := readFromString(`(defun profile-loop (n) (if (= n 0) 0 (profile-loop (- n 1))))`)
+	if _, err := Eval(TopLevel, obj); err != nil {
+		t.Fatal(err)
+	}
+	obj, _ = readFromString(`(profile-loop 1000)`)
+	for _, sampling := range []bool{false, true} {
+		p := StartProfile(TopLevel.Clock, sampling, time.Millisecond)
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			Eval(TopLevel.NewDynamic(), obj)
+		}()
+		p.Stop()
+		if sampling {
+			select {
+			case <-p.done:
+			default:
+				t.Errorf("Stop() returned before the sampler exited")
+			}
+		}
+		<-done
+		if p.isRunning() {
+			t.Errorf("Stop() did not stop the profile")
+		}
+	}
+}
+
+func TestProfileReport(t *testing.T) {
+	p := &Profile{samples: map[string]*profileSample{}}
+	p.add([]string{"F"}, 3*time.Millisecond, 100, 1)
+	p.add([]string{"G", "F"}, 2*time.Millisecond, 20, 1)
+	p.add([]string{"G", "F"}, 2*time.Millisecond, 20, 1)
+	p.add([]string{"F", "G", "F"}, time.Millisecond, 0, 0)
+	var out bytes.Buffer
+	if err := p.Report(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := "" +
+		"     calls      self ms     total ms   self bytes  function\n" +
+		"         2        4.000        8.000          100  F\n" +
+		"         2        4.000        5.000           40  G\n"
+	if out.String() != want {
+		t.Errorf("Report() = %q, want %q", out.String(), want)
+	}
+	out.Reset()
+	if err := p.WritePprof(&out); err != nil {
+		t.Fatal(err)
+	}
+	r, err := gzip.NewReader(&out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{"calls", "nanoseconds", "alloc_space", "F", "G"} {
+		if !bytes.Contains(data, []byte(s)) {
+			t.Errorf("WritePprof() has no %q", s)
+		}
+	}
+}
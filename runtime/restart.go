@@ -0,0 +1,402 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"github.com/islisp-dev/iris/runtime/env"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+/*
+A restart is a named point of recovery established dynamically. Handlers may
+choose one of the restarts which are active when a condition is signaled and
+transfer control to it with invoke-restart. Restarts established by
+restart-case exit the restart-case form, restarts established by with-restarts
+are called in place and return their values to invoke-restart.
+
+The active restarts are kept in the dynamic variable IRIS/RESTARTS, innermost
+first.
+*/
+
+var restartsVariable = instance.NewSymbol("IRIS/RESTARTS")
+
+func activeRestarts(e env.Environment) []ilos.Instance {
+	if restarts, ok := e.DynamicVariable.Get(restartsVariable); ok {
+		return restarts.(instance.List).Slice()
+	}
+	return []ilos.Instance{}
+}
+
+// pushRestarts returns a new environment in which restarts are active in
+// addition to the restarts of e.
+func pushRestarts(e env.Environment, restarts ...ilos.Instance) env.Environment {
+	e = e.NewLexical()
+	list := Nil
+	if l, ok := e.DynamicVariable.Get(restartsVariable); ok {
+		list = l
+	}
+	for i := len(restarts) - 1; i >= 0; i-- {
+		list = instance.NewCons(restarts[i], list)
+	}
+	e.DynamicVariable.Define(restartsVariable, list)
+	return e
+}
+
+// escaped reports whether err is the escape created by newEscape with uid,
+// and returns the index and the object of the escape.
+func escaped(err, uid ilos.Instance) (int, ilos.Instance, bool) {
+	if err == nil || !ilos.InstanceOf(class.CatchTag, err) {
+		return -1, nil, false
+	}
+	tag, _ := err.(instance.Instance).GetSlotValue(instance.NewSymbol("IRIS.TAG"), class.Escape)
+	uid1, _ := err.(instance.Instance).GetSlotValue(instance.NewSymbol("IRIS.UID"), class.Escape)
+	if uid != uid1 {
+		return -1, nil, false
+	}
+	object, _ := err.(instance.Instance).GetSlotValue(instance.NewSymbol("IRIS.OBJECT"), class.CatchTag)
+	return int(tag.(instance.Integer)), object, true
+}
+
+// newEscape creates the escape which transfers the control to the form
+// established uid.
+func newEscape(index int, uid, object ilos.Instance) ilos.Instance {
+	return instance.NewCatchTag(instance.NewInteger(index), uid, object)
+}
+
+// newEscapeRestart creates a restart which escapes with the list of its
+// arguments.
+func newEscapeRestart(name, report, interactive, condition ilos.Instance, index int, uid ilos.Instance) ilos.Instance {
+	function := instance.NewFunction(name, func(e env.Environment, arguments ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+		list, err := List(e, arguments...)
+		if err != nil {
+			return nil, err
+		}
+		return nil, newEscape(index, uid, list)
+	})
+	return instance.NewRestart(name, report, function, interactive, condition)
+}
+
+// readValue is the interactive function of the restarts which require a
+// value. It reads a form from the standard input and returns the list of the
+// value of it.
+func readValue(e env.Environment) (ilos.Instance, ilos.Instance) {
+	if _, err := Format(e, e.StandardOutput, instance.NewString([]rune("Enter a form to be evaluated: "))); err != nil {
+		return nil, err
+	}
+	form, err := Read(e)
+	if err != nil {
+		return nil, err
+	}
+	value, err := Eval(e, form)
+	if err != nil {
+		return nil, err
+	}
+	return List(e, value)
+}
+
+// signalRestartable signals condition in the environment where the restarts
+// named names are active. If one of them is invoked, its index and arguments
+// are returned. Otherwise the index is -1.
+func signalRestartable(e env.Environment, condition ilos.Instance, names ...ilos.Instance) (int, []ilos.Instance, ilos.Instance) {
+	uid := instance.NewInteger(uniqueInt())
+	interactive := instance.NewFunction(instance.NewSymbol("READ-VALUE"), readValue)
+	restarts := []ilos.Instance{}
+	for i, name := range names {
+		switch name {
+		case instance.NewSymbol("USE-VALUE"):
+			report := instance.NewString([]rune("Specify a value to use instead."))
+			restarts = append(restarts, newEscapeRestart(name, report, interactive, condition, i, uid))
+		case instance.NewSymbol("STORE-VALUE"):
+			report := instance.NewString([]rune("Specify a value to store and use."))
+			restarts = append(restarts, newEscapeRestart(name, report, interactive, condition, i, uid))
+		case instance.NewSymbol("RETRY"):
+			report := instance.NewString([]rune("Retry the evaluation."))
+			restarts = append(restarts, newEscapeRestart(name, report, Nil, condition, i, uid))
+		default:
+			restarts = append(restarts, newEscapeRestart(name, Nil, Nil, condition, i, uid))
+		}
+	}
+	_, err := SignalCondition(pushRestarts(e, restarts...), condition, Nil)
+	if index, arguments, ok := escaped(err, uid); ok {
+		return index, arguments.(instance.List).Slice(), nil
+	}
+	return -1, nil, err
+}
+
+// RestartCase evaluates form in a dynamic environment where the restarts
+// defined by clauses are active. Each clause has the form
+// (name lambda-list [:report string] [:interactive function] form*). When a
+// restart is invoked, the control is transferred to the restart-case form, the
+// arguments of invoke-restart are bound to the lambda-list of the clause and
+// the forms are evaluated as progn.
+func RestartCase(e env.Environment, form ilos.Instance, clauses ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	uid := instance.NewInteger(uniqueInt())
+	restarts := []ilos.Instance{}
+	functions := []ilos.Instance{}
+	for i, clause := range clauses {
+		if err := ensure(e, class.List, clause); err != nil {
+			return nil, err
+		}
+		definition := clause.(instance.List).Slice()
+		if len(definition) < 2 {
+			return SignalCondition(e, instance.NewProgramError(e), Nil)
+		}
+		name, lambdaList, forms := definition[0], definition[1], definition[2:]
+		if err := ensure(e, class.Symbol, name); err != nil {
+			return nil, err
+		}
+		report, interactive := Nil, Nil
+		for len(forms) > 1 {
+			if forms[0] == instance.NewSymbol(":REPORT") {
+				if err := ensure(e, class.String, forms[1]); err != nil {
+					return nil, err
+				}
+				report = forms[1]
+			} else if forms[0] == instance.NewSymbol(":INTERACTIVE") {
+				var err ilos.Instance
+				if interactive, err = Eval(e, forms[1]); err != nil {
+					return nil, err
+				}
+				if err := ensure(e, class.Function, interactive); err != nil {
+					return nil, err
+				}
+			} else {
+				break
+			}
+			forms = forms[2:]
+		}
+		fun, err := newNamedFunction(e, name, lambdaList, forms...)
+		if err != nil {
+			return nil, err
+		}
+		functions = append(functions, fun)
+		restarts = append(restarts, newEscapeRestart(name, report, interactive, Nil, i, uid))
+	}
+	ret, err := Eval(pushRestarts(e, restarts...), form)
+	if index, arguments, ok := escaped(err, uid); ok {
+		return functions[index].(instance.Applicable).Apply(e.NewDynamic(), arguments.(instance.List).Slice()...)
+	}
+	return ret, err
+}
+
+// WithRestarts evaluates forms as progn in a dynamic environment where the
+// restarts defined by bindings are active. Each binding has the form (name
+// function [report]). When a restart is invoked, function is called with the
+// arguments of invoke-restart without any transfer of control and its value
+// is returned by invoke-restart.
+func WithRestarts(e env.Environment, bindings ilos.Instance, forms ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.List, bindings); err != nil {
+		return nil, err
+	}
+	restarts := []ilos.Instance{}
+	for _, binding := range bindings.(instance.List).Slice() {
+		if err := ensure(e, class.List, binding); err != nil {
+			return nil, err
+		}
+		definition := binding.(instance.List).Slice()
+		if len(definition) < 2 || len(definition) > 3 {
+			return SignalCondition(e, instance.NewProgramError(e), Nil)
+		}
+		if err := ensure(e, class.Symbol, definition[0]); err != nil {
+			return nil, err
+		}
+		function, err := Eval(e, definition[1])
+		if err != nil {
+			return nil, err
+		}
+		if err := ensure(e, class.Function, function); err != nil {
+			return nil, err
+		}
+		report := Nil
+		if len(definition) == 3 {
+			if err := ensure(e, class.String, definition[2]); err != nil {
+				return nil, err
+			}
+			report = definition[2]
+		}
+		restarts = append(restarts, instance.NewRestart(definition[0], report, function, Nil, Nil))
+	}
+	return Progn(pushRestarts(e, restarts...), forms...)
+}
+
+// ComputeRestarts returns the list of the active restarts, innermost first.
+// If condition is given, the restarts associated with other conditions are
+// excluded.
+func ComputeRestarts(e env.Environment, condition ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if len(condition) > 1 {
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("COMPUTE-RESTARTS"), 0, 1, len(condition)), Nil)
+	}
+	restarts := []ilos.Instance{}
+	for _, restart := range activeRestarts(e) {
+		c := restart.(*instance.Restart).Condition
+		if len(condition) == 0 || condition[0] == Nil || c == Nil {
+			restarts = append(restarts, restart)
+			continue
+		}
+		if same, _ := Eq(e, c, condition[0]); same == T {
+			restarts = append(restarts, restart)
+		}
+	}
+	return List(e, restarts...)
+}
+
+// FindRestart returns the innermost active restart named name, or nil if
+// there is no such restart.
+func FindRestart(e env.Environment, name ilos.Instance, condition ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.Symbol, name); err != nil {
+		return nil, err
+	}
+	restarts, err := ComputeRestarts(e, condition...)
+	if err != nil {
+		return nil, err
+	}
+	for _, restart := range restarts.(instance.List).Slice() {
+		if restart.(*instance.Restart).Name == name {
+			return restart, nil
+		}
+	}
+	return Nil, nil
+}
+
+// findActiveRestart returns the restart designated by restart which is a
+// restart or the name of it. An error shall be signaled if no such restart is
+// active (error-id. control-error).
+func findActiveRestart(e env.Environment, restart ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if ilos.InstanceOf(class.Symbol, restart) {
+		r, err := FindRestart(e, restart)
+		if err != nil {
+			return nil, err
+		}
+		if r == Nil {
+			return SignalCondition(e, instance.NewControlError(e), Nil)
+		}
+		return r, nil
+	}
+	if err := ensure(e, class.Restart, restart); err != nil {
+		return nil, err
+	}
+	for _, r := range activeRestarts(e) {
+		if r == restart {
+			return r, nil
+		}
+	}
+	return SignalCondition(e, instance.NewControlError(e), Nil)
+}
+
+// InvokeRestart calls the function of the restart designated by restart with
+// arguments.
+func InvokeRestart(e env.Environment, restart ilos.Instance, arguments ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	r, err := findActiveRestart(e, restart)
+	if err != nil {
+		return nil, err
+	}
+	return r.(*instance.Restart).Function.(instance.Applicable).Apply(e, arguments...)
+}
+
+// InvokeRestartInteractively calls the function of the restart designated by
+// restart with the arguments obtained from its interactive function.
+func InvokeRestartInteractively(e env.Environment, restart ilos.Instance) (ilos.Instance, ilos.Instance) {
+	r, err := findActiveRestart(e, restart)
+	if err != nil {
+		return nil, err
+	}
+	arguments := Nil
+	if interactive := r.(*instance.Restart).Interactive; interactive != Nil {
+		if arguments, err = interactive.(instance.Applicable).Apply(e); err != nil {
+			return nil, err
+		}
+		if err := ensure(e, class.List, arguments); err != nil {
+			return nil, err
+		}
+	}
+	return r.(*instance.Restart).Function.(instance.Applicable).Apply(e, arguments.(instance.List).Slice()...)
+}
+
+// RestartName returns the name of restart.
+func RestartName(e env.Environment, restart ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.Restart, restart); err != nil {
+		return nil, err
+	}
+	return restart.(*instance.Restart).Name, nil
+}
+
+func invokeRestartIfActive(e env.Environment, name ilos.Instance, condition []ilos.Instance, arguments ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	restart, err := FindRestart(e, name, condition...)
+	if err != nil {
+		return nil, err
+	}
+	if restart == Nil {
+		return Nil, nil
+	}
+	return InvokeRestart(e, restart, arguments...)
+}
+
+// UseValue invokes the innermost USE-VALUE restart with value. If there is no
+// such restart, nil is returned.
+func UseValue(e env.Environment, value ilos.Instance, condition ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return invokeRestartIfActive(e, instance.NewSymbol("USE-VALUE"), condition, value)
+}
+
+// StoreValue invokes the innermost STORE-VALUE restart with value. If there is
+// no such restart, nil is returned.
+func StoreValue(e env.Environment, value ilos.Instance, condition ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return invokeRestartIfActive(e, instance.NewSymbol("STORE-VALUE"), condition, value)
+}
+
+// Retry invokes the innermost RETRY restart. If there is no such restart, nil
+// is returned.
+func Retry(e env.Environment, condition ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return invokeRestartIfActive(e, instance.NewSymbol("RETRY"), condition)
+}
+
+// InteractiveHandler is a handler for interactive sessions. It reports
+// condition with the active restarts to the standard output and invokes the
+// restart chosen by the user from the standard input. If the user chooses to
+// abort, the condition is passed to the toplevel.
+func InteractiveHandler(e env.Environment, condition ilos.Instance) (ilos.Instance, ilos.Instance) {
+	restarts, err := ComputeRestarts(e, condition)
+	if err != nil {
+		return nil, err
+	}
+	if restarts == Nil {
+		return TopLevelHander(e, condition)
+	}
+	if _, err := ReportCondition(e, condition, e.StandardOutput); err != nil {
+		return nil, err
+	}
+	if _, err := Format(e, e.StandardOutput, instance.NewString([]rune("~%Available restarts:~%  0: [ABORT] Return to toplevel.~%"))); err != nil {
+		return nil, err
+	}
+	for i, restart := range restarts.(instance.List).Slice() {
+		r := restart.(*instance.Restart)
+		report := r.Report
+		if report == Nil {
+			report = instance.NewString([]rune(""))
+		}
+		if _, err := Format(e, e.StandardOutput, instance.NewString([]rune("  ~A: [~A] ~A~%")), instance.NewInteger(i+1), r.Name, report); err != nil {
+			return nil, err
+		}
+	}
+	for {
+		if _, err := Format(e, e.StandardOutput, instance.NewString([]rune("Choose a restart: "))); err != nil {
+			return nil, err
+		}
+		choice, err := Read(e)
+		if err != nil {
+			return nil, err
+		}
+		if ilos.InstanceOf(class.Integer, choice) {
+			index := int(choice.(instance.Integer))
+			if index == 0 {
+				return TopLevelHander(e, condition)
+			}
+			if 0 < index && index <= restarts.(instance.List).Length() {
+				return InvokeRestartInteractively(e, restarts.(instance.List).Nth(index-1))
+			}
+		}
+	}
+}
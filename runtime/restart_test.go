@@ -0,0 +1,171 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import "testing"
+
+func TestRestartCase(t *testing.T) {
+	tests := []test{
+		{
+			exp:     `(restart-case (+ 1 2) (use-value (x) x))`,
+			want:    `3`,
+			wantErr: false,
+		},
+		{
+			exp:     `(restart-case (invoke-restart 'use-value 10) (use-value (x) (* x 2)))`,
+			want:    `20`,
+			wantErr: false,
+		},
+		{
+			exp:     `(restart-case (+ 1 (invoke-restart 'my-restart 1 2)) (my-restart (x y) :report "Mine." (list x y)))`,
+			want:    `'(1 2)`,
+			wantErr: false,
+		},
+		{
+			exp: `
+			(with-handler (lambda (c) (invoke-restart 'use-value 5))
+				(restart-case (error "err") (use-value (x) x)))
+			`,
+			want:    `5`,
+			wantErr: false,
+		},
+		{
+			exp: `
+			(flet ((f () (error "err")))
+				(with-handler (lambda (c) (invoke-restart 'use-value 5))
+					(restart-case (f) (use-value (x) x))))
+			`,
+			want:    `5`,
+			wantErr: false,
+		},
+		{
+			exp:     `(invoke-restart 'no-such-restart)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	}
+	execTests(t, RestartCase, tests)
+}
+
+func TestWithRestarts(t *testing.T) {
+	tests := []test{
+		{
+			exp:     `(with-restarts ((double (lambda (x) (* x 2)))) (+ 1 (invoke-restart 'double 5)))`,
+			want:    `11`,
+			wantErr: false,
+		},
+		{
+			exp:     `(with-restarts ((foo #'list "Foo.")) (restart-name (find-restart 'foo)))`,
+			want:    `'foo`,
+			wantErr: false,
+		},
+	}
+	execTests(t, WithRestarts, tests)
+}
+
+func TestComputeRestarts(t *testing.T) {
+	tests := []test{
+		{
+			exp:     `(compute-restarts)`,
+			want:    `nil`,
+			wantErr: false,
+		},
+		{
+			exp:     `(restart-case (mapcar #'restart-name (compute-restarts)) (foo () 1) (bar () 2))`,
+			want:    `'(foo bar)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(restart-case (restart-case (mapcar #'restart-name (compute-restarts)) (foo () 1)) (bar () 2))`,
+			want:    `'(foo bar)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(find-restart 'foo)`,
+			want:    `nil`,
+			wantErr: false,
+		},
+		{
+			exp: `
+(with-handler
+  (lambda (c1)
+    (use-value (with-handler
+                 (lambda (c2) (use-value (length (compute-restarts c2)) c2))
+                 undefined-variable-for-compute-restarts)
+               c1))
+  undefined-variable-for-compute-restarts)`,
+			want:    `3`,
+			wantErr: false,
+		},
+	}
+	execTests(t, ComputeRestarts, tests)
+}
+
+func TestUseValue(t *testing.T) {
+	tests := []test{
+		{
+			exp:     `(with-handler (lambda (c) (use-value 42 c)) (+ undefined-variable-for-use-value 1))`,
+			want:    `43`,
+			wantErr: false,
+		},
+		{
+			exp:     `(with-handler (lambda (c) (use-value #'list)) (undefined-function-for-use-value 1 2))`,
+			want:    `'(1 2)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(use-value 1)`,
+			want:    `nil`,
+			wantErr: false,
+		},
+	}
+	execTests(t, UseValue, tests)
+}
+
+func TestStoreValue(t *testing.T) {
+	tests := []test{
+		{
+			exp:     `(with-handler (lambda (c) (store-value 42)) undefined-variable-for-store-value)`,
+			want:    `42`,
+			wantErr: false,
+		},
+		{
+			exp:     `undefined-variable-for-store-value`,
+			want:    `42`,
+			wantErr: false,
+		},
+	}
+	execTests(t, StoreValue, tests)
+}
+
+func TestRetry(t *testing.T) {
+	tests := []test{
+		{
+			exp: `
+			(with-handler (lambda (c) (defglobal undefined-variable-for-retry 7) (retry))
+				undefined-variable-for-retry)
+			`,
+			want:    `7`,
+			wantErr: false,
+		},
+	}
+	execTests(t, Retry, tests)
+}
+
+func TestContinueRestart(t *testing.T) {
+	tests := []test{
+		{
+			exp:     `(with-handler (lambda (c) (invoke-restart 'continue 3)) (cerror "Use 3." "err"))`,
+			want:    `3`,
+			wantErr: false,
+		},
+		{
+			exp:     `(with-handler (lambda (c) (invoke-restart 'continue 3)) (error "err"))`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	}
+	execTests(t, SignalCondition, tests)
+}
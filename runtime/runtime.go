@@ -5,6 +5,7 @@
 package runtime
 
 import (
+	"fmt"
 	"math"
 	"os"
 
@@ -48,13 +49,36 @@ func defgeneric(name string, function interface{}) {
 	TopLevel.Function.Define(symbol, generic)
 }
 
+func defmethod(name string, classes []ilos.Class, function interface{}) {
+	symbol := instance.NewSymbol(name)
+	parameters := []ilos.Instance{}
+	for i := range classes {
+		parameters = append(parameters, instance.NewSymbol(fmt.Sprintf("X%v", i)))
+	}
+	lambdaList, _ := List(TopLevel, parameters...)
+	generic, ok := TopLevel.Function.Get(symbol)
+	if !ok {
+		generic = instance.NewGenericFunction(symbol, lambdaList, nil, class.StandardGenericFunction)
+		TopLevel.Function.Define(symbol, generic)
+	}
+	generic.(*instance.GenericFunction).AddMethod(nil, lambdaList, classes, instance.NewFunction(symbol, function))
+}
+
 func defglobal(name string, value ilos.Instance) {
 	symbol := instance.NewSymbol(name)
 	TopLevel.Variable.Define(symbol, value)
 }
 
+func defdynamic(name string, value ilos.Instance) {
+	symbol := instance.NewSymbol(name)
+	TopLevel.DynamicVariable.Define(symbol, value)
+}
+
 func init() {
+	TopLevel.Clock = systemClock{}
 	defglobal("*PI*", instance.Float(math.Pi))
+	defglobal("*COMMAND-LINE-ARGUMENTS*", Nil)
+	defdynamic("*TRACE-OUTPUT*", TopLevel.ErrorOutput)
 	defglobal("*MOST-POSITIVE-FLOAT*", MostPositiveFloat)
 	defglobal("*MOST-NEGATIVE-FLOAT*", MostNegativeFloat)
 	defun("-", Substruct)
@@ -70,10 +94,21 @@ func init() {
 	defspecial("AND", And)
 	defun("APPEND", Append)
 	defun("APPLY", Apply)
+	defun("APROPOS", Apropos)
 	defun("ARRAY-DIMENSIONS", ArrayDimensions)
 	defun("AREF", Aref)
 	defun("ASSOC", Assoc)
-	// TODO: defspecial2("ASSURE", Assure)
+	defspecial("ASSERT-EQUAL", AssertEqual)
+	defspecial("ASSERT-ERROR", AssertError)
+	defspecial("ASSERT-FALSE", AssertFalse)
+	defspecial("ASSERT-TRUE", AssertTrue)
+	defspecial("ASSURE", Assure)
+	defun("ARITY-ERROR-ACTUAL", ArityErrorActual)
+	defun("ARITY-ERROR-EXPECTED-MAX", ArityErrorExpectedMax)
+	defun("ARITY-ERROR-EXPECTED-MIN", ArityErrorExpectedMin)
+	defun("ARITY-ERROR-NAME", ArityErrorName)
+	defun("ARITHMETIC-ERROR-OPERANDS", ArithmeticErrorOperands)
+	defun("ARITHMETIC-ERROR-OPERATION", ArithmeticErrorOperation)
 	defun("ATAN", Atan)
 	defun("ATAN2", Atan2)
 	defun("ATANH", Atanh)
@@ -100,6 +135,7 @@ func init() {
 	defun("CLASS-OF", ClassOf)
 	defun("CLOSE", Close)
 	// TODO defun2("COERCION", Coercion)
+	defun("COMPUTE-RESTARTS", ComputeRestarts)
 	defspecial("COND", Cond)
 	defun("CONDITION-CONTINUABLE", ConditionContinuable)
 	defun("CONS", Cons)
@@ -115,6 +151,7 @@ func init() {
 	defun("CREATE-STRING-INPUT-STREAM", CreateStringInputStream)
 	defun("CREATE-STRING-OUTPUT-STREAM", CreateStringOutputStream)
 	defun("CREATE-VECTOR", CreateVector)
+	defun("CURRENT-DIRECTORY", CurrentDirectory)
 	defspecial("DEFCLASS", Defclass)
 	defspecial("DEFCONSTANT", Defconstant)
 	defspecial("DEFDYNAMIC", Defdynamic)
@@ -122,21 +159,36 @@ func init() {
 	defspecial("DEFMETHOD", Defmethod)
 	defspecial("DEFGLOBAL", Defglobal)
 	defspecial("DEFMACRO", Defmacro)
+	defspecial("DEFSUITE", Defsuite)
+	defspecial("DEFTEST", Deftest)
 	defspecial("DEFUN", Defun)
+	defun("DELETE-FILE", DeleteFile)
+	defun("DESCRIBE", Describe)
+	defun("DIRECTORY", Directory)
 	defun("DIV", Div)
+	defun("DOCUMENTATION", Documentation)
+	defun("DOMAIN-ERROR-EXPECTED-CLASS", DomainErrorExpectedClass)
+	defun("DOMAIN-ERROR-OBJECT", DomainErrorObject)
 	defspecial("DYNAMIC", Dynamic)
 	defspecial("DYNAMIC-LET", DynamicLet)
 	defun("ELT", Elt)
+	defun("ENSURE-DIRECTORIES-EXIST", EnsureDirectoriesExist)
 	defun("EQ", Eq)
 	defun("EQL", Eql)
 	defun("EQUAL", Equal)
 	defun("ERROR", Error)
 	defun("ERROR-OUTPUT", ErrorOutput)
+	defun("EXIT", Exit)
 	defun("EXP", Exp)
 	defun("EXPT", Expt)
-	// TODO defun2("FILE-LENGTH", FileLength)
-	// TODO defun2("FILE-POSITION", FilePosition)
-	// TODO defun2("FINISH-OUTPUT", FinishOutput)
+	defun("FILE-ERROR-MESSAGE", FileErrorMessage)
+	defun("FILE-ERROR-PATHNAME", FileErrorPathname)
+	defun("FILE-LENGTH", FileLength)
+	defun("FILE-POSITION", FilePosition)
+	defun("FILE-SIZE", FileSize)
+	defun("FILE-WRITE-DATE", FileWriteDate)
+	defun("FINISH-OUTPUT", FinishOutput)
+	defun("FIND-RESTART", FindRestart)
 	defspecial("FLET", Flet)
 	defun("FLOAT", Float)
 	defun("FLOATP", Floatp)
@@ -158,20 +210,30 @@ func init() {
 	defun("GENERAL-VECTOR-P", GeneralVectorP)
 	// TODO defun2("GENERIC-FUNCTION-P", GenericFunctionP)
 	defun("GENSYM", Gensym)
-	// TODO defun2("GET-INTERNAL-REAL-TIME", GetInternalRealTime)
-	// TODO defun2("GET-INTERNAL-RUN-TIME", GetInternalRunTime)
+	defun("GET-INTERNAL-REAL-TIME", GetInternalRealTime)
+	defun("GET-INTERNAL-RUN-TIME", GetInternalRunTime)
 	defun("GET-OUTPUT-STREAM-STRING", GetOutputStreamString)
-	// TODO defun2("GET-UNIVERSAL-TIME", GetUniversalTime)
+	defun("GET-UNIVERSAL-TIME", GetUniversalTime)
+	defun("GETENV", Getenv)
 	defspecial("GO", Go)
+	defspecial("HANDLER-CASE", HandlerCase)
 	// TODO defun2("IDENTITY", Identity)
 	defspecial("IF", If)
-	// TODO defspecial2("IGNORE-ERRORS", IgnoreErrors)
+	defspecial("IGNORE-ERRORS", IgnoreErrors)
+	defun("IMMUTABLE-BINDING-NAME", ImmutableBindingName)
+	defspecial("IN-SUITE", InSuite)
+	defun("INDEX-OUT-OF-RANGE-INDEX", IndexOutOfRangeIndex)
+	defun("INDEX-OUT-OF-RANGE-SEQUENCE", IndexOutOfRangeSequence)
 	defgeneric("INITIALIZE-OBJECT", InitializeObject) // TODO change generic function
 	defun("INPUT-STREAM-P", InputStreamP)
 	defun("INSTANCEP", Instancep)
 	// TODO defun2("INTEGER", Integer)
 	defun("INTEGERP", Integerp)
-	// TODO defun2("INTERNAL-TIME-UNITS-PER-SECOND", InternalTimeUnitsPerSecond)
+	defun("INTERNAL-ERROR-MESSAGE", InternalErrorMessage)
+	defun("INTERNAL-ERROR-NAME", InternalErrorName)
+	defun("INVOKE-RESTART", InvokeRestart)
+	defun("INVOKE-RESTART-INTERACTIVELY", InvokeRestartInteractively)
+	defun("INTERNAL-TIME-UNITS-PER-SECOND", InternalTimeUnitsPerSecond)
 	defun("ISQRT", Isqrt)
 	defspecial("LABELS", Labels)
 	defspecial("LAMBDA", Lambda)
@@ -191,6 +253,7 @@ func init() {
 	defun("MAPLIST", Maplist)
 	defun("MAX", Max)
 	defun("MEMBER", Member)
+	defun("MERGE-PATHNAMES", MergePathnames)
 	defun("MIN", Min)
 	defun("MOD", Mod)
 	defglobal("NI-L", Nil)
@@ -205,33 +268,72 @@ func init() {
 	defspecial("OR", Or)
 	defun("OUTPUT-STREAM-P", OutputStreamP)
 	defun("PARSE-NUMBER", ParseNumber)
-	// TODO defun2("PREVIEW-CHAR", PreviewChar)
-	// TODO defun2("PROVE-FILE", ProveFile)
+	defun("PARSE-ERROR-EXPECTED-CLASS", ParseErrorExpectedClass)
+	defun("PARSE-ERROR-STRING", ParseErrorString)
+	defun("PATH-BASENAME", PathBasename)
+	defun("PATH-DIRECTORY", PathDirectory)
+	defun("PATH-EXTENSION", PathExtension)
+	defun("PATH-JOIN", PathJoin)
+	defun("PREVIEW-CHAR", PreviewChar)
+	defun("PROBE-FILE", ProbeFile)
+	defun("PROCESS-ALIVE-P", ProcessAliveP)
+	defun("PROCESS-ERROR", ProcessError)
+	defun("PROCESS-EXIT-CODE", ProcessExitCode)
+	defun("PROCESS-INPUT", ProcessInput)
+	defun("PROCESS-KILL", ProcessKill)
+	defun("PROCESS-OUTPUT", ProcessOutput)
+	defun("PROCESS-PID", ProcessPid)
+	defun("PROCESS-WAIT", ProcessWait)
+	defun("PROFILE-REPORT", ProfileReport)
 	defspecial("PROGN", Progn)
 	defun("PROPERTY", Property)
 	defspecial("QUASIQUOTE", Quasiquote)
 	defspecial("QUOTE", Quote)
 	defun("QUOTIENT", Quotient)
 	defun("READ", Read)
-	// TODO defun2("READ-BYTE", ReadByte)
+	defun("READ-BYTE", ReadByte)
 	defun("READ-CHAR", ReadChar)
 	defun("READ-LINE", ReadLine)
 	defun("REMOVE-PROPERTY", RemoveProperty)
-	defun("REPORT-CONDITION", ReportCondition)
+	defun("RENAME-FILE", RenameFile)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.SeriousCondition, class.Object}, reportSeriousCondition)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.Error, class.Object}, reportError)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.SimpleError, class.Object}, reportSimpleError)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.ArithmeticError, class.Object}, reportArithmeticError)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.DivisionByZero, class.Object}, reportDivisionByZero)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.DomainError, class.Object}, reportDomainError)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.ParseError, class.Object}, reportParseError)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.UndefinedEntity, class.Object}, reportUndefinedEntity)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.StreamError, class.Object}, reportStreamError)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.EndOfStream, class.Object}, reportEndOfStream)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.ArityError, class.Object}, reportArityError)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.IndexOutOfRange, class.Object}, reportIndexOutOfRange)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.ImmutableBinding, class.Object}, reportImmutableBinding)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.InternalError, class.Object}, reportInternalError)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.FileError, class.Object}, reportFileError)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.ControlError, class.Object}, reportControlError)
+	defmethod("REPORT-CONDITION", []ilos.Class{class.StorageExhausted, class.Object}, reportStorageExhausted)
+	defspecial("RESTART-CASE", RestartCase)
+	defun("RESTART-NAME", RestartName)
+	defun("RETRY", Retry)
 	defspecial("RETURN-FROM", ReturnFrom)
 	defun("REVERSE", Reverse)
 	defun("ROUND", Round)
+	defun("RUN-PROGRAM", RunProgram)
+	defun("RUN-TESTS", RunTestsFunction)
 	defun("SET-AREF", SetAref)
 	defun("(SETF AREF)", SetAref)
 	defun("SET-CAR", SetCar)
 	defun("(SETF CAR)", SetCar)
 	defun("SET-CDR", SetCdr)
+	defun("SET-CURRENT-DIRECTORY", SetCurrentDirectory)
 	defun("(SETF CDR)", SetCdr)
+	defun("SETENV", Setenv)
 	defun("SET-DYNAMIC", SetDynamic)
 	defun("(SETF DYNAMIC)", SetDynamic)
 	defun("SET-ELT", SetElt)
 	defun("(SETF ELT)", SetElt)
-	// TODO defun2("SET-FILE-POSITION", SetFilePosition)
+	defun("SET-FILE-POSITION", SetFilePosition)
 	defun("SET-GAREF", SetGaref)
 	defun("(SETF GAREF)", SetGaref)
 	defun("SET-PROPERTY", SetProperty)
@@ -239,14 +341,23 @@ func init() {
 	defspecial("SETF", Setf)
 	defspecial("SETQ", Setq)
 	defun("SIGNAL-CONDITION", SignalCondition)
-	// TODO defun2("SIMPLE-ERROR-FORMAT-ARGUMENTS", SimpleErrorFormatArguments)
-	// TODO defun2("SIMPLE-ERROR-FORMAT-STRING", SimpleErrorFormatString)
+	defun("SIMPLE-ERROR-FORMAT-ARGUMENTS", SimpleErrorFormatArguments)
+	defun("SIMPLE-ERROR-FORMAT-STRING", SimpleErrorFormatString)
 	defun("SIN", Sin)
 	defun("SINH", Sinh)
 	defun("SQRT", Sqrt)
 	defun("STANDARD-INPUT", StandardInput)
 	defun("STANDARD-OUTPUT", StandardOutput)
+	defun("START-PROFILING", StartProfiling)
+	defun("STORE-VALUE", StoreValue)
+	defun("STOP-PROFILING", StopProfiling)
+	defun("STREAM-ERROR-STREAM", StreamErrorStream)
+	defmethod("STREAM-FINISH-OUTPUT", []ilos.Class{class.FundamentalStream}, StreamFinishOutput)
+	defmethod("STREAM-LINE-COLUMN", []ilos.Class{class.FundamentalStream}, StreamLineColumn)
+	defmethod("STREAM-READ-CHAR", []ilos.Class{class.FundamentalStream}, StreamReadChar)
 	defun("STREAM-READY-P", StreamReadyP)
+	defmethod("STREAM-WRITE-CHAR", []ilos.Class{class.FundamentalStream, class.Character}, StreamWriteChar)
+	defmethod("STREAM-WRITE-STRING", []ilos.Class{class.FundamentalStream, class.String}, StreamWriteString)
 	defun("STREAMP", Streamp)
 	defun("STRING-APPEND", StringAppend)
 	defun("STRING-INDEX", StringIndex)
@@ -264,21 +375,28 @@ func init() {
 	defspecial("TAGBODY", Tagbody)
 	defspecial("TAN", Tan)
 	defspecial("TANH", Tanh)
-	// TODO defspecial2("THE", The)
+	defspecial("THE", The)
 	defspecial("THROW", Throw)
+	defspecial("TIME", Time)
+	defspecial("TRACE", Trace)
 	defun("TRUNCATE", Truncate)
-	// TODO defun1("UNDEFINED-ENTITY-NAME", UndefinedEntityName)
-	// TODO defun2("UNDEFINED-ENTITY-NAMESPACE", UndefinedEntityNamespace)
+	defun("UNDEFINED-ENTITY-NAME", UndefinedEntityName)
+	defun("UNDEFINED-ENTITY-NAMESPACE", UndefinedEntityNamespace)
+	defspecial("UNTRACE", Untrace)
 	defspecial("UNWIND-PROTECT", UnwindProtect)
+	defun("USE-VALUE", UseValue)
 	defun("VECTOR", Vector)
 	defspecial("WHILE", While)
 	defspecial("WITH-ERROR-OUTPUT", WithErrorOutput)
 	defspecial("WITH-HANDLER", WithHandler)
 	defspecial("WITH-OPEN-INPUT-FILE", WithOpenInputFile)
+	defspecial("WITH-OPEN-IO-FILE", WithOpenIoFile)
 	defspecial("WITH-OPEN-OUTPUT-FILE", WithOpenOutputFile)
+	defspecial("WITH-RESTARTS", WithRestarts)
 	defspecial("WITH-STANDARD-INPUT", WithStandardInput)
 	defspecial("WITH-STANDARD-OUTPUT", WithStandardOutput)
-	// TODO defun2("WRITE-BYTE", WriteByte)
+	defun("WRITE-BYTE", WriteByte)
+	defun("WRITE-PROFILE", WriteProfile)
 
 	defclass("<OBJECT>", class.Object)
 	defclass("<BUILT-IN-CLASS>", class.BuiltInClass)
@@ -319,4 +437,13 @@ func init() {
 	defclass("<STORAGE-EXHAUSTED>", class.StorageExhausted)
 	defclass("<STANDARD-OBJECT>", class.StandardObject)
 	defclass("<STREAM>", class.Stream)
+	defclass("<RESTART>", class.Restart)
+	defclass("<ARITY-ERROR>", class.ArityError)
+	defclass("<INDEX-OUT-OF-RANGE>", class.IndexOutOfRange)
+	defclass("<IMMUTABLE-BINDING>", class.ImmutableBinding)
+	defclass("<INTERNAL-ERROR>", class.InternalError)
+	defclass("<FILE-ERROR>", class.FileError)
+	defclass("<PROCESS>", class.Process)
+	defclass("<ASSERTION-FAILURE>", class.AssertionFailure)
+	defclass("<FUNDAMENTAL-STREAM>", class.FundamentalStream)
 }
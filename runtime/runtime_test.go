@@ -0,0 +1,111 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"io/ioutil"
+	"math/rand"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// unsafeBuiltins touch the world outside of the interpreter, so they are not
+// worth throwing random arguments at.
+var unsafeBuiltins = map[string]bool{
+	"OPEN-INPUT-FILE":          true,
+	"OPEN-OUTPUT-FILE":         true,
+	"OPEN-IO-FILE":             true,
+	"RUN-PROGRAM":              true,
+	"DELETE-FILE":              true,
+	"RENAME-FILE":              true,
+	"ENSURE-DIRECTORIES-EXIST": true,
+	"SET-CURRENT-DIRECTORY":    true,
+	"SETENV":                   true,
+	"START-PROFILING":          true,
+	"WRITE-PROFILE":            true,
+}
+
+func randomObject(r *rand.Rand, depth int) ilos.Instance {
+	n := 11
+	if depth > 2 {
+		n = 7
+	}
+	switch r.Intn(n) {
+	case 0:
+		return Nil
+	case 1:
+		return T
+	case 2:
+		return instance.NewInteger(r.Intn(16) - 4)
+	case 3:
+		return instance.NewFloat(r.Float64()*16 - 4)
+	case 4:
+		return instance.NewCharacter(rune('a' + r.Intn(26)))
+	case 5:
+		return instance.NewString([]rune("abc"[:r.Intn(4)]))
+	case 6:
+		return instance.NewSymbol([]string{"A", "B", ":REST", "CAR"}[r.Intn(4)])
+	case 7:
+		objs := make([]ilos.Instance, r.Intn(4))
+		for i := range objs {
+			objs[i] = randomObject(r, depth+1)
+		}
+		l, _ := List(TopLevel, objs...)
+		return l
+	case 8:
+		return instance.NewCons(randomObject(r, depth+1), randomObject(r, depth+1))
+	case 9:
+		objs := make([]ilos.Instance, r.Intn(4))
+		for i := range objs {
+			objs[i] = randomObject(r, depth+1)
+		}
+		return instance.NewGeneralVector(objs)
+	default:
+		return []ilos.Instance{class.Integer, class.List, class.Error, TopLevel.Handler}[r.Intn(4)]
+	}
+}
+
+func FuzzBuiltins(f *testing.F) {
+	for seed := int64(0); seed < 8; seed++ {
+		f.Add(seed)
+	}
+	names := []string{}
+	for name := range TopLevel.Function[0] {
+		if !unsafeBuiltins[name.String()] {
+			names = append(names, name.String())
+		}
+	}
+	sort.Strings(names)
+	f.Fuzz(func(t *testing.T, seed int64) {
+		r := rand.New(rand.NewSource(seed))
+		e := TopLevel.NewDynamic()
+		e.StandardInput = instance.NewStream(strings.NewReader(""), nil)
+		e.StandardOutput = instance.NewStream(nil, ioutil.Discard)
+		e.ErrorOutput = instance.NewStream(nil, ioutil.Discard)
+		for _, name := range names {
+			function, _ := TopLevel.Function.Get(instance.NewSymbol(name))
+			for i := 0; i < 8; i++ {
+				arguments := make([]ilos.Instance, r.Intn(5))
+				for j := range arguments {
+					arguments[j] = randomObject(r, 0)
+				}
+				// Apply turns a panic of a builtin into an <internal-error>, so
+				// the panics are found in the conditions.
+				ret, err := function.(instance.Applicable).Apply(e.NewDynamic(), arguments...)
+				if err != nil && ilos.InstanceOf(class.InternalError, err) {
+					t.Fatalf("(%v %v) panicked: %v", name, arguments, err)
+				}
+				if ret == nil && err == nil {
+					t.Fatalf("(%v %v) returned neither a value nor a condition", name, arguments)
+				}
+			}
+		}
+	})
+}
@@ -25,7 +25,7 @@ func Length(e env.Environment, sequence ilos.Instance) (ilos.Instance, ilos.Inst
 		return instance.NewInteger(len(sequence.(instance.String))), nil
 	case ilos.InstanceOf(class.GeneralVector, sequence):
 		return instance.NewInteger(len(sequence.(instance.GeneralVector))), nil
-	case ilos.InstanceOf(class.List, sequence):
+	case isProperList(sequence):
 		return instance.NewInteger(sequence.(instance.List).Length()), nil
 	}
 	// TODO: class.Seq
@@ -46,22 +46,22 @@ func Elt(e env.Environment, sequence, z ilos.Instance) (ilos.Instance, ilos.Inst
 	case ilos.InstanceOf(class.String, sequence):
 		seq := sequence.(instance.String)
 		idx := int(z.(instance.Integer))
-		if idx > 0 && len(seq) <= idx {
-			return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
+		if idx < 0 || len(seq) <= idx {
+			return SignalCondition(e, instance.NewIndexOutOfRange(e, sequence, z), Nil)
 		}
 		return instance.NewCharacter(seq[idx]), nil
 	case ilos.InstanceOf(class.GeneralVector, sequence):
 		seq := sequence.(instance.GeneralVector)
 		idx := int(z.(instance.Integer))
-		if idx > 0 && len(seq) <= idx {
-			return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
+		if idx < 0 || len(seq) <= idx {
+			return SignalCondition(e, instance.NewIndexOutOfRange(e, sequence, z), Nil)
 		}
 		return seq[idx], nil
 	case ilos.InstanceOf(class.List, sequence):
 		seq := sequence.(instance.List).Slice()
 		idx := int(z.(instance.Integer))
-		if idx > 0 && len(seq) <= idx {
-			return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
+		if idx < 0 || len(seq) <= idx {
+			return SignalCondition(e, instance.NewIndexOutOfRange(e, sequence, z), Nil)
 		}
 		return seq[idx], nil
 	}
@@ -82,8 +82,8 @@ func SetElt(e env.Environment, obj, sequence, z ilos.Instance) (ilos.Instance, i
 	case ilos.InstanceOf(class.String, sequence):
 		seq := sequence.(instance.String)
 		idx := int(z.(instance.Integer))
-		if idx > 0 && len(seq) <= idx {
-			return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
+		if idx < 0 || len(seq) <= idx {
+			return SignalCondition(e, instance.NewIndexOutOfRange(e, sequence, z), Nil)
 		}
 		if err := ensure(e, class.Character, obj); err != nil {
 			return nil, err
@@ -93,16 +93,16 @@ func SetElt(e env.Environment, obj, sequence, z ilos.Instance) (ilos.Instance, i
 	case ilos.InstanceOf(class.GeneralVector, sequence):
 		seq := sequence.(instance.GeneralVector)
 		idx := int(z.(instance.Integer))
-		if idx > 0 && len(seq) <= idx {
-			return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
+		if idx < 0 || len(seq) <= idx {
+			return SignalCondition(e, instance.NewIndexOutOfRange(e, sequence, z), Nil)
 		}
 		seq[idx] = obj
 		return obj, nil
 	case ilos.InstanceOf(class.List, sequence):
 		seq := sequence.(instance.List).Slice()
 		idx := int(z.(instance.Integer))
-		if idx > 0 && len(seq) <= idx {
-			return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
+		if idx < 0 || len(seq) <= idx {
+			return SignalCondition(e, instance.NewIndexOutOfRange(e, sequence, z), Nil)
 		}
 		for idx != 0 && ilos.InstanceOf(class.Cons, sequence) {
 			idx--
@@ -129,27 +129,27 @@ func Subseq(e env.Environment, sequence, z1, z2 ilos.Instance) (ilos.Instance, i
 	}
 	start := int(z1.(instance.Integer))
 	end := int(z2.(instance.Integer))
+	if !ilos.InstanceOf(class.String, sequence) && !ilos.InstanceOf(class.GeneralVector, sequence) && !ilos.InstanceOf(class.List, sequence) {
+		return SignalCondition(e, instance.NewDomainError(e, sequence, class.Object), Nil)
+	}
+	length, err := Length(e, sequence)
+	if err != nil {
+		return nil, err
+	}
+	if start < 0 || int(length.(instance.Integer)) < start {
+		return SignalCondition(e, instance.NewIndexOutOfRange(e, sequence, z1), Nil)
+	}
+	if end < start || int(length.(instance.Integer)) < end {
+		return SignalCondition(e, instance.NewIndexOutOfRange(e, sequence, z2), Nil)
+	}
 	switch {
 	case ilos.InstanceOf(class.String, sequence):
-		seq := sequence.(instance.String)
-		if !(0 <= start && start < len(seq) && 0 <= end && end < len(seq) && start <= end) {
-			return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
-		}
-		return seq[start:end], nil
+		return sequence.(instance.String)[start:end], nil
 	case ilos.InstanceOf(class.GeneralVector, sequence):
-		seq := sequence.(instance.GeneralVector)
-		if !(0 <= start && start < len(seq) && 0 <= end && end < len(seq) && start <= end) {
-			return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
-		}
-		return seq[start:end], nil
-	case ilos.InstanceOf(class.List, sequence):
-		seq := sequence.(instance.List).Slice()
-		if !(0 < start && start < len(seq) && 0 < end && end < len(seq) && start <= end) {
-			return SignalCondition(e, instance.NewIndexOutOfRange(e), Nil)
-		}
-		return List(e, seq[start:end]...)
+		return sequence.(instance.GeneralVector)[start:end], nil
+	default:
+		return List(e, sequence.(instance.List).Slice()[start:end]...)
 	}
-	return SignalCondition(e, instance.NewDomainError(e, sequence, class.Object), Nil)
 }
 
 // Destructively modifies destination to contain the results of applying
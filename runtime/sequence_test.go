@@ -47,6 +47,16 @@ func TestElt(t *testing.T) {
 			want:    `#\a`,
 			wantErr: false,
 		},
+		{
+			exp:     `(elt "abc" -1)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(elt '(a b c) 3)`,
+			want:    `nil`,
+			wantErr: true,
+		},
 	})
 }
 
@@ -90,6 +100,16 @@ func TestSubseq(t *testing.T) {
 			want:    `#(b c d)`,
 			wantErr: false,
 		},
+		{
+			exp:     `(subseq '(a b c) 0 3)`,
+			want:    `'(a b c)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(subseq "abc" 2 1)`,
+			want:    `nil`,
+			wantErr: true,
+		},
 	})
 }
 
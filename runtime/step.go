@@ -0,0 +1,75 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"github.com/islisp-dev/iris/reader/parser"
+	"github.com/islisp-dev/iris/runtime/env"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// Stepper is told of the evaluations of the forms read from the named inputs,
+// such as the source files, which lets a debugger stop at them.
+type Stepper interface {
+	// Enter is called before form read from source is evaluated in e. The
+	// evaluation is abandoned with err unless err is nil.
+	Enter(e env.Environment, form ilos.Instance, source parser.Source) (err ilos.Instance)
+	// Leave is called after form is evaluated or abandoned.
+	Leave(e env.Environment, form ilos.Instance)
+}
+
+// stepper is the stepper set, or nil.
+var stepper Stepper
+
+// sourceUsers is the number of the coverages being taken and the stepper,
+// which share parser.Sources.
+var sourceUsers int
+
+// useSources starts recording where the forms are read from unless it is
+// already recorded, and returns the map recorded to.
+func useSources() map[*instance.Cons]parser.Source {
+	if sourceUsers == 0 {
+		parser.Sources = map[*instance.Cons]parser.Source{}
+	}
+	sourceUsers++
+	return parser.Sources
+}
+
+// releaseSources stops recording where the forms are read from unless it is
+// still used by another.
+func releaseSources() {
+	sourceUsers--
+	if sourceUsers == 0 {
+		parser.Sources = nil
+	}
+}
+
+// SetStepper starts recording where the forms are read from and telling s of
+// their evaluations. The stepper is removed if s is nil.
+func SetStepper(s Stepper) {
+	switch {
+	case stepper == nil && s != nil:
+		useSources()
+	case stepper != nil && s == nil:
+		releaseSources()
+	}
+	stepper = s
+}
+
+// step evaluates the cons form with the stepper set.
+func step(e env.Environment, form *instance.Cons) (ilos.Instance, ilos.Instance) {
+	source, ok := parser.Sources[form]
+	if !ok {
+		return evalCons(e, form)
+	}
+	s := stepper
+	if err := s.Enter(e, form, source); err != nil {
+		s.Leave(e, form)
+		return nil, err
+	}
+	defer s.Leave(e, form)
+	return evalCons(e, form)
+}
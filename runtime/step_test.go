@@ -0,0 +1,117 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/islisp-dev/iris/reader/parser"
+	"github.com/islisp-dev/iris/reader/tokenizer"
+	"github.com/islisp-dev/iris/runtime/env"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// recordingStepper records the evaluations, and abandons the form at abandon.
+type recordingStepper struct {
+	events  []string
+	abandon int
+}
+
+func (s *recordingStepper) Enter(e env.Environment, form ilos.Instance, source parser.Source) ilos.Instance {
+	s.events = append(s.events, fmt.Sprintf("enter %v at %v:%v", form, source.Start.Line, source.Start.Column))
+	if source.Start.Line == s.abandon {
+		return instance.NewExit(instance.NewInteger(3))
+	}
+	return nil
+}
+
+func (s *recordingStepper) Leave(e env.Environment, form ilos.Instance) {
+	s.events = append(s.events, fmt.Sprintf("leave %v", form))
+}
+
+func TestSetStepper(t *testing.T) {
+	s := &recordingStepper{abandon: 2}
+	SetStepper(s)
+	defer func() {
+		SetStepper(nil)
+	}()
+	r := tokenizer.NewReader(strings.NewReader("(list (+ 1 2) 'a)\n(car (list 1))"))
+	r.Name = "step.lsp"
+	form, err := parser.Parse(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Eval(TopLevel, form); err != nil {
+		t.Fatal(err)
+	}
+	form, err = parser.Parse(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Eval(TopLevel, form); err == nil {
+		t.Errorf("Eval() of the abandoned form succeeded")
+	} else if status, ok := ExitStatus(err); !ok || status != 3 {
+		t.Errorf("Eval() of the abandoned form = %v, want the exit with 3", err)
+	}
+	want := []string{
+		"enter (LIST (+ 1 2) (QUOTE A)) at 1:1",
+		"enter (+ 1 2) at 1:7",
+		"leave (+ 1 2)",
+		"enter (QUOTE A) at 1:15",
+		"leave (QUOTE A)",
+		"leave (LIST (+ 1 2) (QUOTE A))",
+		"enter (CAR (LIST 1)) at 2:1",
+		"leave (CAR (LIST 1))",
+	}
+	if !reflect.DeepEqual(s.events, want) {
+		t.Errorf("events = %q, want %q", s.events, want)
+	}
+}
+
+func TestSetStepperWithCoverage(t *testing.T) {
+	s := &recordingStepper{}
+	SetStepper(s)
+	defer SetStepper(nil)
+	r := tokenizer.NewReader(strings.NewReader("(list 1)\n(list 2)\n(list 3)"))
+	r.Name = "step.lsp"
+	read := func() ilos.Instance {
+		form, err := parser.Parse(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := Eval(TopLevel, form); err != nil {
+			t.Fatal(err)
+		}
+		return form
+	}
+	before := read()
+	c := StartCoverage()
+	covered := read()
+	c.Stop()
+	read()
+	want := []string{
+		"enter (LIST 1) at 1:1",
+		"leave (LIST 1)",
+		"enter (LIST 2) at 2:1",
+		"leave (LIST 2)",
+		"enter (LIST 3) at 3:1",
+		"leave (LIST 3)",
+	}
+	if !reflect.DeepEqual(s.events, want) {
+		t.Errorf("events = %q, want %q", s.events, want)
+	}
+	// The coverage keeps only the forms read while it was taken.
+	if _, ok := c.sources[before.(*instance.Cons)]; ok || len(c.sources) != 1 {
+		t.Errorf("sources = %v, want only %v", c.sources, covered)
+	}
+	SetStepper(nil)
+	if parser.Sources != nil {
+		t.Errorf("SetStepper(nil) did not stop recording the sources")
+	}
+}
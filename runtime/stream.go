@@ -5,12 +5,15 @@
 package runtime
 
 import (
-	"bufio"
 	"bytes"
+	"io"
+	"io/ioutil"
 	"os"
+	"reflect"
 	"strings"
 
 	"github.com/islisp-dev/iris/reader/parser"
+	"github.com/islisp-dev/iris/reader/tokenizer"
 	"github.com/islisp-dev/iris/runtime/env"
 	"github.com/islisp-dev/iris/runtime/ilos"
 	"github.com/islisp-dev/iris/runtime/ilos/class"
@@ -24,19 +27,24 @@ func Streamp(e env.Environment, obj ilos.Instance) (ilos.Instance, ilos.Instance
 	return Nil, nil
 }
 
+// OpenStreamP returns t if obj is a stream which has not been closed;
+// otherwise, returns nil.
 func OpenStreamP(e env.Environment, obj ilos.Instance) (ilos.Instance, ilos.Instance) {
-	return T, nil
+	if s, ok := streamOf(e, obj).(instance.Stream); ok && !*s.Closed {
+		return T, nil
+	}
+	return Nil, nil
 }
 
 func InputStreamP(e env.Environment, obj ilos.Instance) (ilos.Instance, ilos.Instance) {
-	if s, ok := obj.(instance.Stream); ok && s.Reader != nil {
+	if s, ok := streamOf(e, obj).(instance.Stream); ok && (s.Reader != nil || s.Bytes != nil) {
 		return T, nil
 	}
 	return Nil, nil
 }
 
 func OutputStreamP(e env.Environment, obj ilos.Instance) (ilos.Instance, ilos.Instance) {
-	if s, ok := obj.(instance.Stream); ok && s.Writer != nil {
+	if s, ok := streamOf(e, obj).(instance.Stream); ok && s.Writer != nil {
 		return T, nil
 	}
 	return Nil, nil
@@ -90,118 +98,229 @@ func WithErrorOutput(e env.Environment, streamForm ilos.Instance, forms ...ilos.
 	return Progn(e, forms...)
 }
 
-func OpenInputFile(e env.Environment, filename ilos.Instance, elementClass ...ilos.Instance) (ilos.Instance, ilos.Instance) {
-	// TODO: elementClass
-	if ok, _ := Stringp(e, filename); ok == Nil {
-		return SignalCondition(e, instance.NewDomainError(e, filename, class.String), Nil)
-	}
-	file, err := os.Open(string(filename.(instance.String)))
-	if err != nil {
-		return SignalCondition(e, instance.NewStreamError(e), Nil)
-	}
-	return instance.NewStream(file, nil), nil
+// ensureStream signals a domain error unless s is a stream whose elements are
+// 8-bit integers if binary is true, or characters otherwise, and a stream error
+// if s has been closed.
+func ensureStream(e env.Environment, s ilos.Instance, binary bool) ilos.Instance {
+	stream, ok := s.(instance.Stream)
+	if !ok || stream.Binary != binary {
+		_, err := SignalCondition(e, instance.NewDomainError(e, s, class.Stream), Nil)
+		return err
+	}
+	if *stream.Closed {
+		_, err := SignalCondition(e, instance.NewStreamError(e, s), Nil)
+		return err
+	}
+	return nil
 }
 
-func OpenOutputFile(e env.Environment, filename ilos.Instance, elementClass ...ilos.Instance) (ilos.Instance, ilos.Instance) {
-	// TODO: elementClass
-	if ok, _ := Stringp(e, filename); ok == Nil {
-		return SignalCondition(e, instance.NewDomainError(e, filename, class.String), Nil)
-	}
-	file, err := os.Open(string(filename.(instance.String)))
-	if err != nil {
-		return SignalCondition(e, instance.NewStreamError(e), Nil)
-	}
-	return instance.NewStream(nil, file), nil
+// binaryElementClass returns true if the optional element-class denotes 8-bit
+// integers, and false if it denotes characters. An error shall be signaled if
+// element-class is neither the class <character> nor 8 (error-id.
+// domain-error).
+func binaryElementClass(e env.Environment, name string, elementClass []ilos.Instance) (bool, ilos.Instance) {
+	if len(elementClass) > 1 {
+		_, err := SignalCondition(e, instance.NewArityError(e, instance.NewSymbol(name), 1, 2, 1+len(elementClass)), Nil)
+		return false, err
+	}
+	if len(elementClass) == 0 || reflect.DeepEqual(elementClass[0], class.Character) {
+		return false, nil
+	}
+	if elementClass[0] == instance.NewInteger(8) {
+		return true, nil
+	}
+	_, err := SignalCondition(e, instance.NewDomainError(e, elementClass[0], class.Integer), Nil)
+	return false, err
 }
 
-func OpenIoFile(e env.Environment, filename ilos.Instance, elementClass ...ilos.Instance) (ilos.Instance, ilos.Instance) {
-	// TODO: elementClass
+func openFile(e env.Environment, name string, filename ilos.Instance, flag int, input, output bool, elementClass []ilos.Instance) (ilos.Instance, ilos.Instance) {
 	if ok, _ := Stringp(e, filename); ok == Nil {
 		return SignalCondition(e, instance.NewDomainError(e, filename, class.String), Nil)
 	}
-	file, err := os.Open(string(filename.(instance.String)))
+	binary, err := binaryElementClass(e, name, elementClass)
 	if err != nil {
-		return SignalCondition(e, instance.NewStreamError(e), Nil)
+		return nil, err
+	}
+	file, fail := os.OpenFile(string(filename.(instance.String)), flag, 0666)
+	if fail != nil {
+		return signalFileError(e, string(filename.(instance.String)), fail)
+	}
+	stream := instance.NewFileStream(file, input, output, binary).(instance.Stream)
+	if stream.Reader != nil {
+		stream.Reader.Name = file.Name()
 	}
-	return instance.NewStream(file, file), nil
+	return stream, nil
 }
 
-func WithOpenInputFile(e env.Environment, fileSpec ilos.Instance, forms ...ilos.Instance) (ilos.Instance, ilos.Instance) {
-	if ok, _ := Consp(e, fileSpec); ok == Nil {
-		return SignalCondition(e, instance.NewDomainError(e, fileSpec, class.Cons), Nil)
-	}
-	n, err := Car(e, fileSpec)
+// OpenInputFile opens the file named filename for input. The elements of the
+// stream are characters unless element-class is 8, which denotes 8-bit
+// integers.
+func OpenInputFile(e env.Environment, filename ilos.Instance, elementClass ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return openFile(e, "OPEN-INPUT-FILE", filename, os.O_RDONLY, true, false, elementClass)
+}
+
+// OpenOutputFile opens the file named filename for output. The element-class
+// is as for open-input-file and may be followed by the keyword options
+// :if-exists and :if-does-not-exist. If the file exists, :supersede (the
+// default) truncates it, :append writes at its end, :overwrite writes from its
+// beginning and :error signals an error. If the file does not exist, :create
+// (the default) creates it and :error signals an error.
+func OpenOutputFile(e env.Environment, filename ilos.Instance, options ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	flag, elementClass, err := openOptions(e, "OPEN-OUTPUT-FILE", os.O_WRONLY, os.O_TRUNC, options)
 	if err != nil {
 		return nil, err
 	}
-	s, err := OpenInputFile(e, n)
+	return openFile(e, "OPEN-OUTPUT-FILE", filename, flag, false, true, elementClass)
+}
+
+// OpenIoFile opens the file named filename for both input and output. The
+// options are as for open-output-file, except that an existing file is
+// overwritten from its beginning by default.
+func OpenIoFile(e env.Environment, filename ilos.Instance, options ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	flag, elementClass, err := openOptions(e, "OPEN-IO-FILE", os.O_RDWR, 0, options)
 	if err != nil {
 		return nil, err
 	}
-	e.Variable.Define(n, s)
-	return Progn(e, forms...)
+	return openFile(e, "OPEN-IO-FILE", filename, flag, true, true, elementClass)
 }
 
-func WithOpenOutputFile(e env.Environment, fileSpec ilos.Instance, forms ...ilos.Instance) (ilos.Instance, ilos.Instance) {
-	if ok, _ := Consp(e, fileSpec); ok == Nil {
-		return SignalCondition(e, instance.NewDomainError(e, fileSpec, class.Cons), Nil)
+// openOptions splits the options of open-output-file and open-io-file into the
+// optional element-class and the flags for os.OpenFile, where ifExists is the
+// flag used if :if-exists is not given.
+func openOptions(e env.Environment, name string, flag, ifExists int, options []ilos.Instance) (int, []ilos.Instance, ilos.Instance) {
+	elementClass := []ilos.Instance{}
+	if len(options)%2 == 1 {
+		elementClass, options = options[:1], options[1:]
+	}
+	ifDoesNotExist := os.O_CREATE
+	for i := 0; i < len(options); i += 2 {
+		key, value := options[i], options[i+1]
+		switch {
+		case key == instance.NewSymbol(":IF-EXISTS") && value == instance.NewSymbol(":SUPERSEDE"):
+			ifExists = os.O_TRUNC
+		case key == instance.NewSymbol(":IF-EXISTS") && value == instance.NewSymbol(":APPEND"):
+			ifExists = os.O_APPEND
+		case key == instance.NewSymbol(":IF-EXISTS") && value == instance.NewSymbol(":OVERWRITE"):
+			ifExists = 0
+		case key == instance.NewSymbol(":IF-EXISTS") && value == instance.NewSymbol(":ERROR"):
+			ifExists = os.O_EXCL
+		case key == instance.NewSymbol(":IF-DOES-NOT-EXIST") && value == instance.NewSymbol(":CREATE"):
+			ifDoesNotExist = os.O_CREATE
+		case key == instance.NewSymbol(":IF-DOES-NOT-EXIST") && value == instance.NewSymbol(":ERROR"):
+			ifDoesNotExist = 0
+		case key == instance.NewSymbol(":IF-EXISTS") || key == instance.NewSymbol(":IF-DOES-NOT-EXIST"):
+			_, err := SignalCondition(e, instance.NewDomainError(e, value, class.Symbol), Nil)
+			return 0, nil, err
+		default:
+			_, err := SignalCondition(e, instance.NewDomainError(e, key, class.Symbol), Nil)
+			return 0, nil, err
+		}
 	}
-	n, err := Car(e, fileSpec)
-	if err != nil {
-		return nil, err
+	// O_EXCL is only meaningful together with O_CREATE. If neither creating
+	// nor existing files are allowed, no file can be opened.
+	if ifExists == os.O_EXCL && ifDoesNotExist == 0 {
+		_, err := SignalCondition(e, instance.NewStreamError(e, Nil), Nil)
+		return 0, nil, err
 	}
-	s, err := OpenOutputFile(e, n)
-	if err != nil {
-		return nil, err
-	}
-	e.Variable.Define(n, s)
-	return Progn(e, forms...)
+	return flag | ifExists | ifDoesNotExist, elementClass, nil
 }
 
-func WithOpenIoFile(e env.Environment, fileSpec ilos.Instance, forms ...ilos.Instance) (ilos.Instance, ilos.Instance) {
-	if ok, _ := Consp(e, fileSpec); ok == Nil {
+// withOpenFile evaluates the filename and options of fileSpec, which is
+// (name filename option*), opens the file with open and evaluates forms with
+// name bound to the stream. The stream is closed however the forms are exited,
+// and an error in closing it is signaled unless the forms exited by an error
+// or a non-local exit.
+func withOpenFile(e env.Environment, open func(env.Environment, ilos.Instance, ...ilos.Instance) (ilos.Instance, ilos.Instance), fileSpec ilos.Instance, forms ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if ok, _ := Consp(e, fileSpec); ok == Nil || !isProperList(fileSpec) || fileSpec.(instance.List).Length() < 2 {
 		return SignalCondition(e, instance.NewDomainError(e, fileSpec, class.Cons), Nil)
 	}
-	n, err := Car(e, fileSpec)
+	spec := fileSpec.(instance.List).Slice()
+	if ok, _ := Symbolp(e, spec[0]); ok == Nil {
+		return SignalCondition(e, instance.NewDomainError(e, spec[0], class.Symbol), Nil)
+	}
+	arguments := []ilos.Instance{}
+	for _, form := range spec[1:] {
+		argument, err := Eval(e, form)
+		if err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, argument)
+	}
+	s, err := open(e, arguments[0], arguments[1:]...)
 	if err != nil {
 		return nil, err
 	}
-	s, err := OpenIoFile(e, n)
+	e.Variable.Define(spec[0], s)
+	ret, err := Progn(e, forms...)
 	if err != nil {
+		s.(instance.Stream).Close() // the forms are unwinding with err
 		return nil, err
 	}
-	e.Variable.Define(n, s)
-	return Progn(e, forms...)
+	if _, err := Close(e, s); err != nil {
+		return nil, err
+	}
+	return ret, nil
 }
 
+// WithOpenInputFile opens the file as by open-input-file, evaluates forms
+// with name bound to the stream and closes the stream on exit.
+func WithOpenInputFile(e env.Environment, fileSpec ilos.Instance, forms ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return withOpenFile(e, OpenInputFile, fileSpec, forms...)
+}
+
+// WithOpenOutputFile opens the file as by open-output-file, evaluates forms
+// with name bound to the stream and closes the stream on exit.
+func WithOpenOutputFile(e env.Environment, fileSpec ilos.Instance, forms ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return withOpenFile(e, OpenOutputFile, fileSpec, forms...)
+}
+
+// WithOpenIoFile opens the file as by open-io-file, evaluates forms with name
+// bound to the stream and closes the stream on exit.
+func WithOpenIoFile(e env.Environment, fileSpec ilos.Instance, forms ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return withOpenFile(e, OpenIoFile, fileSpec, forms...)
+}
+
+// Close closes stream, flushing its output and releasing the underlying file.
+// Closing a closed stream has no effect.
 func Close(e env.Environment, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
-	// It works on file or std stream.
-	if ok, _ := Streamp(e, stream); ok == Nil {
+	s, ok := streamOf(e, stream).(instance.Stream)
+	if !ok {
 		return SignalCondition(e, instance.NewDomainError(e, stream, class.Stream), Nil)
 	}
-	/* TODO
-	if stream.(instance.Stream).Reader != nil {
-		stream.(instance.Stream).Reader.(*os.File).Close()
+	if f, ok := s.Writer.(*fundamentalStream); ok && !*s.Closed {
+		if _, err := f.call("STREAM-FINISH-OUTPUT"); err != nil {
+			return nil, err
+		}
 	}
-	if stream.(instance.Stream).Writer != nil {
-		stream.(instance.Stream).Writer.(*os.File).Close()
+	if err := s.Close(); err != nil {
+		return SignalCondition(e, instance.NewStreamError(e, stream), Nil)
 	}
-	*/
 	return Nil, nil
 }
 
-func FlushOutput(e env.Environment, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
-	// It works on file or std stream.
-	if ok, _ := Streamp(e, stream); ok == Nil {
-		return SignalCondition(e, instance.NewDomainError(e, stream, class.Stream), Nil)
+// FinishOutput writes any output buffered in output-stream to its destination.
+func FinishOutput(e env.Environment, outputStream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if ok, _ := OutputStreamP(e, outputStream); ok == Nil {
+		return SignalCondition(e, instance.NewDomainError(e, outputStream, class.Stream), Nil)
+	}
+	s := streamOf(e, outputStream).(instance.Stream)
+	if *s.Closed {
+		return SignalCondition(e, instance.NewStreamError(e, outputStream), Nil)
+	}
+	if f, ok := s.Writer.(*fundamentalStream); ok {
+		_, err := f.call("STREAM-FINISH-OUTPUT")
+		return Nil, err
 	}
-	if stream.(instance.Stream).Writer != nil {
-		stream.(instance.Stream).Writer.(*os.File).Close()
+	if err := s.Flush(); err != nil {
+		return SignalCondition(e, instance.NewStreamError(e, outputStream), Nil)
 	}
 	return Nil, nil
 }
 
 func CreateStringInputStream(e env.Environment, str ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.String, str); err != nil {
+		return nil, err
+	}
 	return instance.NewStream(strings.NewReader(string(str.(instance.String))), nil), nil
 }
 
@@ -210,105 +329,306 @@ func CreateStringOutputStream(e env.Environment) (ilos.Instance, ilos.Instance)
 }
 
 func GetOutputStreamString(e env.Environment, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
-	if ok, _ := OutputStreamP(e, stream); ok == Nil {
-		return SignalCondition(e, instance.NewDomainError(e, stream, class.Stream), Nil)
+	if s, ok := stream.(instance.Stream); ok {
+		if b, ok := s.Writer.(*bytes.Buffer); ok {
+			return instance.NewString([]rune(b.String())), nil
+		}
 	}
-	return instance.NewString([]rune(stream.(instance.Stream).Writer.(*bytes.Buffer).String())), nil
+	return SignalCondition(e, instance.NewDomainError(e, stream, class.Stream), Nil)
 }
 
-func Read(e env.Environment, options ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+// inputOptions returns the input-stream, eos-error-p and eos-value given by
+// the optional arguments of the reading functions named name. The
+// input-stream defaults to the standard input, eos-error-p to t and eos-value
+// to nil.
+func inputOptions(e env.Environment, name string, options []ilos.Instance) (instance.Stream, bool, ilos.Instance, ilos.Instance) {
+	if len(options) > 3 {
+		_, err := SignalCondition(e, instance.NewArityError(e, instance.NewSymbol(name), 0, 3, len(options)), Nil)
+		return instance.Stream{}, false, nil, err
+	}
 	s := e.StandardInput
 	if len(options) > 0 {
 		s = options[0]
 	}
-	if b, _ := InputStreamP(e, s); b == Nil {
-		return SignalCondition(e, instance.NewDomainError(e, s, class.Stream), Nil)
+	s = streamOf(e, s)
+	if ok, _ := InputStreamP(e, s); ok == Nil {
+		_, err := SignalCondition(e, instance.NewDomainError(e, s, class.Stream), Nil)
+		return instance.Stream{}, false, nil, err
 	}
-	eosErrorP := true
-	if len(options) > 1 {
-		if options[1] == Nil {
-			eosErrorP = false
-		}
+	if err := ensureStream(e, s, false); err != nil {
+		return instance.Stream{}, false, nil, err
 	}
+	eosErrorP := len(options) < 2 || options[1] != Nil
 	eosValue := Nil
 	if len(options) > 2 {
-		if options[2] == Nil {
-			eosValue = options[2]
-		}
+		eosValue = options[2]
+	}
+	return s.(instance.Stream), eosErrorP, eosValue, nil
+}
+
+// Read reads an object from input-stream. If the end of the stream is reached,
+// an error is signaled if eos-error-p is true (error-id. end-of-stream);
+// otherwise eos-value is returned. An error shall be signaled if the text is
+// not the representation of an object (error-id. parse-error).
+func Read(e env.Environment, options ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	s, eosErrorP, eosValue, err := inputOptions(e, "READ", options)
+	if err != nil {
+		return nil, err
+	}
+	v, err := parser.Parse(s.Reader)
+	if err := signaled(s); err != nil {
+		return nil, err
 	}
-	v, err := parser.Parse(s.(instance.Stream).Reader)
 	if err != nil && ilos.InstanceOf(class.EndOfStream, err) {
 		if eosErrorP {
-			return nil, err
+			return SignalCondition(e, instance.NewEndOfStream(e, s), Nil)
 		}
 		return eosValue, nil
 	}
+	if err != nil {
+		return SignalCondition(e, err, Nil)
+	}
 	return v, nil
 }
 
+// ReadChar reads a character from input-stream. The end of the stream is
+// handled as for read.
 func ReadChar(e env.Environment, options ...ilos.Instance) (ilos.Instance, ilos.Instance) {
-	s := e.StandardInput
-	if len(options) > 0 {
-		s = options[0]
-	}
-	if ok, _ := InputStreamP(e, s); ok == Nil {
-		return SignalCondition(e, instance.NewDomainError(e, s, class.Stream), Nil)
+	s, eosErrorP, eosValue, err := inputOptions(e, "READ-CHAR", options)
+	if err != nil {
+		return nil, err
 	}
-	eosErrorP := true
-	if len(options) > 1 {
-		if options[1] == Nil {
-			eosErrorP = false
-		}
+	v, _, fail := s.Reader.ReadRune()
+	if err := signaled(s); err != nil {
+		return nil, err
 	}
-	eosValue := Nil
-	if len(options) > 2 {
-		if options[2] == Nil {
-			eosValue = options[2]
+	if fail != nil {
+		if eosErrorP {
+			return SignalCondition(e, instance.NewEndOfStream(e, s), Nil)
 		}
+		return eosValue, nil
 	}
-	v, _, err := bufio.NewReader(s.(instance.Stream).Reader).ReadRune()
+	return instance.NewCharacter(v), nil
+}
+
+// PreviewChar returns the next character of input-stream without consuming
+// it, so that the following read-char returns the same character. The end of
+// the stream is handled as for read.
+func PreviewChar(e env.Environment, options ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	s, eosErrorP, eosValue, err := inputOptions(e, "PREVIEW-CHAR", options)
 	if err != nil {
+		return nil, err
+	}
+	v, _, fail := s.Reader.PeekRune()
+	if err := signaled(s); err != nil {
+		return nil, err
+	}
+	if fail != nil {
 		if eosErrorP {
-			return nil, instance.Create(e, class.EndOfStream)
+			return SignalCondition(e, instance.NewEndOfStream(e, s), Nil)
 		}
 		return eosValue, nil
 	}
 	return instance.NewCharacter(v), nil
 }
 
+// ReadLine reads characters from input-stream up to the next newline and
+// returns them as a string without the newline. The end of the stream is
+// handled as for read.
 func ReadLine(e env.Environment, options ...ilos.Instance) (ilos.Instance, ilos.Instance) {
-	s := e.StandardInput
-	if len(options) > 0 {
-		s = options[0]
+	s, eosErrorP, eosValue, err := inputOptions(e, "READ-LINE", options)
+	if err != nil {
+		return nil, err
 	}
-	if ok, _ := InputStreamP(e, s); ok == Nil {
-		return SignalCondition(e, instance.NewDomainError(e, s, class.Stream), Nil)
+	v, fail := s.Reader.ReadLine()
+	if err := signaled(s); err != nil {
+		return nil, err
 	}
-	eosErrorP := true
-	if len(options) > 1 {
-		if options[1] == Nil {
-			eosErrorP = false
+	if fail != nil {
+		if eosErrorP {
+			return SignalCondition(e, instance.NewEndOfStream(e, s), Nil)
 		}
+		return eosValue, nil
+	}
+	return instance.NewString([]rune(v)), nil
+}
+
+// StreamReadyP returns t if a character or byte can be read from input-stream
+// without waiting for input; otherwise, returns nil.
+func StreamReadyP(e env.Environment, inputStream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if ok, _ := InputStreamP(e, inputStream); ok == Nil {
+		return SignalCondition(e, instance.NewDomainError(e, inputStream, class.Stream), Nil)
+	}
+	s := streamOf(e, inputStream).(instance.Stream)
+	if *s.Closed {
+		return SignalCondition(e, instance.NewStreamError(e, inputStream), Nil)
+	}
+	if s.Bytes != nil {
+		if s.Bytes.Buffered() > 0 {
+			return T, nil
+		}
+		position, err := FilePosition(e, s)
+		if err != nil {
+			return nil, err
+		}
+		length, err := fileLength(e, s.File.Name(), true)
+		if err != nil {
+			return nil, err
+		}
+		if int(position.(instance.Integer)) < int(length.(instance.Integer)) {
+			return T, nil
+		}
+		return Nil, nil
+	}
+	if s.Reader.Ready() {
+		return T, nil
+	}
+	return Nil, nil
+}
+
+// ReadByte reads an 8-bit integer from input-stream, whose element class must
+// be 8. If the end of the stream is reached, an error is signaled if
+// eos-error-p is true (error-id. end-of-stream); otherwise eos-value is
+// returned.
+func ReadByte(e env.Environment, inputStream ilos.Instance, options ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if ok, _ := InputStreamP(e, inputStream); ok == Nil {
+		return SignalCondition(e, instance.NewDomainError(e, inputStream, class.Stream), Nil)
+	}
+	if err := ensureStream(e, inputStream, true); err != nil {
+		return nil, err
 	}
-	eosValue := Nil
 	if len(options) > 2 {
-		if options[2] == Nil {
-			eosValue = options[2]
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("READ-BYTE"), 1, 3, 1+len(options)), Nil)
+	}
+	b, err := inputStream.(instance.Stream).Bytes.ReadByte()
+	if err != nil {
+		if len(options) == 0 || options[0] != Nil {
+			return SignalCondition(e, instance.NewEndOfStream(e, inputStream), Nil)
+		}
+		if len(options) == 2 {
+			return options[1], nil
 		}
+		return Nil, nil
 	}
-	v, _, err := bufio.NewReader(s.(instance.Stream).Reader).ReadLine()
+	return instance.NewInteger(int(b)), nil
+}
+
+// WriteByte writes the 8-bit integer z to output-stream, whose element class
+// must be 8, and returns z. An error shall be signaled if z is not an integer
+// between 0 and 255 (error-id. domain-error).
+func WriteByte(e env.Environment, z, outputStream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if ok, _ := OutputStreamP(e, outputStream); ok == Nil {
+		return SignalCondition(e, instance.NewDomainError(e, outputStream, class.Stream), Nil)
+	}
+	if err := ensureStream(e, outputStream, true); err != nil {
+		return nil, err
+	}
+	if err := ensure(e, class.Integer, z); err != nil {
+		return nil, err
+	}
+	if b := int(z.(instance.Integer)); b < 0 || 255 < b {
+		return SignalCondition(e, instance.NewDomainError(e, z, class.Integer), Nil)
+	}
+	if _, err := outputStream.(instance.Stream).Writer.Write([]byte{byte(z.(instance.Integer))}); err != nil {
+		return SignalCondition(e, instance.NewStreamError(e, outputStream), Nil)
+	}
+	return z, nil
+}
+
+// fileStream returns the file underlying stream. An error shall be signaled if
+// stream is not a file stream (error-id. domain-error).
+func fileStream(e env.Environment, stream ilos.Instance) (*os.File, ilos.Instance) {
+	if s, ok := stream.(instance.Stream); ok && s.File != nil {
+		return s.File, nil
+	}
+	_, err := SignalCondition(e, instance.NewDomainError(e, stream, class.Stream), Nil)
+	return nil, err
+}
+
+// FileLength returns the length of the file named filename in units of
+// element-class, which is either the class <character> or 8. A file stream may
+// be given instead of filename, in which case its element class is used.
+func FileLength(e env.Environment, filename ilos.Instance, elementClass ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if s, ok := filename.(instance.Stream); ok {
+		file, err := fileStream(e, s)
+		if err != nil {
+			return nil, err
+		}
+		return fileLength(e, file.Name(), s.Binary)
+	}
+	if ok, _ := Stringp(e, filename); ok == Nil {
+		return SignalCondition(e, instance.NewDomainError(e, filename, class.String), Nil)
+	}
+	binary, err := binaryElementClass(e, "FILE-LENGTH", elementClass)
 	if err != nil {
-		if eosErrorP {
-			return nil, instance.Create(e, class.EndOfStream)
+		return nil, err
+	}
+	return fileLength(e, string(filename.(instance.String)), binary)
+}
+
+func fileLength(e env.Environment, name string, binary bool) (ilos.Instance, ilos.Instance) {
+	if binary {
+		info, err := os.Stat(name)
+		if err != nil {
+			return signalFileError(e, name, err)
 		}
-		return eosValue, nil
+		return instance.NewInteger(int(info.Size())), nil
+	}
+	content, err := ioutil.ReadFile(name)
+	if err != nil {
+		return signalFileError(e, name, err)
 	}
-	return instance.NewString([]rune(string(v))), nil
+	return instance.NewInteger(len([]rune(string(content)))), nil
 }
 
-// TODO: preview-char (Hint: Bufio.Rreader)
+// FilePosition returns the current position in the file stream, counted in
+// bytes from the beginning of the file.
+func FilePosition(e env.Environment, stream ilos.Instance) (ilos.Instance, ilos.Instance) {
+	file, err := fileStream(e, stream)
+	if err != nil {
+		return nil, err
+	}
+	position, fail := file.Seek(0, io.SeekCurrent)
+	if fail != nil {
+		return SignalCondition(e, instance.NewStreamError(e, stream), Nil)
+	}
+	s := stream.(instance.Stream)
+	position += int64(s.Buffered())
+	switch {
+	case s.Bytes != nil:
+		position -= int64(s.Bytes.Buffered())
+	case s.Reader != nil:
+		position -= int64(s.Reader.Buffered())
+	}
+	return instance.NewInteger(int(position)), nil
+}
 
-func StreamReadyP(e env.Environment, inputStream ilos.Instance) (ilos.Instance, ilos.Instance) {
-	// TODO: stream-ready-p
-	return T, nil
+// SetFilePosition sets the position in the file stream to z, counted in bytes
+// from the beginning of the file, and returns z. An error shall be signaled if
+// z is not a non-negative integer (error-id. domain-error).
+func SetFilePosition(e env.Environment, stream, z ilos.Instance) (ilos.Instance, ilos.Instance) {
+	file, err := fileStream(e, stream)
+	if err != nil {
+		return nil, err
+	}
+	if err := ensure(e, class.Integer, z); err != nil {
+		return nil, err
+	}
+	if int(z.(instance.Integer)) < 0 {
+		return SignalCondition(e, instance.NewDomainError(e, z, class.Integer), Nil)
+	}
+	s := stream.(instance.Stream)
+	if err := s.Flush(); err != nil {
+		return SignalCondition(e, instance.NewStreamError(e, stream), Nil)
+	}
+	if _, err := file.Seek(int64(z.(instance.Integer)), io.SeekStart); err != nil {
+		return SignalCondition(e, instance.NewStreamError(e, stream), Nil)
+	}
+	if s.Bytes != nil {
+		s.Bytes.Reset(file)
+	}
+	if s.Reader != nil {
+		*s.Reader = *tokenizer.NewReader(file)
+	}
+	return z, nil
 }
@@ -0,0 +1,255 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+)
+
+func TestBinaryStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "binary")
+	execTests(t, ReadByte, []test{
+		{
+			exp:     fmt.Sprintf(`(let ((s (open-output-file %q 8))) (write-byte 1 s) (write-byte 255 s) (let ((p (file-position s))) (close s) p))`, path),
+			want:    `2`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(let ((s (open-input-file %q 8))) (list (read-byte s) (read-byte s) (read-byte s nil 'eof)))`, path),
+			want:    `'(1 255 eof)`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(let ((s (open-input-file %q 8))) (read-byte s) (read-byte s) (read-byte s))`, path),
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     fmt.Sprintf(`(file-length %q 8)`, path),
+			want:    `2`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(file-length (open-input-file %q 8))`, path),
+			want:    `2`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(let ((s (open-input-file %q 8))) (read-byte s) (file-position s))`, path),
+			want:    `1`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(let ((s (open-input-file %q 8))) (set-file-position s 1) (read-byte s))`, path),
+			want:    `255`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(write-byte 256 (open-output-file %q 8))`, path),
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     fmt.Sprintf(`(read-char (open-input-file %q 8))`, path),
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     fmt.Sprintf(`(format (open-output-file %q 8) "x")`, path),
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     fmt.Sprintf(`(read-byte (open-input-file %q))`, path),
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     fmt.Sprintf(`(open-input-file %q 16)`, path),
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(read-byte (create-string-input-stream "a"))`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestFilePosition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "text")
+	execTests(t, FilePosition, []test{
+		{
+			exp:     fmt.Sprintf(`(let ((s (open-output-file %q))) (format s "h~Cllo" (convert 233 <character>)) (let ((p (file-position s))) (close s) p))`, path),
+			want:    `6`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(list (file-length %q (class <character>)) (file-length %q 8))`, path, path),
+			want:    `'(5 6)`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(let ((s (open-input-file %q))) (read-char s) (file-position s))`, path),
+			want:    `1`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(let ((s (open-input-file %q))) (set-file-position s 3) (read-char s))`, path),
+			want:    `#\l`,
+			wantErr: false,
+		},
+		{
+			exp:     `(file-position (create-string-input-stream "a"))`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestFileStreamLifecycle(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "text")
+	execTests(t, Close, []test{
+		{
+			exp:     fmt.Sprintf(`(let ((s (open-output-file %q))) (format s "abc") (close s) (read-line (open-input-file %q)))`, path, path),
+			want:    `"abc"`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(let ((s (open-output-file %q :if-exists :append))) (format s "de") (finish-output s) (read-line (open-input-file %q)))`, path, path),
+			want:    `"abcde"`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(let ((s (open-io-file %q))) (format s "x") (close s) (read-line (open-input-file %q)))`, path, path),
+			want:    `"xbcde"`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(open-output-file %q :if-exists :error)`, path),
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     fmt.Sprintf(`(open-output-file %q :if-does-not-exist :error)`, path+"-missing"),
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     fmt.Sprintf(`(open-output-file %q :if-exists :keep)`, path),
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     fmt.Sprintf(`(let ((s (open-input-file %q))) (list (open-stream-p s) (progn (close s) (close s) (open-stream-p s))))`, path),
+			want:    `'(t nil)`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(let ((s (open-input-file %q))) (close s) (read-char s))`, path),
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     fmt.Sprintf(`(with-open-output-file (s %q) (format s "fg") (format s "h"))`, path),
+			want:    `nil`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(with-open-input-file (s %q) (read-line s))`, path),
+			want:    `"fgh"`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(let ((x nil)) (list (with-open-input-file (s %q) (setq x s) 1) (open-stream-p x)))`, path),
+			want:    `'(1 nil)`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(let ((x nil)) (block b (with-open-input-file (s %q) (setq x s) (return-from b nil))) (open-stream-p x))`, path),
+			want:    `nil`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(let ((x nil)) (catch 'c (with-open-io-file (s %q 8) (setq x s) (throw 'c nil))) (open-stream-p x))`, path),
+			want:    `nil`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(let ((x nil)) (catch 'c (with-handler (lambda (c) (throw 'c nil)) (with-open-input-file (s %q) (setq x s) (car 1)))) (open-stream-p x))`, path),
+			want:    `nil`,
+			wantErr: false,
+		},
+	})
+}
+
+func TestPreviewChar(t *testing.T) {
+	execTests(t, PreviewChar, []test{
+		{
+			exp:     `(let ((s (create-string-input-stream "ab"))) (list (preview-char s) (read-char s) (preview-char s) (read-char s) (preview-char s nil 'eof)))`,
+			want:    `'(#\a #\a #\b #\b eof)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(preview-char (create-string-input-stream ""))`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(handler-case (read (create-string-input-stream ")")) (<parse-error> (c) t))`,
+			want:    `t`,
+			wantErr: false,
+		},
+		{
+			exp:     `(read (create-string-input-stream ")"))`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(handler-case (read (create-string-input-stream "(car") nil 'eof) (<parse-error> (c) t))`,
+			want:    `t`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((s (create-string-input-stream "(a b) rest"))) (list (read s) (read-line s) (read-line s nil nil)))`,
+			want:    `'((a b) " rest" nil)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let* ((o (create-string-output-stream)) (s (progn (format o "one~%two~%(x)") (create-string-input-stream (get-output-stream-string o))))) (list (read-line s) (read-char s) (read-line s) (read s)))`,
+			want:    `'("one" #\t "wo" (x))`,
+			wantErr: false,
+		},
+	})
+}
+
+func TestStreamReadyP(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "binary")
+	execTests(t, StreamReadyP, []test{
+		{
+			exp:     `(let ((s (create-string-input-stream "a"))) (list (stream-ready-p s) (progn (read-char s) (stream-ready-p s))))`,
+			want:    `'(t nil)`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(with-open-output-file (s %q 8) (write-byte 1 s))`, path),
+			want:    `1`,
+			wantErr: false,
+		},
+		{
+			exp:     fmt.Sprintf(`(with-open-input-file (s %q 8) (list (stream-ready-p s) (progn (read-byte s) (stream-ready-p s))))`, path),
+			want:    `'(t nil)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(stream-ready-p (create-string-output-stream))`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
@@ -33,7 +33,7 @@ func CreateString(e env.Environment, i ilos.Instance, initialElement ...ilos.Ins
 		return SignalCondition(e, instance.NewDomainError(e, i, class.Object), Nil)
 	}
 	if len(initialElement) > 1 {
-		return SignalCondition(e, instance.NewArityError(e), Nil)
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("CREATE-STRING"), 1, 2, 1+len(initialElement)), Nil)
 	}
 	n := int(i.(instance.Integer))
 	v := make([]rune, n)
@@ -130,7 +130,7 @@ func CharIndex(e env.Environment, char, str ilos.Instance, startPosition ...ilos
 		return nil, err
 	}
 	if len(startPosition) > 1 {
-		return SignalCondition(e, instance.NewArityError(e), Nil)
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("CHAR-INDEX"), 2, 3, 2+len(startPosition)), Nil)
 	}
 	n := 0
 	if len(startPosition) == 1 {
@@ -138,6 +138,9 @@ func CharIndex(e env.Environment, char, str ilos.Instance, startPosition ...ilos
 			return nil, err
 		}
 		n = int(startPosition[0].(instance.Integer))
+		if n < 0 || len(str.(instance.String)) < n {
+			return SignalCondition(e, instance.NewIndexOutOfRange(e, str, startPosition[0]), Nil)
+		}
 	}
 	s := string(str.(instance.String)[n:])
 	c := rune(char.(instance.Character))
@@ -164,7 +167,7 @@ func StringIndex(e env.Environment, sub, str ilos.Instance, startPosition ...ilo
 		return nil, err
 	}
 	if len(startPosition) > 1 {
-		return SignalCondition(e, instance.NewArityError(e), Nil)
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("STRING-INDEX"), 2, 3, 2+len(startPosition)), Nil)
 	}
 	n := 0
 	if len(startPosition) == 1 {
@@ -172,6 +175,9 @@ func StringIndex(e env.Environment, sub, str ilos.Instance, startPosition ...ilo
 			return nil, err
 		}
 		n = int(startPosition[0].(instance.Integer))
+		if n < 0 || len(str.(instance.String)) < n {
+			return SignalCondition(e, instance.NewIndexOutOfRange(e, str, startPosition[0]), Nil)
+		}
 	}
 	s := string(str.(instance.String)[n:])
 	c := string(sub.(instance.String))
@@ -28,17 +28,20 @@ func Symbolp(e env.Environment, obj ilos.Instance) (ilos.Instance, ilos.Instance
 // symbol or property-name is not a symbol (error-id. domain-error). obj may be
 // any ISLISP object
 func Property(e env.Environment, symbol, propertyName ilos.Instance, obj ...ilos.Instance) (ilos.Instance, ilos.Instance) {
-	if err := ensure(e, class.Symbol, symbol); err != nil {
+	if err := ensure(e, class.Symbol, symbol, propertyName); err != nil {
 		return nil, err
 	}
 	if len(obj) > 1 {
-		return SignalCondition(e, instance.NewArityError(e), Nil)
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("PROPERTY"), 2, 3, 2+len(obj)), Nil)
 	}
 	ret, ok := e.Property.Get(symbol, propertyName)
 	if ok {
 		return ret, nil
 	}
-	return obj[1], nil
+	if len(obj) == 1 {
+		return obj[0], nil
+	}
+	return Nil, nil
 }
 
 // SetProperty causes obj to be the new value of the property named
@@ -48,7 +51,7 @@ func Property(e env.Environment, symbol, propertyName ilos.Instance, obj ...ilos
 // signaled if either symbol or property-name is not a symbol (error-id.
 // domain-error). obj may be any ISLISP object
 func SetProperty(e env.Environment, obj, symbol, propertyName ilos.Instance) (ilos.Instance, ilos.Instance) {
-	if err := ensure(e, class.Symbol, symbol); err != nil {
+	if err := ensure(e, class.Symbol, symbol, propertyName); err != nil {
 		return nil, err
 	}
 	e.Property.Set(symbol, propertyName, obj)
@@ -61,7 +64,7 @@ func SetProperty(e env.Environment, obj, symbol, propertyName ilos.Instance) (il
 // signaled if either symbol or property-name is not a symbol (error-id.
 // domain-error).
 func RemoveProperty(e env.Environment, symbol, propertyName ilos.Instance) (ilos.Instance, ilos.Instance) {
-	if err := ensure(e, class.Symbol, symbol); err != nil {
+	if err := ensure(e, class.Symbol, symbol, propertyName); err != nil {
 		return nil, err
 	}
 	if v, ok := e.Property.Delete(symbol, propertyName); ok {
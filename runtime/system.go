@@ -0,0 +1,86 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"os"
+
+	"github.com/islisp-dev/iris/runtime/env"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// SetCommandLineArguments binds *command-line-arguments* to the list of
+// strings args, the arguments given to the program after the script.
+func SetCommandLineArguments(args []string) {
+	arguments := []ilos.Instance{}
+	for _, arg := range args {
+		arguments = append(arguments, instance.NewString([]rune(arg)))
+	}
+	list, _ := List(TopLevel, arguments...)
+	TopLevel.Variable.Define(instance.NewSymbol("*COMMAND-LINE-ARGUMENTS*"), list)
+}
+
+// Getenv returns the value of the environment variable named name, or nil if
+// it is not set.
+func Getenv(e env.Environment, name ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.String, name); err != nil {
+		return nil, err
+	}
+	value, ok := os.LookupEnv(string(name.(instance.String)))
+	if !ok {
+		return Nil, nil
+	}
+	return instance.NewString([]rune(value)), nil
+}
+
+// Setenv sets the environment variable named name to value and returns value.
+// If value is nil, the variable is removed from the environment.
+func Setenv(e env.Environment, name, value ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.String, name); err != nil {
+		return nil, err
+	}
+	if value == Nil {
+		if fail := os.Unsetenv(string(name.(instance.String))); fail != nil {
+			return SignalCondition(e, instance.NewDomainError(e, name, class.String), Nil)
+		}
+		return Nil, nil
+	}
+	if err := ensure(e, class.String, value); err != nil {
+		return nil, err
+	}
+	if fail := os.Setenv(string(name.(instance.String)), string(value.(instance.String))); fail != nil {
+		return SignalCondition(e, instance.NewDomainError(e, name, class.String), Nil)
+	}
+	return value, nil
+}
+
+// Exit terminates the program with status, which defaults to 0. The cleanup
+// forms of the active unwind-protect forms are evaluated before the program
+// terminates.
+func Exit(e env.Environment, status ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if len(status) > 1 {
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("EXIT"), 0, 1, len(status)), Nil)
+	}
+	code := instance.NewInteger(0)
+	if len(status) == 1 {
+		if err := ensure(e, class.Integer, status[0]); err != nil {
+			return nil, err
+		}
+		code = status[0]
+	}
+	return nil, instance.NewExit(code)
+}
+
+// ExitStatus returns the status given to exit if err is the escape made by
+// exit.
+func ExitStatus(err ilos.Instance) (int, bool) {
+	if err == nil || !ilos.InstanceOf(class.Exit, err) {
+		return 0, false
+	}
+	status, _ := err.(instance.Instance).GetSlotValue(instance.NewSymbol("IRIS.OBJECT"), class.Exit)
+	return int(status.(instance.Integer)), true
+}
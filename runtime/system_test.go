@@ -0,0 +1,86 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"testing"
+)
+
+func TestGetenv(t *testing.T) {
+	t.Setenv("IRIS_TEST", "value")
+	execTests(t, Getenv, []test{
+		{
+			exp:     `(getenv "IRIS_TEST")`,
+			want:    `"value"`,
+			wantErr: false,
+		},
+		{
+			exp:     `(list (setenv "IRIS_TEST" "other") (getenv "IRIS_TEST"))`,
+			want:    `'("other" "other")`,
+			wantErr: false,
+		},
+		{
+			exp:     `(list (setenv "IRIS_TEST" nil) (getenv "IRIS_TEST"))`,
+			want:    `'(nil nil)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(getenv 'iris-test)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestExit(t *testing.T) {
+	execTests(t, Exit, []test{
+		{
+			exp:     `*command-line-arguments*`,
+			want:    `nil`,
+			wantErr: false,
+		},
+		{
+			exp:     `(exit 1.0)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(catch 'c (exit))`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(progn (defglobal cleaned nil) (catch 'c (unwind-protect (exit) (setq cleaned t))) cleaned)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `cleaned`,
+			want:    `t`,
+			wantErr: false,
+		},
+	})
+	for _, tt := range []struct {
+		exp  string
+		want int
+	}{
+		{`(exit)`, 0},
+		{`(exit 3)`, 3},
+		{`(block b (unwind-protect (exit 4) (return-from b 5)))`, -1},
+	} {
+		obj, _ := readFromString(tt.exp)
+		_, err := Eval(TopLevel, obj)
+		status, ok := ExitStatus(err)
+		if tt.want < 0 {
+			if ok {
+				t.Errorf("%v exited with %v, want a control-error", tt.exp, status)
+			}
+			continue
+		}
+		if !ok || status != tt.want {
+			t.Errorf("%v exited with %v, %v, want %v", tt.exp, status, ok, tt.want)
+		}
+	}
+}
@@ -0,0 +1,34 @@
+;;; 4 Forms and evaluation
+
+(section "4.7" "Functions")
+
+(functionp (function car)) => t
+(functionp 'car) => nil
+(functionp (lambda (x) x)) => t
+((lambda (x y) (+ x y)) 1 2) => 3
+((lambda (x &rest xs) xs) 1 2 3) => '(2 3)
+((lambda (x :rest xs) xs) 1) => nil
+(funcall (function +) 1 2 3) => 6
+(apply (function +) 1 2 '(3 4)) => 10
+(apply #'list '()) => nil
+(labels ((evenp* (n) (if (= n 0) t (oddp* (- n 1))))
+         (oddp* (n) (if (= n 0) nil (evenp* (- n 1)))))
+  (evenp* 10)) => t
+(flet ((f (x) (+ x 1))) (flet ((f (x) (f (* x 2)))) (f 3))) => 7
+(funcall (lambda (x) x)) signals <arity-error>
+(funcall 1 2) signals <domain-error>
+(no-such-function 1) signals <undefined-function>
+
+(section "4.8" "Defining operators")
+
+(defconstant conformance-constant 10) => 'conformance-constant
+conformance-constant => 10
+(defglobal conformance-global 20) => 'conformance-global
+conformance-global => 20
+(defdynamic *conformance-dynamic* 30) => '*conformance-dynamic*
+(dynamic *conformance-dynamic*) => 30
+(defun conformance-square (x) (* x x)) => 'conformance-square
+(conformance-square 4) => 16
+(let ((conformance-global 1)) conformance-global) => 1
+conformance-global => 20
+(setq conformance-constant 1) signals <error>
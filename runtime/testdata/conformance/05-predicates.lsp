@@ -0,0 +1,51 @@
+;;; 5 Predicates
+
+(section "5.1" "Boolean values")
+
+(not nil) => t
+(not t) => nil
+(not '()) => t
+(eq nil '()) => t
+
+(section "5.2" "Class predicates")
+
+(symbolp 'a) => t
+(symbolp "a") => nil
+(symbolp nil) => t
+(consp '(a)) => t
+(consp '()) => nil
+(listp '()) => t
+(listp 'a) => nil
+(null '()) => t
+(null 'a) => nil
+(numberp 1) => t
+(numberp 1.5) => t
+(numberp 'a) => nil
+(integerp 3) => t
+(floatp 3) => nil
+(characterp #\a) => t
+(stringp "a") => t
+(stringp #\a) => nil
+
+(section "5.3" "Equality")
+
+(eq 'a 'a) => t
+(eq 'a 'b) => nil
+(eql 2 2) => t
+(eql 2 2.0) => nil
+(eql #\a #\a) => t
+(equal "abc" "abc") => t
+(equal "abc" "ABC") => nil
+(equal '(1 (2 #\c "d")) '(1 (2 #\c "d"))) => t
+(equal #(1 2) #(1 2)) => t
+(equal 1 1.0) => nil
+(let ((x '(a))) (eq x x)) => t
+
+(section "5.4" "Logical connectives")
+
+(and) => t
+(and 1 2) => 2
+(and nil (car 1)) => nil
+(or) => nil
+(or nil 2) => 2
+(or 1 (car 1)) => 1
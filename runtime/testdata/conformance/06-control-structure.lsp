@@ -0,0 +1,64 @@
+;;; 6 Control structure
+
+(section "6.1" "Constants")
+
+'a => 'a
+(quote (a b)) => '(a b)
+1 => 1
+"abc" => "abc"
+#\a => #\a
+#(1 2) => (vector 1 2)
+
+(section "6.2" "Variables")
+
+(let ((x 1) (y 2)) (+ x y)) => 3
+(let ((x 1)) (let ((x 2) (y x)) y)) => 1
+(let* ((x 1) (y (+ x 1))) y) => 2
+(let ((x 1)) (setq x 2) x) => 2
+(let ((x (list 1 2))) (setf (car x) 3) x) => '(3 2)
+(setq conformance-unbound 1) signals <undefined-variable>
+conformance-unbound signals <undefined-variable>
+
+(section "6.3" "Dynamic variables")
+
+(defdynamic *conformance-level* 0) => '*conformance-level*
+(dynamic-let ((*conformance-level* 1)) (dynamic *conformance-level*)) => 1
+(dynamic *conformance-level*) => 0
+(progn (set-dynamic 5 *conformance-level*) (dynamic *conformance-level*)) => 5
+(dynamic *conformance-undefined*) signals <undefined-variable>
+
+(section "6.4" "Conditional expressions")
+
+(if t 1 2) => 1
+(if nil 1 2) => 2
+(if nil 1) => nil
+(cond ((= 1 2) 'a) ((= 1 1) 'b) (t 'c)) => 'b
+(cond (nil 1)) => nil
+(cond (3)) => 3
+(case 3 ((1 2) 'low) ((3 4) 'high) (t 'other)) => 'high
+(case 'z ((a) 1) (t 2)) => 2
+(case 'z ((a) 1)) => nil
+(case-using #'string= "b" (("a") 1) (("b") 2)) => 2
+
+(section "6.5" "Sequencing forms")
+
+(progn) => nil
+(progn 1 2 3) => 3
+
+(section "6.6" "Iteration")
+
+(let ((i 0) (s 0)) (while (< i 5) (setq s (+ s i)) (setq i (+ i 1))) s) => 10
+(for ((i 0 (+ i 1)) (s 0 (+ s i))) ((= i 5) s)) => 10
+(for ((i 0 (+ i 1))) ((= i 3))) => nil
+(for ((xs '(1 2 3) (cdr xs)) (ys '() (cons (car xs) ys))) ((null xs) ys)) => '(3 2 1)
+
+(section "6.7" "Non-local exits")
+
+(block b (return-from b 1) 2) => 1
+(block b (block c (return-from b 1)) 2) => 1
+(catch 'tag (throw 'tag 1) 2) => 1
+(catch 'tag (catch 'other (throw 'tag 1)) 2) => 1
+(throw 'conformance-no-catcher 1) signals <control-error>
+(let ((x 0)) (tagbody (setq x 1) (go end) (setq x 2) end) x) => 1
+(let ((x 0)) (catch 'tag (unwind-protect (throw 'tag 1) (setq x 2))) x) => 2
+(let ((f (block b (lambda () (return-from b 1))))) (funcall f)) signals <control-error>
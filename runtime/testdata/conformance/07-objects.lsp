@@ -0,0 +1,46 @@
+;;; 7 Objects
+
+(section "7.1" "Defining classes")
+
+(defclass <conformance-point> () ((x :initarg x :initform 0 :accessor point-x) (y :initarg y :reader point-y))) => '<conformance-point>
+(point-x (create (class <conformance-point>))) => 0
+(point-y (create (class <conformance-point>) 'y 2)) => 2
+(let ((p (create (class <conformance-point>) 'x 1))) (setf (point-x p) 3) (point-x p)) => 3
+(defclass <conformance-point3> (<conformance-point>) ((z :initarg z :initform 0 :reader point-z))) => '<conformance-point3>
+(point-x (create (class <conformance-point3>) 'x 5)) => 5
+(subclassp (class <conformance-point3>) (class <conformance-point>)) => t
+(subclassp (class <conformance-point>) (class <conformance-point3>)) => nil
+
+(section "7.2" "Generic functions")
+
+(defgeneric conformance-describe (x)) => 'conformance-describe
+(defmethod conformance-describe ((x <integer>)) 'integer) => 'conformance-describe
+(defmethod conformance-describe ((x <number>)) 'number) => 'conformance-describe
+(defmethod conformance-describe ((x <object>)) 'object) => 'conformance-describe
+(conformance-describe 1) => 'integer
+(conformance-describe 1.5) => 'number
+(conformance-describe "a") => 'object
+(generic-function-p #'conformance-describe) => t
+(generic-function-p #'car) => nil
+(defgeneric conformance-qualified (x)) => 'conformance-qualified
+(defmethod conformance-qualified ((x <integer>)) (list 'primary)) => 'conformance-qualified
+(defmethod conformance-qualified :around ((x <integer>)) (cons 'around (call-next-method))) => 'conformance-qualified
+(conformance-qualified 1) => '(around primary)
+(conformance-describe) signals <arity-error>
+
+(section "7.3" "Object creation and initialization")
+
+(defgeneric conformance-init-count ()) => 'conformance-init-count
+(defclass <conformance-counted> () ((n :accessor counted-n))) => '<conformance-counted>
+(defmethod initialize-object :after ((x <conformance-counted>) initargs) (setf (counted-n x) 42)) => 'initialize-object
+(counted-n (create (class <conformance-counted>))) => 42
+
+(section "7.4" "Class enquiry")
+
+(eq (class-of 1) (class <integer>)) => t
+(eq (class-of "a") (class <string>)) => t
+(eq (class-of (create (class <conformance-point>))) (class <conformance-point>)) => t
+(instancep 1 (class <number>)) => t
+(instancep 1 (class <float>)) => nil
+(instancep (create (class <conformance-point3>)) (class <conformance-point>)) => t
+(class <conformance-undefined>) signals <undefined-entity>
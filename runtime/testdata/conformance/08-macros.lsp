@@ -0,0 +1,13 @@
+;;; 8 Macros
+
+(section "8.1" "Macros")
+
+(defmacro conformance-swap (a b) `(let ((tmp ,a)) (setq ,a ,b) (setq ,b tmp))) => 'conformance-swap
+(let ((x 1) (y 2)) (conformance-swap x y) (list x y)) => '(2 1)
+(defmacro conformance-unless (test :rest body) `(if ,test nil (progn ,@body))) => 'conformance-unless
+(conformance-unless nil 1 2) => 2
+(conformance-unless t 1 2) => nil
+`(1 ,(+ 1 1) ,@(list 3 4)) => '(1 2 3 4)
+`(a . ,(+ 1 2)) => '(a . 3)
+`#(1 ,(+ 1 1)) => #(1 2)
+(let ((x 'a)) `(x ,x)) => '(x a)
@@ -0,0 +1,24 @@
+;;; 9 Declarations and coercions
+
+(section "9.1" "Declarations")
+
+(the <integer> 1) => 1
+(assure <integer> 1) => 1
+(assure <integer> "a") signals <domain-error>
+
+(section "9.2" "Coercion functions")
+
+(convert 1 <float>) => 1.0
+(convert 1 <integer>) => 1
+(convert #\a <integer>) => 97
+(convert 97 <character>) => #\a
+(convert "abc" <list>) => '(#\a #\b #\c)
+(convert '(#\a #\b) <string>) => "ab"
+(convert #(1 2) <list>) => '(1 2)
+(convert '(1 2) <general-vector>) => #(1 2)
+(convert 'abc <string>) => "ABC"
+(convert "12" <integer>) => 12
+(convert "1.5" <float>) => 1.5
+(convert 12 <string>) => "12"
+(convert "abc" <symbol>) => '|abc|
+(convert 1 <symbol>) signals <domain-error>
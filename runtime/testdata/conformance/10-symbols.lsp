@@ -0,0 +1,16 @@
+;;; 10 Symbol class
+
+(section "10.1" "Symbols")
+
+(symbolp 'abc) => t
+(eq 'abc 'ABC) => t
+(eq '|abc| 'abc) => nil
+(symbolp (gensym)) => t
+(eq (gensym) (gensym)) => nil
+
+(section "10.2" "Symbol properties")
+
+(progn (setf (property 'conformance-symbol 'color) 'red) (property 'conformance-symbol 'color)) => 'red
+(property 'conformance-symbol 'size) => nil
+(property 'conformance-symbol 'size 10) => 10
+(progn (remove-property 'conformance-symbol 'color) (property 'conformance-symbol 'color)) => nil
@@ -0,0 +1,59 @@
+;;; 11 Number class
+
+(section "11.1" "Number class")
+
+(parse-number "123") => 123
+(parse-number "-1.5") => (- 1.5)
+(parse-number "abc") signals <parse-error>
+(= 3 3.0) => t
+(/= 3 4) => t
+(< 1 2 3) => t
+(< 1 3 2) => nil
+(>= 3 3 2) => t
+(+) => 0
+(+ 1 2 3) => 6
+(*) => 1
+(* 2 3.0) => 6.0
+(- 5) => -5
+(- 10 1 2) => 7
+(reciprocal 2.0) => 0.5
+(quotient 10 4) => 2.5
+(quotient 10 5) => 2
+(quotient 1 0) signals <division-by-zero>
+(max 1 3 2) => 3
+(min 1 3 2) => 1
+(abs -3) => 3
+(exp 0) => 1.0
+(log 1) => 0.0
+(expt 2 10) => 1024
+(expt 2.0 3) => 8.0
+(sqrt 16) => 4
+(sqrt 2.25) => 1.5
+(sin 0) => 0.0
+(atan2 0 1) => 0.0
+(+ 1 'a) signals <domain-error>
+
+(section "11.2" "Float class")
+
+(floatp 1.0) => t
+(float 2) => 2.0
+(floor 2.5) => 2
+(floor (- 2.5)) => -3
+(ceiling 2.5) => 3
+(truncate (- 2.5)) => -2
+(round 2.5) => 2
+(round 3.5) => 4
+(< *most-negative-float* 0 *most-positive-float*) => t
+
+(section "11.3" "Integer class")
+
+(integerp 1.0) => nil
+(div 7 2) => 3
+(div -7 2) => -4
+(mod 7 2) => 1
+(mod -7 2) => 1
+(div 1 0) signals <division-by-zero>
+(gcd 12 18) => 6
+(lcm 4 6) => 12
+(isqrt 17) => 4
+(* 4294967296 4294967296) => 18446744073709551616
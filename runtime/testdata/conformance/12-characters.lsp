@@ -0,0 +1,15 @@
+;;; 12 Character class
+
+(section "12.1" "Character class")
+
+(characterp #\a) => t
+(characterp "a") => nil
+(char= #\a #\a) => t
+(char= #\a #\A) => nil
+(char/= #\a #\b) => t
+(char< #\a #\b) => t
+(char<= #\a #\a) => t
+(char> #\b #\a) => t
+(char>= #\a #\b) => nil
+(eql #\space #\Space) => t
+(char= #\a "a") signals <domain-error>
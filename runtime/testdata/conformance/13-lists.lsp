@@ -0,0 +1,35 @@
+;;; 13 List class
+
+(section "13.1" "Cons")
+
+(cons 1 2) => '(1 . 2)
+(car '(1 2)) => 1
+(cdr '(1 2)) => '(2)
+(car '()) signals <domain-error>
+(let ((x (cons 1 2))) (set-car 3 x) x) => '(3 . 2)
+(let ((x (cons 1 2))) (set-cdr 3 x) x) => '(1 . 3)
+
+(section "13.2" "Null class")
+
+(null nil) => t
+(eq (class-of nil) (class <null>)) => t
+
+(section "13.3" "List operations")
+
+(create-list 3 'a) => '(a a a)
+(list 1 2 3) => '(1 2 3)
+(list) => nil
+(reverse '(1 2 3)) => '(3 2 1)
+(nreverse (list 1 2 3)) => '(3 2 1)
+(append '(1) '(2 3) '() '(4)) => '(1 2 3 4)
+(append) => nil
+(member 2 '(1 2 3)) => '(2 3)
+(member 4 '(1 2 3)) => nil
+(mapcar #'+ '(1 2 3) '(10 20)) => '(11 22)
+(mapc #'list '(1 2)) => '(1 2)
+(mapcan (lambda (x) (list x x)) '(1 2)) => '(1 1 2 2)
+(maplist #'length '(1 2 3)) => '(3 2 1)
+(mapl #'length '(1 2)) => '(1 2)
+(mapcon #'list '(1 2)) => '((1 2) (2))
+(assoc 'b '((a . 1) (b . 2))) => '(b . 2)
+(assoc 'c '((a . 1))) => nil
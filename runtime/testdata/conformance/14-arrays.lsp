@@ -0,0 +1,20 @@
+;;; 14 Arrays
+
+(section "14.1" "Array classes")
+
+(basic-array-p #(1 2)) => t
+(basic-array-p "ab") => t
+(basic-array-p '(1)) => nil
+(basic-array*-p (create-array '(2 2) 0)) => t
+(general-array*-p (create-array '(2 2) 0)) => t
+
+(section "14.2" "General arrays")
+
+(array-dimensions (create-array '(2 3) 0)) => '(2 3)
+(aref (create-array '(2 2) 'x) 1 1) => 'x
+(let ((a (create-array '(2 2) 0))) (set-aref 5 a 0 1) (aref a 0 1)) => 5
+(let ((a (create-array '(2 2) 0))) (setf (garef a 1 0) 7) (garef a 1 0)) => 7
+(aref #(1 2 3) 2) => 3
+(aref "abc" 1) => #\b
+(aref #(1 2) 2) signals <index-out-of-range>
+(aref (create-array '(2 2) 0) 0) signals <program-error>
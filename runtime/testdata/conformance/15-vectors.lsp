@@ -0,0 +1,11 @@
+;;; 15 Vectors
+
+(section "15.1" "Vectors")
+
+(basic-vector-p #(1)) => t
+(basic-vector-p "a") => t
+(general-vector-p #(1)) => t
+(general-vector-p "a") => nil
+(create-vector 2 'a) => #(a a)
+(vector) => #()
+(vector 1 2) => #(1 2)
@@ -0,0 +1,22 @@
+;;; 16 String class
+
+(section "16.1" "String class")
+
+(stringp "") => t
+(create-string 3 #\a) => "aaa"
+(string= "abc" "abc") => t
+(string= "abc" "abd") => nil
+(string/= "abc" "abd") => t
+(string< "abc" "abd") => t
+(string<= "abc" "abc") => t
+(string> "b" "abc") => t
+(string>= "a" "b") => nil
+(char-index #\b "abc") => 1
+(char-index #\z "abc") => nil
+(char-index #\c "abcabc" 3) => 5
+(string-index "bc" "abcbc") => 1
+(string-index "bc" "abcbc" 2) => 3
+(string-index "x" "abc") => nil
+(string-append "ab" "" "cd") => "abcd"
+(string-append) => ""
+(string= "a" 'a) signals <domain-error>
@@ -0,0 +1,19 @@
+;;; 17 Sequence functions
+
+(section "17.1" "Sequence functions")
+
+(length '(1 2 3)) => 3
+(length "abcd") => 4
+(length #()) => 0
+(length 1) signals <domain-error>
+(elt '(a b c) 1) => 'b
+(elt "abc" 2) => #\c
+(elt #(1 2) 2) signals <index-out-of-range>
+(let ((x (list 1 2))) (set-elt 3 x 0) x) => '(3 2)
+(let ((x (create-string 2 #\a))) (setf (elt x 1) #\b) x) => "ab"
+(subseq "abcdef" 1 3) => "bc"
+(subseq '(1 2 3 4) 2 4) => '(3 4)
+(subseq #(1 2 3) 1 1) => #()
+(subseq "abc" 2 5) signals <index-out-of-range>
+(map-into (create-list 3 0) #'+ '(1 2 3) '(10 20 30)) => '(11 22 33)
+(map-into (create-string 2 #\a) (lambda (c) c) "xy") => "xy"
@@ -0,0 +1,16 @@
+;;; 18 Stream class
+
+(section "18.1" "Streams to files")
+
+(streamp (standard-output)) => t
+(streamp 1) => nil
+(open-stream-p (standard-input)) => t
+(input-stream-p (standard-input)) => t
+(output-stream-p (standard-output)) => t
+(input-stream-p (standard-output)) => nil
+
+(section "18.2" "Other streams")
+
+(read (create-string-input-stream "(a b)")) => '(a b)
+(let ((s (create-string-output-stream))) (format s "~A~A" 1 2) (get-output-stream-string s)) => "12"
+(let ((s (create-string-output-stream))) (format s "a") (get-output-stream-string s) (get-output-stream-string s)) => ""
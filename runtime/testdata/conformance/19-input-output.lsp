@@ -0,0 +1,31 @@
+;;; 19 Input and output
+
+(section "19.1" "Argument conventions for input functions")
+
+(defun conformance-format (string :rest args) (let ((s (create-string-output-stream))) (apply #'format s string args) (get-output-stream-string s))) => 'conformance-format
+(read (create-string-input-stream "") nil 'eof) => 'eof
+(read (create-string-input-stream "")) signals <end-of-stream>
+(read-char (create-string-input-stream "") nil 'eof) => 'eof
+
+(section "19.2" "Character I/O")
+
+(read-char (create-string-input-stream "ab")) => #\a
+(let ((s (create-string-input-stream "ab"))) (preview-char s) (read-char s)) => #\a
+(read-line (create-string-input-stream "ab")) => "ab"
+(let ((s (create-string-input-stream (conformance-format "a~%b")))) (read-line s) (read-line s)) => "b"
+(read (create-string-input-stream "#\\a")) => #\a
+(read (create-string-input-stream "(1 . 2)")) => '(1 . 2)
+(conformance-format "~A ~S" "a" "a") => "a \"a\""
+(conformance-format "~B ~O ~D ~X" 5 8 10 9) => "101 10 10 9"
+(conformance-format "~3R" 5) => "12"
+(conformance-format "~~") => "~"
+(conformance-format "a~%b") => (string-append "a" (create-string 1 (convert 10 <character>)) "b")
+(conformance-format "~C" #\a) => "a"
+(conformance-format "~G" 1.5) => "1.5"
+(let ((s (create-string-output-stream))) (format-char s #\a) (format-integer s 10 2) (format-object s "x" nil) (get-output-stream-string s)) => "a1010x"
+(conformance-format "~A" '(1 "a" #\b)) => "(1 a b)"
+(conformance-format "~S" '(1 "a" #\b)) => "(1 \"a\" #\\b)"
+
+(section "19.3" "Binary I/O")
+
+(let ((s (create-string-input-stream "a"))) (read-byte s)) signals <error>
@@ -0,0 +1,9 @@
+;;; 20 Files
+
+(section "20.1" "Files")
+
+(probe-file "testdata/conformance/no-such-file") => nil
+(open-input-file "testdata/conformance/no-such-file") signals <file-error>
+(with-open-input-file (s "testdata/conformance/20-files.lsp") (read-line s)) => ";;; 20 Files"
+(with-open-input-file (s "testdata/conformance/20-files.lsp") (read s)) => '(section "20.1" "Files")
+(< 0 (file-length "testdata/conformance/20-files.lsp" 8)) => t
@@ -0,0 +1,39 @@
+;;; 21 Condition system
+
+(section "21.1" "Conditions")
+
+(subclassp (class <domain-error>) (class <error>)) => t
+(subclassp (class <error>) (class <serious-condition>)) => t
+(subclassp (class <division-by-zero>) (class <arithmetic-error>)) => t
+(subclassp (class <undefined-function>) (class <undefined-entity>)) => t
+(subclassp (class <end-of-stream>) (class <stream-error>)) => t
+
+(section "21.2" "Signaling and handling conditions")
+
+(error "bad ~A" 1) signals <simple-error>
+(cerror "go on" "bad") signals <simple-error>
+(ignore-errors (car 1)) => nil
+(ignore-errors 1) => 1
+(catch 'c (with-handler (lambda (c) (throw 'c 'handled)) (error "x"))) => 'handled
+(with-handler (lambda (c) (continue-condition c 2)) (+ 1 (cerror "continue" "x"))) => 3
+(with-handler (lambda (c) (continue-condition c 2)) (error "x")) signals <control-error>
+(catch 'c (with-handler (lambda (c) (throw 'c (condition-continuable c))) (error "x"))) => nil
+(catch 'c (with-handler (lambda (c) (throw 'c (class-of c))) (signal-condition (create (class <simple-error>) 'format-string "x" 'format-arguments '()) nil))) => (class <simple-error>)
+(handler-case (car 1) (<domain-error> () 'domain)) => 'domain
+(handler-case (car 1) (<arithmetic-error> () 'arith) (<error> () 'error)) => 'error
+
+(section "21.3" "Data associated with condition classes")
+
+(handler-case (error "bad ~A" 1) (<simple-error> (c) (simple-error-format-string c))) => "bad ~A"
+(handler-case (error "bad ~A" 1) (<simple-error> (c) (simple-error-format-arguments c))) => '(1)
+(handler-case (car 1) (<domain-error> (c) (domain-error-object c))) => 1
+(handler-case (car 1) (<domain-error> (c) (eq (domain-error-expected-class c) (class <cons>)))) => t
+(handler-case (div 1 0) (<arithmetic-error> (c) (arithmetic-error-operands c))) => '(1 0)
+(handler-case (parse-number "x") (<parse-error> (c) (parse-error-string c))) => "x"
+(handler-case (conformance-undefined-function) (<undefined-entity> (c) (list (undefined-entity-name c) (undefined-entity-namespace c)))) => '(conformance-undefined-function function)
+(handler-case conformance-undefined-variable (<undefined-entity> (c) (undefined-entity-namespace c))) => 'variable
+(let ((s (create-string-input-stream ""))) (handler-case (read s) (<stream-error> (c) (eq (stream-error-stream c) s)))) => t
+
+(section "21.4" "Error identification")
+
+(let ((s (create-string-output-stream))) (report-condition (create (class <simple-error>) 'format-string "bad ~A" 'format-arguments '(1)) s) (get-output-stream-string s)) => "bad 1"
@@ -0,0 +1,9 @@
+;;; 22 Miscellaneous
+
+(section "22.1" "Miscellaneous")
+
+(identity 1) => 1
+(integerp (get-universal-time)) => t
+(integerp (get-internal-real-time)) => t
+(integerp (get-internal-run-time)) => t
+(< 0 (internal-time-units-per-second)) => t
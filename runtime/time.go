@@ -0,0 +1,82 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"fmt"
+	"runtime"
+	"time"
+
+	"github.com/islisp-dev/iris/runtime/env"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// systemClock is the clock of the top level, which reads the time of the
+// system and the processor time of the process.
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
+
+func (systemClock) RunTime() time.Duration {
+	return processTime()
+}
+
+// internalTimeUnitsPerSecond is the number of internal time units in a second,
+// that is, internal times are measured in microseconds.
+const internalTimeUnitsPerSecond = int(time.Second / time.Microsecond)
+
+// universalTimeOffset is the number of seconds from 1900-01-01T00:00:00Z, the
+// origin of universal time, to the Unix epoch.
+const universalTimeOffset = 2208988800
+
+// GetUniversalTime returns the current time as a non-negative integer, the
+// number of seconds since 1900-01-01T00:00:00Z.
+func GetUniversalTime(e env.Environment) (ilos.Instance, ilos.Instance) {
+	return instance.NewInteger(int(e.Clock.Now().Unix()) + universalTimeOffset), nil
+}
+
+// GetInternalRealTime returns the current real time in internal time units.
+// The origin of the internal real time is implementation defined, so only the
+// difference of two values is meaningful.
+func GetInternalRealTime(e env.Environment) (ilos.Instance, ilos.Instance) {
+	return instance.NewInteger(int(e.Clock.Now().UnixNano() / int64(time.Microsecond))), nil
+}
+
+// GetInternalRunTime returns the processor time used by the process in
+// internal time units.
+func GetInternalRunTime(e env.Environment) (ilos.Instance, ilos.Instance) {
+	return instance.NewInteger(int(e.Clock.RunTime() / time.Microsecond)), nil
+}
+
+// InternalTimeUnitsPerSecond returns the number of internal time units in a
+// second.
+func InternalTimeUnitsPerSecond(e env.Environment) (ilos.Instance, ilos.Instance) {
+	return instance.NewInteger(internalTimeUnitsPerSecond), nil
+}
+
+// Time evaluates form and returns its value. The elapsed real time, the
+// processor time and the memory allocated during the evaluation are reported
+// to the error output stream.
+func Time(e env.Environment, form ilos.Instance) (ilos.Instance, ilos.Instance) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start, startRun := e.Clock.Now(), e.Clock.RunTime()
+	ret, err := Eval(e, form)
+	if err != nil {
+		return nil, err
+	}
+	real, run := e.Clock.Now().Sub(start), e.Clock.RunTime()-startRun
+	runtime.ReadMemStats(&after)
+	report := fmt.Sprintf("Elapsed real time: %.6f seconds~%%Run time: %.6f seconds~%%Allocated: %v bytes in %v objects~%%",
+		real.Seconds(), run.Seconds(),
+		after.TotalAlloc-before.TotalAlloc, after.Mallocs-before.Mallocs)
+	if _, err := Format(e, e.ErrorOutput, instance.NewString([]rune(report))); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
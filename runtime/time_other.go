@@ -0,0 +1,18 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package runtime
+
+import "time"
+
+var processStart = time.Now()
+
+// processTime approximates the processor time used by the process by the real
+// time since it started, where the processor time is not available.
+func processTime() time.Duration {
+	return time.Since(processStart)
+}
@@ -0,0 +1,106 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"testing"
+	"time"
+
+	"github.com/islisp-dev/iris/runtime/env"
+)
+
+// tickClock advances by one second of real time and a quarter second of run
+// time every time it is read.
+type tickClock struct {
+	now time.Time
+	run time.Duration
+}
+
+func (c *tickClock) Now() time.Time {
+	c.now = c.now.Add(time.Second)
+	return c.now
+}
+
+func (c *tickClock) RunTime() time.Duration {
+	c.run += time.Second / 4
+	return c.run
+}
+
+func withClock(clock env.Clock, f func()) {
+	defer func(before env.Clock) { TopLevel.Clock = before }(TopLevel.Clock)
+	TopLevel.Clock = clock
+	f()
+}
+
+func TestGetUniversalTime(t *testing.T) {
+	withClock(&tickClock{now: time.Date(1999, 12, 31, 23, 59, 59, 0, time.UTC)}, func() {
+		execTests(t, GetUniversalTime, []test{
+			{
+				exp:     `(get-universal-time)`,
+				want:    `3155673600`,
+				wantErr: false,
+			},
+		})
+	})
+}
+
+func TestGetInternalRealTime(t *testing.T) {
+	withClock(&tickClock{}, func() {
+		execTests(t, GetInternalRealTime, []test{
+			{
+				exp:     `(let ((a (get-internal-real-time))) (- (get-internal-real-time) a))`,
+				want:    `(internal-time-units-per-second)`,
+				wantErr: false,
+			},
+		})
+	})
+}
+
+func TestGetInternalRunTime(t *testing.T) {
+	withClock(&tickClock{}, func() {
+		execTests(t, GetInternalRunTime, []test{
+			{
+				exp:     `(get-internal-run-time)`,
+				want:    `250000`,
+				wantErr: false,
+			},
+		})
+	})
+}
+
+func TestTime(t *testing.T) {
+	withClock(&tickClock{}, func() {
+		execTests(t, Time, []test{
+			{
+				exp:     `(with-error-output (create-string-output-stream) (time (+ 1 2)))`,
+				want:    `3`,
+				wantErr: false,
+			},
+			{
+				exp: `
+				(let ((s (create-string-output-stream)))
+					(with-error-output s (time 1))
+					(string-index "Run time: 0.250000 seconds" (get-output-stream-string s)))
+				`,
+				want:    `36`,
+				wantErr: false,
+			},
+			{
+				exp: `
+				(let ((s (create-string-output-stream)))
+					(with-error-output s (time 1))
+					(string-index "Elapsed real time: 1.000000 seconds" (get-output-stream-string s)))
+				`,
+				want:    `0`,
+				wantErr: false,
+			},
+			{
+				exp:     `(time (car 1))`,
+				want:    `nil`,
+				wantErr: true,
+			},
+		})
+	})
+}
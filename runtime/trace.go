@@ -0,0 +1,237 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/islisp-dev/iris/runtime/env"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// traceDepthVariable is the dynamic variable bound to the number of the traced
+// calls in progress which are shown, so that each evaluation counts its own.
+var traceDepthVariable = instance.NewSymbol("IRIS/TRACE-DEPTH")
+
+func traceDepth(e env.Environment) int {
+	if depth, ok := e.DynamicVariable.Get(traceDepthVariable); ok {
+		return int(depth.(instance.Integer))
+	}
+	return 0
+}
+
+// tracedFunction replaces the global function named name while it is traced.
+// The methods of a traced generic function are traced too; methods maps the
+// names of their traced functions to the original functions.
+type tracedFunction struct {
+	name      ilos.Instance
+	function  ilos.Instance
+	condition ilos.Instance
+	maxDepth  int
+	methods   map[ilos.Instance]ilos.Instance
+}
+
+func (f *tracedFunction) Class() ilos.Class {
+	return f.function.Class()
+}
+
+func (f *tracedFunction) String() string {
+	return f.function.String()
+}
+
+func (f *tracedFunction) Apply(e env.Environment, arguments ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	return f.call(e, f.name, f.function, arguments)
+}
+
+// call applies function to arguments, printing the call and its result to
+// *trace-output* unless the condition of f is not satisfied or the calls are
+// nested too deeply.
+func (f *tracedFunction) call(e env.Environment, name, function ilos.Instance, arguments []ilos.Instance) (ilos.Instance, ilos.Instance) {
+	n := traceDepth(e)
+	if f.maxDepth >= 0 && n >= f.maxDepth {
+		return function.(instance.Applicable).Apply(e, arguments...)
+	}
+	if f.condition != Nil {
+		ok, err := f.condition.(instance.Applicable).Apply(e.NewDynamic(), arguments...)
+		if err != nil {
+			return nil, err
+		}
+		if ok == Nil {
+			return function.(instance.Applicable).Apply(e, arguments...)
+		}
+	}
+	output, _ := e.DynamicVariable.Get(instance.NewSymbol("*TRACE-OUTPUT*"))
+	indent := instance.NewString([]rune(strings.Repeat("  ", n)))
+	depth := instance.NewInteger(n)
+	call, _ := List(e, arguments...)
+	if _, err := Format(e, output, instance.NewString([]rune("~A~A: ~S~%")), indent, depth, instance.NewCons(name, call)); err != nil {
+		return nil, err
+	}
+	inner := e.NewLexical()
+	inner.DynamicVariable.Define(traceDepthVariable, instance.NewInteger(n+1))
+	ret, err := function.(instance.Applicable).Apply(inner, arguments...)
+	switch {
+	case err == nil:
+		_, err = Format(e, output, instance.NewString([]rune("~A~A: ~S returned ~S~%")), indent, depth, name, ret)
+	case ilos.InstanceOf(class.SeriousCondition, err):
+		Format(e, output, instance.NewString([]rune("~A~A: ~S signaled ~S~%")), indent, depth, name, err)
+	default:
+		Format(e, output, instance.NewString([]rune("~A~A: ~S exited non-locally~%")), indent, depth, name)
+	}
+	return ret, err
+}
+
+// traceMethods replaces the functions of the methods of generic with the
+// traced functions, which are named after the name of generic, the qualifier
+// and the classes of the parameters.
+func (f *tracedFunction) traceMethods(generic *instance.GenericFunction) {
+	generic.MapMethods(func(qualifier ilos.Instance, classList []ilos.Class, function ilos.Instance) ilos.Instance {
+		label := []ilos.Instance{instance.NewSymbol("METHOD"), f.name}
+		if qualifier != nil {
+			label = append(label, qualifier)
+		}
+		for _, c := range classList {
+			label = append(label, c)
+		}
+		name, _ := List(TopLevel, label...)
+		f.methods[name] = function
+		return instance.NewFunction(name, func(e env.Environment, arguments ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+			return f.call(e, name, function, arguments)
+		})
+	})
+}
+
+// untraceMethods restores the functions of the methods of generic which are
+// still traced.
+func (f *tracedFunction) untraceMethods(generic *instance.GenericFunction) {
+	generic.MapMethods(func(qualifier ilos.Instance, classList []ilos.Class, function ilos.Instance) ilos.Instance {
+		if name, ok := function.(instance.Function).Name().(*instance.Cons); ok && f.methods[name] != nil {
+			return f.methods[name]
+		}
+		return function
+	})
+}
+
+// untraced returns the function which function traces, or function itself if
+// it is not traced.
+func untraced(function ilos.Instance) ilos.Instance {
+	if f, ok := function.(*tracedFunction); ok {
+		return f.function
+	}
+	return function
+}
+
+// tracedNames returns the sorted list of the names of the traced functions.
+func tracedNames(e env.Environment) (ilos.Instance, ilos.Instance) {
+	names := []ilos.Instance{}
+	for name, function := range TopLevel.Function[0] {
+		if _, ok := function.(*tracedFunction); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Slice(names, func(i, j int) bool { return names[i].String() < names[j].String() })
+	return List(e, names...)
+}
+
+// Trace traces the global functions specified by specs and returns the list
+// of their names. Each spec is the name of a function or a list of the form
+// (name option value ...), where the options are:
+//
+// :condition is a function which is applied to the arguments of each call.
+// The call is shown only if it returns true.
+//
+// :max-depth is the number of the nested traced calls beyond which the calls
+// are not shown.
+//
+// Each call is shown on *trace-output* with its arguments, indented by the
+// number of the traced calls in progress, and so is the value returned or the
+// condition signaled. The methods of a generic function are traced with it.
+// Without specs, the list of the names of the traced functions is returned.
+func Trace(e env.Environment, specs ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if len(specs) == 0 {
+		return tracedNames(e)
+	}
+	names := []ilos.Instance{}
+	for _, spec := range specs {
+		name, options := spec, []ilos.Instance{}
+		if ilos.InstanceOf(class.Cons, spec) {
+			if !isProperList(spec) {
+				return SignalCondition(e, instance.NewDomainError(e, spec, class.List), Nil)
+			}
+			name, options = spec.(*instance.Cons).Car, spec.(*instance.Cons).Cdr.(instance.List).Slice()
+		}
+		if err := ensure(e, class.Symbol, name); err != nil {
+			return nil, err
+		}
+		if len(options)%2 != 0 {
+			return SignalCondition(e, instance.NewProgramError(e), Nil)
+		}
+		traced := &tracedFunction{name: name, condition: Nil, maxDepth: -1, methods: map[ilos.Instance]ilos.Instance{}}
+		for i := 0; i < len(options); i += 2 {
+			value, err := Eval(e, options[i+1])
+			if err != nil {
+				return nil, err
+			}
+			switch options[i] {
+			case instance.NewSymbol(":CONDITION"):
+				if err := ensure(e, class.Function, value); err != nil {
+					return nil, err
+				}
+				traced.condition = value
+			case instance.NewSymbol(":MAX-DEPTH"):
+				if err := ensure(e, class.Integer, value); err != nil {
+					return nil, err
+				}
+				traced.maxDepth = int(value.(instance.Integer))
+			default:
+				return SignalCondition(e, instance.NewDomainError(e, options[i], class.Symbol), Nil)
+			}
+		}
+		function, ok := e.Function[:1].Get(name)
+		if !ok {
+			return SignalCondition(e, instance.NewUndefinedFunction(e, name), Nil)
+		}
+		if _, err := Untrace(e, name); err != nil {
+			return nil, err
+		}
+		traced.function = untraced(function)
+		if generic, ok := traced.function.(*instance.GenericFunction); ok {
+			traced.traceMethods(generic)
+		}
+		e.Function[:1].Define(name, traced)
+		names = append(names, name)
+	}
+	return List(e, names...)
+}
+
+// Untrace stops tracing the functions named names and returns the list of
+// them. Without names, all the traced functions are untraced.
+func Untrace(e env.Environment, names ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if len(names) == 0 {
+		traced, err := tracedNames(e)
+		if err != nil {
+			return nil, err
+		}
+		names = traced.(instance.List).Slice()
+	}
+	for _, name := range names {
+		if err := ensure(e, class.Symbol, name); err != nil {
+			return nil, err
+		}
+		function, _ := e.Function[:1].Get(name)
+		traced, ok := function.(*tracedFunction)
+		if !ok {
+			continue
+		}
+		if generic, ok := traced.function.(*instance.GenericFunction); ok {
+			traced.untraceMethods(generic)
+		}
+		e.Function[:1].Define(name, traced.function)
+	}
+	return List(e, names...)
+}
@@ -0,0 +1,130 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+func TestTrace(t *testing.T) {
+	defer func() {
+		obj, _ := readFromString(`(untrace)`)
+		Eval(TopLevel, obj)
+		for _, name := range []string{"TRACE-LINES", "TRACE-FACT", "TRACE-AREA"} {
+			delete(TopLevel.Function[0], instance.NewSymbol(name))
+		}
+	}()
+	execTests(t, Trace, []test{
+		{
+			exp:     `(defun trace-lines (:rest lines) (let ((s (create-string-output-stream))) (mapc (lambda (line) (format s "~A~%" line)) lines) (get-output-stream-string s)))`,
+			want:    `'trace-lines`,
+			wantErr: false,
+		},
+		{
+			exp:     `(defun trace-fact (n) (if (= n 0) 1 (* n (trace-fact (- n 1)))))`,
+			want:    `'trace-fact`,
+			wantErr: false,
+		},
+		{
+			exp:     `(list (trace trace-fact) (trace))`,
+			want:    `'((trace-fact) (trace-fact))`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((s (create-string-output-stream))) (dynamic-let ((*trace-output* s)) (list (trace-fact 2) (get-output-stream-string s))))`,
+			want:    `(list 2 (trace-lines "0: (TRACE-FACT 2)" "  1: (TRACE-FACT 1)" "    2: (TRACE-FACT 0)" "    2: TRACE-FACT returned 1" "  1: TRACE-FACT returned 1" "0: TRACE-FACT returned 2"))`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((s (create-string-output-stream))) (dynamic-let ((*trace-output* s)) (trace (trace-fact :max-depth 1)) (trace-fact 2) (get-output-stream-string s)))`,
+			want:    `(trace-lines "0: (TRACE-FACT 2)" "0: TRACE-FACT returned 2")`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((s (create-string-output-stream))) (dynamic-let ((*trace-output* s)) (trace (trace-fact :condition (lambda (n) (= n 1)))) (trace-fact 2) (get-output-stream-string s)))`,
+			want:    `(trace-lines "0: (TRACE-FACT 1)" "0: TRACE-FACT returned 1")`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((s (create-string-output-stream))) (dynamic-let ((*trace-output* s)) (list (untrace trace-fact) (trace) (trace-fact 2) (get-output-stream-string s))))`,
+			want:    `'((trace-fact) nil 2 "")`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((s (create-string-output-stream))) (dynamic-let ((*trace-output* s)) (trace car) (list (ignore-errors (car 1)) (get-output-stream-string s))))`,
+			want:    `(list nil (trace-lines "0: (CAR 1)" "0: CAR exited non-locally"))`,
+			wantErr: false,
+		},
+		{
+			exp:     `(progn (defgeneric trace-area (s)) (defmethod trace-area ((s <integer>)) (* s s)) (trace trace-area) (defmethod trace-area ((s <float>)) s) (untrace trace-area))`,
+			want:    `'(trace-area)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((s (create-string-output-stream))) (dynamic-let ((*trace-output* s)) (trace trace-area) (list (trace-area 2) (get-output-stream-string s))))`,
+			want:    `(list 4 (trace-lines "0: (TRACE-AREA 2)" "  1: ((METHOD TRACE-AREA <INTEGER>) 2)" "  1: (METHOD TRACE-AREA <INTEGER>) returned 4" "0: TRACE-AREA returned 4"))`,
+			wantErr: false,
+		},
+		{
+			exp:     `(let ((s (create-string-output-stream))) (dynamic-let ((*trace-output* s)) (untrace) (list (trace-area 2) (get-output-stream-string s))))`,
+			want:    `'(4 "")`,
+			wantErr: false,
+		},
+		{
+			exp:     `(trace trace-undefined)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(trace (trace-fact :depth 1))`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestTraceConcurrent(t *testing.T) {
+	defer func() {
+		obj, _ := readFromString(`(untrace)`)
+		Eval(TopLevel, obj)
+		delete(TopLevel.Function[0], instance.NewSymbol("TRACE-COUNT"))
+	}()
+	for _, exp := range []string{
+		`(defun trace-count (n) (if (= n 0) 0 (+ 1 (trace-count (- n 1)))))`,
+		`(trace trace-count)`,
+	} {
+		obj, _ := readFromString(exp)
+		if _, err := Eval(TopLevel, obj); err != nil {
+			t.Fatalf("Eval(%v) err = %v", exp, err)
+		}
+	}
+	// Each evaluation counts the depth of its own traced calls.
+	exp := `(let ((s (create-string-output-stream))) (dynamic-let ((*trace-output* s)) (trace-count 20) (get-output-stream-string s)))`
+	obj, _ := readFromString(exp)
+	outputs := make([]ilos.Instance, 4)
+	var wg sync.WaitGroup
+	for i := range outputs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			outputs[i], _ = Eval(TopLevel.NewDynamic(), obj)
+		}(i)
+	}
+	wg.Wait()
+	want, err := Eval(TopLevel.NewDynamic(), obj)
+	if err != nil {
+		t.Fatalf("Eval(%v) err = %v", exp, err)
+	}
+	for i, output := range outputs {
+		if !reflect.DeepEqual(output, want) {
+			t.Errorf("output %v = %v, want %v", i, output, want)
+		}
+	}
+}
@@ -0,0 +1,355 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/islisp-dev/iris/runtime/env"
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/class"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+// testSuite is a named group of tests. Its setup forms are evaluated before
+// each of its tests and of the tests of the suites in it, and its teardown
+// forms after them.
+type testSuite struct {
+	name     ilos.Instance
+	parent   *testSuite
+	setup    []ilos.Instance
+	teardown []ilos.Instance
+}
+
+// testCase is a test defined by deftest in e.
+type testCase struct {
+	name  ilos.Instance
+	suite *testSuite
+	e     env.Environment
+	forms []ilos.Instance
+}
+
+var (
+	testSuites = map[ilos.Instance]*testSuite{}
+	// testCases are in the order of their definitions.
+	testCases []*testCase
+	// currentSuite is the suite of the tests defined next, or nil.
+	currentSuite *testSuite
+)
+
+// suites returns the suites of t, the outermost first.
+func (t *testCase) suites() []*testSuite {
+	suites := []*testSuite{}
+	for s := t.suite; s != nil; s = s.parent {
+		suites = append([]*testSuite{s}, suites...)
+	}
+	return suites
+}
+
+// fullName returns the name of t preceded by the names of its suites,
+// separated by slashes.
+func (t *testCase) fullName() string {
+	names := []string{}
+	for _, s := range t.suites() {
+		names = append(names, s.name.String())
+	}
+	return strings.Join(append(names, t.name.String()), "/")
+}
+
+// in reports whether t is in suite or in the suites in it.
+func (t *testCase) in(suite *testSuite) bool {
+	for s := t.suite; s != nil; s = s.parent {
+		if s == suite {
+			return true
+		}
+	}
+	return false
+}
+
+// Defsuite defines the test suite named name and returns name. The options
+// are lists of the following forms:
+//
+// (:parent suite-name) puts the suite in the suite named suite-name.
+//
+// (:setup form*) gives the forms evaluated before each test in the suite.
+//
+// (:teardown form*) gives the forms evaluated after each test in the suite,
+// even if the test fails.
+func Defsuite(e env.Environment, name ilos.Instance, options ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.Symbol, name); err != nil {
+		return nil, err
+	}
+	suite := &testSuite{name: name}
+	for _, option := range options {
+		if !ilos.InstanceOf(class.Cons, option) || !isProperList(option) {
+			return SignalCondition(e, instance.NewDomainError(e, option, class.Cons), Nil)
+		}
+		xs := option.(instance.List).Slice()
+		switch xs[0] {
+		case instance.NewSymbol(":PARENT"):
+			if len(xs) != 2 {
+				return SignalCondition(e, instance.NewProgramError(e), Nil)
+			}
+			parent, ok := testSuites[xs[1]]
+			if !ok {
+				return SignalCondition(e, instance.NewUndefinedEntity(e, xs[1], instance.NewSymbol("TEST-SUITE")), Nil)
+			}
+			suite.parent = parent
+		case instance.NewSymbol(":SETUP"):
+			suite.setup = xs[1:]
+		case instance.NewSymbol(":TEARDOWN"):
+			suite.teardown = xs[1:]
+		default:
+			return SignalCondition(e, instance.NewDomainError(e, xs[0], class.Symbol), Nil)
+		}
+	}
+	// The tests already defined in the suite stay in it.
+	if old, ok := testSuites[name]; ok {
+		*old = *suite
+		return name, nil
+	}
+	testSuites[name] = suite
+	return name, nil
+}
+
+// InSuite makes the tests defined next belong to the suite named name, or to
+// no suite if name is nil, and returns name.
+func InSuite(e env.Environment, name ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if name == Nil {
+		currentSuite = nil
+		return name, nil
+	}
+	suite, ok := testSuites[name]
+	if !ok {
+		return SignalCondition(e, instance.NewUndefinedEntity(e, name, instance.NewSymbol("TEST-SUITE")), Nil)
+	}
+	currentSuite = suite
+	return name, nil
+}
+
+// Deftest defines the test named name in the current suite, which evaluates
+// forms, and returns name. A test of the same name in the suite is replaced.
+// The test fails if an assertion fails or another condition is signaled.
+func Deftest(e env.Environment, name ilos.Instance, forms ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if err := ensure(e, class.Symbol, name); err != nil {
+		return nil, err
+	}
+	test := &testCase{name: name, suite: currentSuite, e: e, forms: forms}
+	for i, t := range testCases {
+		if t.name == name && t.suite == currentSuite {
+			testCases[i] = test
+			return name, nil
+		}
+	}
+	testCases = append(testCases, test)
+	return name, nil
+}
+
+func assertionFailed(e env.Environment, formatString string, objs ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	arguments, err := List(e, objs...)
+	if err != nil {
+		return nil, err
+	}
+	return SignalCondition(e, instance.NewAssertionFailure(e, instance.NewString([]rune(formatString)), arguments), Nil)
+}
+
+// AssertTrue evaluates form and signals an assertion failure if its value is
+// nil. Otherwise, t is returned.
+func AssertTrue(e env.Environment, form ilos.Instance) (ilos.Instance, ilos.Instance) {
+	ret, err := Eval(e, form)
+	if err != nil {
+		return nil, err
+	}
+	if ret == Nil {
+		return assertionFailed(e, "~S is false", form)
+	}
+	return T, nil
+}
+
+// AssertFalse evaluates form and signals an assertion failure unless its value
+// is nil. Otherwise, t is returned.
+func AssertFalse(e env.Environment, form ilos.Instance) (ilos.Instance, ilos.Instance) {
+	ret, err := Eval(e, form)
+	if err != nil {
+		return nil, err
+	}
+	if ret != Nil {
+		return assertionFailed(e, "~S is ~S, expected false", form, ret)
+	}
+	return T, nil
+}
+
+// AssertEqual evaluates expected and form, and signals an assertion failure
+// unless their values are equal. Otherwise, t is returned.
+func AssertEqual(e env.Environment, expected, form ilos.Instance) (ilos.Instance, ilos.Instance) {
+	want, err := Eval(e, expected)
+	if err != nil {
+		return nil, err
+	}
+	got, err := Eval(e, form)
+	if err != nil {
+		return nil, err
+	}
+	if ok, _ := Equal(e, want, got); ok == Nil {
+		return assertionFailed(e, "~S is ~S, expected ~S", form, got, want)
+	}
+	return T, nil
+}
+
+// AssertError evaluates form and returns the condition of the class named
+// className signaled by it. An assertion failure is signaled if form returns
+// normally. The other conditions are passed to the outer handler.
+func AssertError(e env.Environment, className, form ilos.Instance) (ilos.Instance, ilos.Instance) {
+	c, err := Class(e, className)
+	if err != nil {
+		return nil, err
+	}
+	ret, index, condition, err := handle(e, []ilos.Class{c}, form)
+	if err != nil {
+		return nil, err
+	}
+	if index < 0 {
+		return assertionFailed(e, "~S returned ~S, expected a condition of class ~A", form, ret, className)
+	}
+	return condition, nil
+}
+
+// TestResult is the result of a test.
+type TestResult struct {
+	// Name is the name of the test preceded by the names of its suites,
+	// separated by slashes.
+	Name string
+	// Status is "pass", "fail" if an assertion failed, or "error" if
+	// another condition was signaled or the test exited non-locally.
+	Status string
+	// Message describes why the test did not pass.
+	Message string
+	// Output is written by the test to the standard and error output.
+	Output  string
+	Elapsed time.Duration
+}
+
+// conditionMessage returns the report of condition.
+func conditionMessage(e env.Environment, condition ilos.Instance) string {
+	stream, _ := CreateStringOutputStream(e)
+	if _, err := ReportCondition(e, condition, stream); err != nil {
+		return condition.String()
+	}
+	message, _ := GetOutputStreamString(e, stream)
+	return string(message.(instance.String))
+}
+
+// runTest evaluates the forms of t between the setup and teardown forms of its
+// suites, with the output captured, and times them by clock.
+func runTest(t *testCase, clock env.Clock) TestResult {
+	var output strings.Builder
+	e := t.e.NewLexical()
+	e.StandardOutput = instance.NewStream(nil, &output)
+	e.ErrorOutput = e.StandardOutput
+	e.Handler = instance.NewFunction(instance.NewSymbol("TEST-HANDLER"), TopLevelHander)
+	start := clock.Now()
+	suites := t.suites()
+	var err ilos.Instance
+	n := 0
+	for ; n < len(suites) && err == nil; n++ {
+		_, err = Progn(e, suites[n].setup...)
+	}
+	if err == nil {
+		_, err = Progn(e, t.forms...)
+	}
+	for i := n - 1; i >= 0; i-- {
+		if _, fail := Progn(e, suites[i].teardown...); fail != nil && err == nil {
+			err = fail
+		}
+	}
+	result := TestResult{Name: t.fullName(), Status: "pass", Output: output.String(), Elapsed: clock.Now().Sub(start)}
+	switch {
+	case err == nil:
+	case ilos.InstanceOf(class.AssertionFailure, err):
+		result.Status, result.Message = "fail", conditionMessage(e, err)
+	case ilos.InstanceOf(class.SeriousCondition, err):
+		result.Status, result.Message = "error", conditionMessage(e, err)
+	default:
+		result.Status, result.Message = "error", "The test exited non-locally."
+		if status, ok := ExitStatus(err); ok {
+			result.Message = fmt.Sprintf("The test exited with status %d.", status)
+		}
+	}
+	return result
+}
+
+// RunTests runs the tests whose names match pattern, or all the tests if
+// pattern is nil, in the order of their definitions.
+func RunTests(pattern *regexp.Regexp) []TestResult {
+	results := []TestResult{}
+	for _, t := range testCases {
+		if pattern == nil || pattern.MatchString(t.fullName()) {
+			results = append(results, runTest(t, TopLevel.Clock))
+		}
+	}
+	return results
+}
+
+// WriteTestResult writes r to w as go test does. The passed tests are written
+// only if verbose is true. The message and the output of the test follow its
+// result, indented.
+func WriteTestResult(w io.Writer, r TestResult, verbose bool) error {
+	if r.Status == "pass" && !verbose {
+		return nil
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s: %s (%.2fs)\n", strings.ToUpper(r.Status), r.Name, r.Elapsed.Seconds())
+	for _, text := range []string{r.Message, r.Output} {
+		if text == "" {
+			continue
+		}
+		for _, line := range strings.Split(strings.TrimSuffix(text, "\n"), "\n") {
+			fmt.Fprintf(&b, "    %s\n", line)
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// RunTestsFunction runs the tests in the suite named suite, or all the tests
+// if suite is not given, and writes their results to the standard output. It
+// returns t if all of them pass, and nil otherwise.
+func RunTestsFunction(e env.Environment, suite ...ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if len(suite) > 1 {
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("RUN-TESTS"), 0, 1, len(suite)), Nil)
+	}
+	var s *testSuite
+	if len(suite) == 1 && suite[0] != Nil {
+		if err := ensure(e, class.Symbol, suite[0]); err != nil {
+			return nil, err
+		}
+		var ok bool
+		if s, ok = testSuites[suite[0]]; !ok {
+			return SignalCondition(e, instance.NewUndefinedEntity(e, suite[0], instance.NewSymbol("TEST-SUITE")), Nil)
+		}
+	}
+	counts := map[string]int{}
+	var b strings.Builder
+	for _, t := range testCases {
+		if s != nil && !t.in(s) {
+			continue
+		}
+		r := runTest(t, e.Clock)
+		counts[r.Status]++
+		WriteTestResult(&b, r, true)
+	}
+	fmt.Fprintf(&b, "%d passed, %d failed, %d errors\n", counts["pass"], counts["fail"], counts["error"])
+	if _, err := Format(e, e.StandardOutput, instance.NewString([]rune("~A")), instance.NewString([]rune(b.String()))); err != nil {
+		return nil, err
+	}
+	if counts["fail"]+counts["error"] > 0 {
+		return Nil, nil
+	}
+	return T, nil
+}
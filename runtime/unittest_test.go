@@ -0,0 +1,160 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package runtime
+
+import (
+	"reflect"
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/islisp-dev/iris/runtime/ilos"
+	"github.com/islisp-dev/iris/runtime/ilos/instance"
+)
+
+func resetTests() {
+	testSuites = map[ilos.Instance]*testSuite{}
+	testCases = nil
+	currentSuite = nil
+	delete(TopLevel.Variable[0], instance.NewSymbol("*UNITTEST-X*"))
+}
+
+func TestAssertEqual(t *testing.T) {
+	execTests(t, AssertEqual, []test{
+		{
+			exp:     `(assert-equal '(1 2) (list 1 2))`,
+			want:    `t`,
+			wantErr: false,
+		},
+		{
+			exp:     `(assert-equal 4 (+ 1 2))`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(handler-case (assert-equal 4 (+ 1 2)) (<assertion-failure> (c) (simple-error-format-arguments c)))`,
+			want:    `'((+ 1 2) 3 4)`,
+			wantErr: false,
+		},
+		{
+			exp:     `(assert-true (= 1 1))`,
+			want:    `t`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (assert-true nil) (<assertion-failure> (c) (simple-error-format-string c)))`,
+			want:    `"~S is false"`,
+			wantErr: false,
+		},
+		{
+			exp:     `(assert-false (= 1 2))`,
+			want:    `t`,
+			wantErr: false,
+		},
+		{
+			exp:     `(assert-false 1)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestAssertError(t *testing.T) {
+	execTests(t, AssertError, []test{
+		{
+			exp:     `(instancep (assert-error <arithmetic-error> (div 1 0)) (class <division-by-zero>))`,
+			want:    `t`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (assert-error <error> 1) (<assertion-failure> () 'failed))`,
+			want:    `'failed`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (assert-error <division-by-zero> (car 1)) (<domain-error> () 'passed))`,
+			want:    `'passed`,
+			wantErr: false,
+		},
+		{
+			exp:     `(flet ((f () (car 1))) (instancep (assert-error <error> (f)) (class <domain-error>)))`,
+			want:    `t`,
+			wantErr: false,
+		},
+		{
+			exp:     `(assert-error <no-such-class> 1)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
+
+func TestRunTests(t *testing.T) {
+	defer resetTests()
+	for _, exp := range []string{
+		`(defglobal *unittest-x* 0)`,
+		`(defsuite math (:setup (setq *unittest-x* 10)) (:teardown (setq *unittest-x* 0)))`,
+		`(defsuite inner (:parent math) (:setup (setq *unittest-x* (+ *unittest-x* 1))))`,
+		`(in-suite math)`,
+		`(deftest add (assert-equal 10 *unittest-x*))`,
+		`(deftest bad (format (standard-output) "hello") (assert-equal 4 (+ 1 2)))`,
+		`(in-suite inner)`,
+		`(deftest nested (assert-equal 11 *unittest-x*))`,
+		`(in-suite nil)`,
+		`(deftest teardown (assert-equal 0 *unittest-x*))`,
+		`(deftest boom (car 1))`,
+		`(deftest boom (error "boom"))`,
+	} {
+		obj, _ := readFromString(exp)
+		if _, err := Eval(TopLevel, obj); err != nil {
+			t.Fatalf("Eval(%v) err = %v", exp, err)
+		}
+	}
+	var results []TestResult
+	withClock(&tickClock{}, func() {
+		results = RunTests(nil)
+	})
+	want := []TestResult{
+		{Name: "MATH/ADD", Status: "pass", Elapsed: time.Second},
+		{Name: "MATH/BAD", Status: "fail", Message: "(+ 1 2) is 3, expected 4", Output: "hello", Elapsed: time.Second},
+		{Name: "MATH/INNER/NESTED", Status: "pass", Elapsed: time.Second},
+		{Name: "TEARDOWN", Status: "pass", Elapsed: time.Second},
+		{Name: "BOOM", Status: "error", Message: "boom", Elapsed: time.Second},
+	}
+	if !reflect.DeepEqual(results, want) {
+		t.Errorf("RunTests(nil) = %v, want %v", results, want)
+	}
+	results = RunTests(regexp.MustCompile("INNER"))
+	if len(results) != 1 || results[0].Name != "MATH/INNER/NESTED" {
+		t.Errorf("RunTests(INNER) = %v, want MATH/INNER/NESTED", results)
+	}
+	execTests(t, RunTestsFunction, []test{
+		{
+			exp:     `(let ((s (create-string-output-stream))) (with-standard-output s (run-tests 'inner)) (string-index "1 passed, 0 failed, 0 errors" (get-output-stream-string s)))`,
+			want:    `36`,
+			wantErr: false,
+		},
+		{
+			exp:     `(with-standard-output (create-string-output-stream) (run-tests))`,
+			want:    `nil`,
+			wantErr: false,
+		},
+		{
+			exp:     `(run-tests 'no-such-suite)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(in-suite no-such-suite)`,
+			want:    `nil`,
+			wantErr: true,
+		},
+		{
+			exp:     `(defsuite orphan (:parent no-such-suite))`,
+			want:    `nil`,
+			wantErr: true,
+		},
+	})
+}
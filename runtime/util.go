@@ -46,6 +46,9 @@ func readFromString(s string) (ilos.Instance, ilos.Instance) {
 
 func ensure(e env.Environment, c ilos.Class, i ...ilos.Instance) ilos.Instance {
 	for _, o := range i {
+		if declared(e, c, o) {
+			continue
+		}
 		if !ilos.InstanceOf(c, o) {
 			_, err := SignalCondition(e, instance.NewDomainError(e, o, c), Nil)
 			return err
@@ -54,6 +57,36 @@ func ensure(e env.Environment, c ilos.Class, i ...ilos.Instance) ilos.Instance {
 	return nil
 }
 
+// declared returns true if o is an argument of the current call which was
+// declared to be an instance of c, or of a subclass of c.
+func declared(e env.Environment, c ilos.Class, o ilos.Instance) bool {
+	for _, d := range e.Declarations {
+		if !identical(d.Object, o) {
+			continue
+		}
+		if d.Class.String() == c.String() || ilos.SubclassOf(c, d.Class) {
+			return true
+		}
+	}
+	return false
+}
+
+// identical returns true if a and b are the same object. Unlike eq, it never
+// panics on objects of incomparable types.
+func identical(a, b ilos.Instance) bool {
+	va, vb := reflect.ValueOf(a), reflect.ValueOf(b)
+	if va.Type() != vb.Type() {
+		return false
+	}
+	switch va.Kind() {
+	case reflect.Slice:
+		return va.Pointer() == vb.Pointer() && va.Len() == vb.Len()
+	case reflect.Ptr:
+		return va.Pointer() == vb.Pointer()
+	}
+	return va.Type().Comparable() && a == b
+}
+
 var unique = 0
 
 func uniqueInt() int {
@@ -21,6 +21,9 @@ import (
 // establishing a variable. The setq special form must be contained in the scope
 // of var , established by defglobal, let, let*, for, or a lambda expression.
 func Setq(e env.Environment, var1, form ilos.Instance) (ilos.Instance, ilos.Instance) {
+	if _, ok := e.Constant.Get(var1); ok {
+		return SignalCondition(e, instance.NewImmutableBinding(e, var1), Nil)
+	}
 	ret, err := Eval(e, form)
 	if err != nil {
 		return nil, err
@@ -67,7 +70,7 @@ func Let(e env.Environment, varForm ilos.Instance, bodyForm ...ilos.Instance) (i
 			return nil, err
 		}
 		if cadr.(instance.List).Length() != 2 {
-			return SignalCondition(e, instance.NewArityError(e), Nil)
+			return SignalCondition(e, instance.NewProgramError(e), Nil)
 		}
 		f, err := Eval(e, cadr.(instance.List).Nth(1))
 		if err != nil {
@@ -77,7 +80,7 @@ func Let(e env.Environment, varForm ilos.Instance, bodyForm ...ilos.Instance) (i
 	}
 	for v, f := range vfs {
 		if !e.Variable.Define(v, f) {
-			return SignalCondition(e, instance.NewImmutableBinding(e), Nil)
+			return SignalCondition(e, instance.NewImmutableBinding(e, v), Nil)
 		}
 	}
 	return Progn(e, bodyForm...)
@@ -105,14 +108,14 @@ func LetStar(e env.Environment, varForm ilos.Instance, bodyForm ...ilos.Instance
 			return nil, err
 		}
 		if cadr.(instance.List).Length() != 2 {
-			return SignalCondition(e, instance.NewArityError(e), Nil)
+			return SignalCondition(e, instance.NewProgramError(e), Nil)
 		}
 		f, err := Eval(e, cadr.(instance.List).Nth(1))
 		if err != nil {
 			return nil, err
 		}
 		if !e.Variable.Define(cadr.(instance.List).Nth(0), f) {
-			return SignalCondition(e, instance.NewImmutableBinding(e), Nil)
+			return SignalCondition(e, instance.NewImmutableBinding(e, cadr.(instance.List).Nth(0)), Nil)
 		}
 	}
 	return Progn(e, bodyForm...)
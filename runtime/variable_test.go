@@ -6,6 +6,32 @@ package runtime
 
 import "testing"
 
+func TestSetq(t *testing.T) {
+	tests := []test{
+		{
+			exp:     `(let ((x 1)) (setq x 2) x)`,
+			want:    `2`,
+			wantErr: false,
+		},
+		{
+			exp:     `(defconstant constant-for-setq 1)`,
+			want:    `'constant-for-setq`,
+			wantErr: false,
+		},
+		{
+			exp:     `(handler-case (setq constant-for-setq 2) (<immutable-binding> (c) (immutable-binding-name c)))`,
+			want:    `'constant-for-setq`,
+			wantErr: false,
+		},
+		{
+			exp:     `constant-for-setq`,
+			want:    `1`,
+			wantErr: false,
+		},
+	}
+	execTests(t, Setq, tests)
+}
+
 func TestSetf(t *testing.T) {
 	tests := []test{
 		{
@@ -40,7 +40,7 @@ func CreateVector(e env.Environment, i ilos.Instance, initialElement ...ilos.Ins
 		return SignalCondition(e, instance.NewDomainError(e, i, class.Integer), Nil)
 	}
 	if len(initialElement) > 1 {
-		return SignalCondition(e, instance.NewArityError(e), Nil)
+		return SignalCondition(e, instance.NewArityError(e, instance.NewSymbol("CREATE-VECTOR"), 1, 2, 1+len(initialElement)), Nil)
 	}
 	n := int(i.(instance.Integer))
 	v := make([]ilos.Instance, n)
@@ -0,0 +1,345 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/islisp-dev/iris/runtime"
+)
+
+// The environment variables given to the process which runs a test file.
+const (
+	testResultsVariable = "IRIS_TEST_RESULTS"
+	testRunVariable     = "IRIS_TEST_RUN"
+)
+
+// testFile is the results of the tests in a file.
+type testFile struct {
+	Path    string
+	Results []runtime.TestResult
+	Elapsed time.Duration
+	// Output is written by the file outside the tests.
+	Output string
+}
+
+// failed reports whether a test in f did not pass.
+func (f testFile) failed() bool {
+	for _, r := range f.Results {
+		if r.Status != "pass" {
+			return true
+		}
+	}
+	return false
+}
+
+// findTestFiles returns the files named *_test.lsp in paths and in the
+// directories in them, sorted and without duplicates.
+func findTestFiles(paths []string) ([]string, error) {
+	files := []string{}
+	found := map[string]bool{}
+	for _, path := range paths {
+		err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			path = filepath.Clean(path)
+			if !info.IsDir() && strings.HasSuffix(info.Name(), "_test.lsp") && !found[path] {
+				found[path] = true
+				files = append(files, path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// testChild loads the test file at path, runs the tests in it, and writes
+// their results to the file named by the environment variable in JSON. It is
+// run in the process started by runTestFile.
+func testChild(path string) int {
+	var pattern *regexp.Regexp
+	if run := os.Getenv(testRunVariable); run != "" {
+		pattern = regexp.MustCompile(run)
+	}
+	if status := script(path); status != 0 {
+		return status
+	}
+	data, err := json.Marshal(runtime.RunTests(pattern))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	if err := ioutil.WriteFile(os.Getenv(testResultsVariable), data, 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return 0
+}
+
+// runTestFile runs the tests in the file at path in a new process, so that
+// the definitions of a file do not affect the others. A file which cannot be
+// loaded is reported as a test which is an error.
+func runTestFile(path, run string, timeout time.Duration) testFile {
+	start := time.Now()
+	file := testFile{Path: path}
+	fail := func(message string) testFile {
+		file.Elapsed = time.Since(start)
+		file.Results = append(file.Results, runtime.TestResult{Name: filepath.Base(path), Status: "error", Message: message, Elapsed: file.Elapsed})
+		return file
+	}
+	executable, err := os.Executable()
+	if err != nil {
+		return fail(err.Error())
+	}
+	results, err := ioutil.TempFile("", "iris-test-")
+	if err != nil {
+		return fail(err.Error())
+	}
+	results.Close()
+	defer os.Remove(results.Name())
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	var output bytes.Buffer
+	cmd := exec.CommandContext(ctx, executable, filepath.Base(path))
+	cmd.Dir = filepath.Dir(path)
+	cmd.Env = append(os.Environ(), testResultsVariable+"="+results.Name(), testRunVariable+"="+run)
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+	err = cmd.Run()
+	file.Output = output.String()
+	if ctx.Err() != nil {
+		return fail(fmt.Sprintf("The tests timed out after %v.", timeout))
+	}
+	if err != nil {
+		return fail(fmt.Sprintf("The file could not be loaded: %v", err))
+	}
+	data, err := ioutil.ReadFile(results.Name())
+	if err == nil {
+		err = json.Unmarshal(data, &file.Results)
+	}
+	if err != nil {
+		return fail(err.Error())
+	}
+	file.Elapsed = time.Since(start)
+	return file
+}
+
+// writeText writes the results as go test does.
+func writeText(w io.Writer, files []testFile, verbose bool) error {
+	for _, f := range files {
+		if verbose || f.failed() {
+			io.WriteString(w, f.Output)
+		}
+		for _, r := range f.Results {
+			if err := runtime.WriteTestResult(w, r, verbose); err != nil {
+				return err
+			}
+		}
+		status := "ok  "
+		if f.failed() {
+			status = "FAIL"
+		}
+		note := ""
+		if len(f.Results) == 0 {
+			note = " [no tests to run]"
+		}
+		if _, err := fmt.Fprintf(w, "%s\t%s\t%.3fs%s\n", status, f.Path, f.Elapsed.Seconds(), note); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Error     *junitFailure `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Errors    int             `xml:"errors,attr"`
+	Time      string          `xml:"time,attr"`
+	TestCases []junitTestCase `xml:"testcase"`
+	SystemOut string          `xml:"system-out,omitempty"`
+}
+
+type junitTestSuites struct {
+	XMLName    xml.Name         `xml:"testsuites"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+// writeJUnit writes the results in the JUnit XML format, with a test suite for
+// each file.
+func writeJUnit(w io.Writer, files []testFile) error {
+	suites := junitTestSuites{}
+	for _, f := range files {
+		suite := junitTestSuite{Name: f.Path, Time: fmt.Sprintf("%.3f", f.Elapsed.Seconds()), SystemOut: f.Output}
+		for _, r := range f.Results {
+			c := junitTestCase{Name: r.Name, ClassName: f.Path, Time: fmt.Sprintf("%.3f", r.Elapsed.Seconds()), SystemOut: r.Output}
+			switch r.Status {
+			case "fail":
+				suite.Failures++
+				c.Failure = &junitFailure{Message: r.Message, Text: r.Message}
+			case "error":
+				suite.Errors++
+				c.Error = &junitFailure{Message: r.Message, Text: r.Message}
+			}
+			suite.Tests++
+			suite.TestCases = append(suite.TestCases, c)
+		}
+		suites.TestSuites = append(suites.TestSuites, suite)
+	}
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	e := xml.NewEncoder(w)
+	e.Indent("", "  ")
+	if err := e.Encode(suites); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+// testEvent is an event of go tool test2json.
+type testEvent struct {
+	Time    time.Time
+	Action  string
+	Package string
+	Test    string   `json:",omitempty"`
+	Elapsed *float64 `json:",omitempty"`
+	Output  string   `json:",omitempty"`
+}
+
+// writeJSON writes the results as the events of go tool test2json, with the
+// files as the packages.
+func writeJSON(w io.Writer, files []testFile) error {
+	e := json.NewEncoder(w)
+	now := time.Now()
+	for _, f := range files {
+		events := []testEvent{}
+		if f.Output != "" {
+			events = append(events, testEvent{Action: "output", Output: f.Output})
+		}
+		for _, r := range f.Results {
+			var b strings.Builder
+			runtime.WriteTestResult(&b, r, true)
+			elapsed := r.Elapsed.Seconds()
+			action := "pass"
+			if r.Status != "pass" {
+				action = "fail"
+			}
+			events = append(events,
+				testEvent{Action: "run", Test: r.Name},
+				testEvent{Action: "output", Test: r.Name, Output: b.String()},
+				testEvent{Action: action, Test: r.Name, Elapsed: &elapsed})
+		}
+		elapsed := f.Elapsed.Seconds()
+		action := "pass"
+		if f.failed() {
+			action = "fail"
+		}
+		events = append(events, testEvent{Action: action, Elapsed: &elapsed})
+		for _, event := range events {
+			event.Time, event.Package = now, f.Path
+			if err := e.Encode(event); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// test runs the tests in the files named *_test.lsp in the directories given
+// by args, or in the current directory, and returns the exit status, which is
+// 1 if a test does not pass.
+func test(args []string) int {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	verbose := flags.Bool("v", false, "write the results of the passed tests and the output of the files")
+	run := flags.String("run", "", "run only the tests whose names, preceded by the names of their suites, match `regexp`")
+	format := flags.String("format", "text", "write the results in the text, junit or json format")
+	outputPath := flags.String("o", "", "write the results to `file` instead of the standard output")
+	timeout := flags.Duration("timeout", 10*time.Minute, "fail the tests of a file if they take longer than `d`")
+	flags.Parse(args)
+	if _, err := regexp.Compile(*run); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if *format != "text" && *format != "junit" && *format != "json" {
+		fmt.Fprintf(os.Stderr, "unknown test format: %v\n", *format)
+		return 2
+	}
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+	names, err := findTestFiles(paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	files := []testFile{}
+	status := 0
+	for _, name := range names {
+		file := runTestFile(name, *run, *timeout)
+		if file.failed() {
+			status = 1
+		}
+		files = append(files, file)
+	}
+	var w io.Writer = os.Stdout
+	if *outputPath != "" {
+		output, err := os.Create(*outputPath)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		defer output.Close()
+		w = output
+	}
+	switch *format {
+	case "junit":
+		err = writeJUnit(w, files)
+	case "json":
+		err = writeJSON(w, files)
+	default:
+		err = writeText(w, files, *verbose)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return status
+}
@@ -0,0 +1,161 @@
+// This Source Code Form is subject to the terms of the Mozilla Public License,
+// v. 2.0. If a copy of the MPL was not distributed with this file, You can
+// obtain one at http://mozilla.org/MPL/2.0/.
+
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// TestMain runs a test file as iris does when the test binary is started by
+// runTestFile.
+func TestMain(m *testing.M) {
+	if os.Getenv(testResultsVariable) != "" {
+		os.Exit(testChild(os.Args[1]))
+	}
+	os.Exit(m.Run())
+}
+
+// runTest runs iris test with args, and returns the exit status and the
+// results written.
+func runTest(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	output := filepath.Join(t.TempDir(), "results")
+	status := test(append([]string{"-o", output}, args...))
+	data, err := ioutil.ReadFile(output)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return status, string(data)
+}
+
+func TestFindTestFiles(t *testing.T) {
+	files, err := findTestFiles([]string{"testdata/test", "testdata/test/math_test.lsp"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		filepath.Join("testdata", "test", "math_test.lsp"),
+		filepath.Join("testdata", "test", "sub", "isolated_test.lsp"),
+	}
+	if !reflect.DeepEqual(files, want) {
+		t.Errorf("findTestFiles() = %q, want %q", files, want)
+	}
+}
+
+func TestTestText(t *testing.T) {
+	status, output := runTest(t, "testdata/test")
+	if status != 1 {
+		t.Errorf("status = %v, want 1", status)
+	}
+	for _, want := range []string{
+		"loaded\n",
+		"--- FAIL: DOUBLE-THREE",
+		"(DOUBLE 3) is 6, expected 7",
+		"FAIL\t" + filepath.Join("testdata", "test", "math_test.lsp"),
+		// The file runs in its own process, so double is not defined in it.
+		"ok  \t" + filepath.Join("testdata", "test", "sub", "isolated_test.lsp"),
+	} {
+		if !strings.Contains(output, want) {
+			t.Errorf("output = %q, want %q in it", output, want)
+		}
+	}
+	if strings.Contains(output, "DOUBLE-TWO") {
+		t.Errorf("output = %q, want no passed test without -v", output)
+	}
+}
+
+func TestTestRun(t *testing.T) {
+	status, output := runTest(t, "-v", "-run", "TWO$", "testdata/test/math_test.lsp")
+	if status != 0 {
+		t.Errorf("status = %v, want 0", status)
+	}
+	if !strings.Contains(output, "--- PASS: DOUBLE-TWO") || strings.Contains(output, "DOUBLE-THREE") {
+		t.Errorf("output = %q, want only DOUBLE-TWO", output)
+	}
+}
+
+func TestTestTimeout(t *testing.T) {
+	status, output := runTest(t, "-timeout", "500ms", "testdata/timeout")
+	if status != 1 {
+		t.Errorf("status = %v, want 1", status)
+	}
+	if !strings.Contains(output, "--- ERROR: loop_test.lsp") || !strings.Contains(output, "The tests timed out after 500ms.") {
+		t.Errorf("output = %q, want the file timed out", output)
+	}
+}
+
+func TestTestJUnit(t *testing.T) {
+	status, output := runTest(t, "-format", "junit", "testdata/test")
+	if status != 1 {
+		t.Errorf("status = %v, want 1", status)
+	}
+	if !strings.HasPrefix(output, xml.Header) {
+		t.Errorf("output = %q, want the XML header", output)
+	}
+	var suites junitTestSuites
+	if err := xml.Unmarshal([]byte(output), &suites); err != nil {
+		t.Fatal(err)
+	}
+	if len(suites.TestSuites) != 2 {
+		t.Fatalf("suites = %v, want 2", suites.TestSuites)
+	}
+	math := suites.TestSuites[0]
+	if math.Name != filepath.Join("testdata", "test", "math_test.lsp") || math.Tests != 2 || math.Failures != 1 || math.Errors != 0 || math.SystemOut != "loaded\n" {
+		t.Errorf("suite = %+v, want 2 tests and 1 failure of math_test.lsp", math)
+	}
+	names := []string{}
+	for _, c := range math.TestCases {
+		names = append(names, c.Name)
+	}
+	if want := []string{"DOUBLE-TWO", "DOUBLE-THREE"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("test cases = %q, want %q", names, want)
+	}
+	if f := math.TestCases[1].Failure; f == nil || f.Message != "(DOUBLE 3) is 6, expected 7" {
+		t.Errorf("failure = %+v, want the failed assertion", f)
+	}
+	if isolated := suites.TestSuites[1]; isolated.Tests != 1 || isolated.Failures != 0 || isolated.Errors != 0 {
+		t.Errorf("suite = %+v, want 1 passed test", isolated)
+	}
+}
+
+func TestTestJSON(t *testing.T) {
+	status, output := runTest(t, "-format", "json", "testdata/test/math_test.lsp")
+	if status != 1 {
+		t.Errorf("status = %v, want 1", status)
+	}
+	events := []string{}
+	decoder := json.NewDecoder(bytes.NewReader([]byte(output)))
+	for decoder.More() {
+		var event testEvent
+		if err := decoder.Decode(&event); err != nil {
+			t.Fatal(err)
+		}
+		if event.Package != filepath.Join("testdata", "test", "math_test.lsp") {
+			t.Errorf("package = %q, want math_test.lsp", event.Package)
+		}
+		events = append(events, strings.TrimSpace(event.Action+" "+event.Test))
+	}
+	want := []string{
+		"output",
+		"run DOUBLE-TWO",
+		"output DOUBLE-TWO",
+		"pass DOUBLE-TWO",
+		"run DOUBLE-THREE",
+		"output DOUBLE-THREE",
+		"fail DOUBLE-THREE",
+		"fail",
+	}
+	if !reflect.DeepEqual(events, want) {
+		t.Errorf("events = %q, want %q", events, want)
+	}
+}
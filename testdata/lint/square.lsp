@@ -0,0 +1 @@
+(defun square (x) (* x x))
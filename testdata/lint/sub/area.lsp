@@ -0,0 +1,2 @@
+;; square is defined in another file, which is checked together.
+(defun area (r) (* 3 (square r) (cube r)))
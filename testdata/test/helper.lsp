@@ -0,0 +1,2 @@
+;; This file is not a test file, so it is not loaded.
+(car 1)
@@ -0,0 +1,9 @@
+(defun double (x) (* x 2))
+
+(format (standard-output) "loaded~%")
+
+(deftest double-two
+  (assert-equal 4 (double 2)))
+
+(deftest double-three
+  (assert-equal 7 (double 3)))
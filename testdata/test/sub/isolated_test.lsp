@@ -0,0 +1,3 @@
+;; The functions defined by the other test files are not defined here.
+(deftest isolated
+  (assert-error <undefined-function> (double 2)))
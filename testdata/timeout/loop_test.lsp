@@ -0,0 +1,2 @@
+(deftest forever
+  (while t))